@@ -0,0 +1,379 @@
+// Package apidiff compares the exported API of two versions of a set of
+// packages and classifies each change as breaking or compatible following the
+// Go 1 compatibility rules.
+package apidiff
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// Change kinds reported in Change.Kind.
+const (
+	Added      = "added"
+	Removed    = "removed"
+	Changed    = "changed"
+	Deprecated = "deprecated"
+)
+
+// Change describes a single difference in the exported API of a package.
+type Change struct {
+	Package  string // the import path of the package
+	Symbol   string // the qualified symbol, e.g. "User.Save", or empty for the package itself
+	Kind     string // Added, Removed, Changed or Deprecated
+	Breaking bool
+	Message  string // a human-readable description of the change
+	Old      string // the previous declaration, if any
+	New      string // the new declaration, if any
+}
+
+// Report lists the API changes between two versions, ordered by package and symbol.
+type Report struct {
+	Changes []Change
+}
+
+// Breaking reports whether any change in the report is breaking.
+//
+// Returns:
+//   - bool: True if at least one change is breaking
+func (r Report) Breaking() bool {
+	for _, c := range r.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// HasKind reports whether the report contains a change of the given kind.
+//
+// Parameters:
+//   - kind: Added, Removed, Changed or Deprecated
+//
+// Returns:
+//   - bool: True if at least one change has the kind
+func (r Report) HasKind(kind string) bool {
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// majorSuffix matches the major version element of an import path, e.g. "/v2".
+var majorSuffix = regexp.MustCompile(`/v[0-9]+(/|$)`)
+
+// packageKey returns the path packages are matched by across versions: the
+// import path without its major version element, so that "example.com/m/v2/x"
+// is compared with "example.com/m/x".
+//
+// Parameters:
+//   - pkg: The package model
+//
+// Returns:
+//   - string: The version-independent package path
+func packageKey(pkg *model.Package) string {
+	if pkg.ImportPath == "" {
+		return pkg.Name
+	}
+	return majorSuffix.ReplaceAllString(pkg.ImportPath, "$1")
+}
+
+// Compare computes the API changes from the old to the new version of a set of
+// packages. The models should include undocumented but not unexported symbols.
+//
+// Parameters:
+//   - oldPkgs: The packages of the base version
+//   - newPkgs: The packages of the new version
+//
+// Returns:
+//   - Report: The changes, ordered by package and symbol
+func Compare(oldPkgs, newPkgs []*model.Package) Report {
+	olds := map[string]*model.Package{}
+	for _, p := range oldPkgs {
+		olds[packageKey(p)] = p
+	}
+	news := map[string]*model.Package{}
+	for _, p := range newPkgs {
+		news[packageKey(p)] = p
+	}
+
+	var r Report
+	for key, oldPkg := range olds {
+		if _, ok := news[key]; !ok {
+			r.Changes = append(r.Changes, Change{
+				Package: oldPkg.ImportPath, Kind: Removed, Breaking: true,
+				Message: "package removed",
+			})
+		}
+	}
+	for key, newPkg := range news {
+		oldPkg, ok := olds[key]
+		if !ok {
+			r.Changes = append(r.Changes, Change{
+				Package: newPkg.ImportPath, Kind: Added,
+				Message: "package added",
+			})
+			continue
+		}
+		r.Changes = append(r.Changes, comparePackage(oldPkg, newPkg)...)
+	}
+
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		a, b := r.Changes[i], r.Changes[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Symbol < b.Symbol
+	})
+	return r
+}
+
+// comparePackage computes the changes between two versions of one package.
+//
+// Parameters:
+//   - oldPkg: The base version of the package
+//   - newPkg: The new version of the package
+//
+// Returns:
+//   - []Change: The changes to the package's symbols
+func comparePackage(oldPkg, newPkg *model.Package) []Change {
+	olds, news := symbols(oldPkg), symbols(newPkg)
+	var changes []Change
+	add := func(c Change) {
+		c.Package = newPkg.ImportPath
+		changes = append(changes, c)
+	}
+
+	for name, o := range olds {
+		n, ok := news[name]
+		if !ok {
+			// Removing a member of a removed type is covered by the type's removal.
+			if o.parent != "" {
+				if _, ok := news[o.parent]; !ok {
+					continue
+				}
+			}
+			add(Change{Symbol: name, Kind: Removed, Breaking: true, Message: o.kind + " removed", Old: o.decl})
+			continue
+		}
+
+		switch {
+		case o.kind != n.kind:
+			add(Change{Symbol: name, Kind: Changed, Breaking: true,
+				Message: "changed from " + o.kind + " to " + n.kind, Old: o.decl, New: n.decl})
+		case o.signature != n.signature:
+			add(Change{Symbol: name, Kind: Changed, Breaking: true,
+				Message: o.kind + " " + o.what + " changed", Old: o.decl, New: n.decl})
+		case o.tag != n.tag:
+			add(Change{Symbol: name, Kind: Changed,
+				Message: "struct tag changed from `" + o.tag + "` to `" + n.tag + "`", Old: o.decl, New: n.decl})
+		}
+		if n.deprecated && !o.deprecated {
+			add(Change{Symbol: name, Kind: Deprecated, Message: deprecationMessage(n), New: n.decl})
+		}
+	}
+
+	for name, n := range news {
+		if _, ok := olds[name]; ok {
+			continue
+		}
+		if n.parent != "" {
+			if _, ok := olds[n.parent]; !ok {
+				// Members of a new type are part of the type's addition.
+				continue
+			}
+		}
+		c := Change{Symbol: name, Kind: Added, Message: n.kind + " added", New: n.decl}
+		if n.kind == "interface method" {
+			// Existing implementations of the interface no longer satisfy it.
+			c.Breaking = true
+		}
+		add(c)
+	}
+	return changes
+}
+
+// deprecationMessage describes a newly deprecated symbol.
+//
+// Parameters:
+//   - s: The deprecated symbol
+//
+// Returns:
+//   - string: The description including the deprecation notice, if any
+func deprecationMessage(s symbol) string {
+	if s.notice == "" {
+		return s.kind + " deprecated"
+	}
+	return s.kind + " deprecated: " + s.notice
+}
+
+// symbol is the comparable form of an exported API element.
+type symbol struct {
+	kind       string // e.g. "func", "method", "type", "field", "interface method", "const", "var"
+	what       string // what signature describes, e.g. "signature" or "type"
+	signature  string // the part of the declaration whose change breaks clients
+	tag        string // the struct tag of a field
+	decl       string // the declaration shown in reports
+	parent     string // the type a field or method belongs to
+	deprecated bool
+	notice     string
+}
+
+// symbols indexes the exported API elements of a package by qualified name.
+//
+// Parameters:
+//   - pkg: The package model
+//
+// Returns:
+//   - map[string]symbol: The API elements by name, e.g. "User" or "User.Save"
+func symbols(pkg *model.Package) map[string]symbol {
+	out := map[string]symbol{}
+	addFunc := func(name, parent string, f model.Func) {
+		kind := "func"
+		if f.Recv != "" {
+			kind = "method"
+		}
+		out[name] = symbol{
+			kind: kind, what: "signature", signature: Signature(f.Decl), decl: f.Decl,
+			parent: parent, deprecated: f.Deprecated, notice: f.Deprecation,
+		}
+	}
+	addValues := func(values []model.Value) {
+		for _, v := range values {
+			for _, s := range v.Specs {
+				for i, name := range s.Names {
+					if !model.IsExported(name) {
+						continue
+					}
+					// Prefer the type-checked type and value, which iota repetitions
+					// and inferred types do not spell out in the source.
+					typ, val := s.Type, ""
+					if v.Kind == "const" && i < len(s.Values) {
+						val = s.Values[i]
+					}
+					if i < len(s.Types) && s.Types[i] != "" {
+						typ = s.Types[i]
+						if i < len(s.Constants) {
+							val = s.Constants[i]
+						}
+					}
+					if val != "" {
+						val = "= " + val
+					}
+					out[name] = symbol{
+						kind: v.Kind, what: "type or value", signature: joinNonEmpty(typ, val),
+						decl:       joinNonEmpty(v.Kind, name, typ, val),
+						deprecated: v.Deprecated || s.Deprecated, notice: v.Deprecation + s.Deprecation,
+					}
+				}
+			}
+		}
+	}
+
+	addValues(pkg.Consts)
+	addValues(pkg.Vars)
+	for _, f := range pkg.Funcs {
+		if model.IsExported(f.Name) {
+			addFunc(f.Name, "", f)
+		}
+	}
+	for _, t := range pkg.Types {
+		if !model.IsExported(t.Name) {
+			continue
+		}
+		typ := symbol{
+			kind: "type", what: "definition", decl: t.Decl,
+			deprecated: t.Deprecated, notice: t.Deprecation,
+		}
+		switch t.Kind {
+		case "struct":
+			typ.kind = "struct"
+		case "interface":
+			typ.kind = "interface"
+		default:
+			typ.signature = typeDefinition(t.Decl)
+		}
+		out[t.Name] = typ
+
+		for _, f := range t.Fields {
+			if !model.IsExported(f.Name) {
+				continue
+			}
+			name := t.Name + "." + f.Name
+			if t.Kind == "interface" {
+				out[name] = symbol{
+					kind: "interface method", what: "signature", signature: Signature(f.Type),
+					decl: f.Name + strings.TrimPrefix(f.Type, "func"), parent: t.Name,
+					deprecated: f.Deprecated, notice: f.Deprecation,
+				}
+				continue
+			}
+			kind := "field"
+			if f.Embedded {
+				kind = "embedded field"
+			}
+			decl := f.Name + " " + f.Type
+			if f.Embedded {
+				decl = f.Type
+			}
+			if f.Tag != "" {
+				decl += " `" + f.Tag + "`"
+			}
+			out[name] = symbol{
+				kind: kind, what: "type", signature: f.Type, tag: f.Tag, decl: decl, parent: t.Name,
+				deprecated: f.Deprecated, notice: f.Deprecation,
+			}
+		}
+		addValues(t.Consts)
+		addValues(t.Vars)
+		for _, f := range t.Funcs {
+			if model.IsExported(f.Name) {
+				addFunc(f.Name, "", f)
+			}
+		}
+		for _, m := range t.Methods {
+			if model.IsExported(m.Name) {
+				addFunc(t.Name+"."+m.Name, t.Name, m)
+			}
+		}
+	}
+	return out
+}
+
+// typeDefinition returns the underlying type of a non-struct, non-interface
+// type declaration such as "type ID string" or "type Alias = other.Type".
+//
+// Parameters:
+//   - decl: The type declaration as Go source
+//
+// Returns:
+//   - string: The declaration without the "type" keyword and name
+func typeDefinition(decl string) string {
+	fields := strings.SplitN(strings.TrimPrefix(decl, "type "), " ", 2)
+	if len(fields) < 2 {
+		return decl
+	}
+	return fields[1]
+}
+
+// joinNonEmpty joins the non-empty parts with single spaces.
+//
+// Parameters:
+//   - parts: The parts to join
+//
+// Returns:
+//   - string: The joined parts
+func joinNonEmpty(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " ")
+}
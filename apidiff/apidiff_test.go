@@ -0,0 +1,194 @@
+package apidiff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func TestSignature(t *testing.T) {
+	tests := map[string]string{
+		"func Save(ctx context.Context, force bool) error":       "(context.Context, bool) error",
+		"func (u *User) Save(a, b int) (int, error)":             "(*User) (int, int) (int, error)",
+		"func Map[T any, U comparable](in []T, f func(T) U) []U": "[any, comparable]([]T, func(T) U) []U",
+		"func(name string) error":                                "(string) error",
+	}
+	for decl, want := range tests {
+		if got := Signature(decl); got != want {
+			t.Errorf("Signature(%q) = %q, want %q", decl, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	oldPkg := &model.Package{
+		Name:       "models",
+		ImportPath: "example.com/app/models",
+		Funcs: []model.Func{
+			{Name: "Open", Decl: "func Open(path string) error"},
+			{Name: "Close", Decl: "func Close()"},
+			{Name: "Load", Decl: "func Load(id string) (*User, error)"},
+		},
+		Types: []model.Type{
+			{
+				Name: "User", Kind: "struct", Decl: "type User struct{...}",
+				Fields: []model.Field{
+					{Name: "Name", Type: "string", Tag: `json:"name"`},
+					{Name: "Age", Type: "int"},
+				},
+				Methods: []model.Func{{Name: "Save", Recv: "User", Decl: "func (u *User) Save() error"}},
+			},
+			{
+				Name: "Store", Kind: "interface", Decl: "type Store interface{...}",
+				Fields: []model.Field{{Name: "Get", Type: "func(id string) (*User, error)"}},
+			},
+		},
+	}
+	newPkg := &model.Package{
+		Name:       "models",
+		ImportPath: "example.com/app/v2/models",
+		Funcs: []model.Func{
+			{Name: "Open", Decl: "func Open(file string) error"},
+			{Name: "Load", Decl: "func Load(id int) (*User, error)", Deprecated: true, Deprecation: "Use Store.Get."},
+			{Name: "Dial", Decl: "func Dial() error"},
+		},
+		Types: []model.Type{
+			{
+				Name: "User", Kind: "struct", Decl: "type User struct{...}",
+				Fields: []model.Field{
+					{Name: "Name", Type: "string", Tag: `json:"full_name"`},
+					{Name: "Age", Type: "int"},
+					{Name: "Email", Type: "string"},
+				},
+				Methods: []model.Func{{Name: "Save", Recv: "User", Decl: "func (user *User) Save() error"}},
+			},
+			{
+				Name: "Store", Kind: "interface", Decl: "type Store interface{...}",
+				Fields: []model.Field{
+					{Name: "Get", Type: "func(key string) (*User, error)"},
+					{Name: "Put", Type: "func(u *User) error"},
+				},
+			},
+		},
+	}
+
+	r := Compare([]*model.Package{oldPkg}, []*model.Package{newPkg})
+
+	var got []string
+	for _, c := range r.Changes {
+		mark := "compatible"
+		if c.Breaking {
+			mark = "breaking"
+		}
+		got = append(got, c.Symbol+" "+c.Kind+" "+mark)
+	}
+	want := []string{
+		"Close removed breaking",
+		"Dial added compatible",
+		"Load changed breaking",
+		"Load deprecated compatible",
+		"Store.Put added breaking",
+		"User.Email added compatible",
+		"User.Name changed compatible",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !r.Breaking() || !r.HasKind(Deprecated) {
+		t.Errorf("expected a breaking report with deprecations")
+	}
+
+	var buf bytes.Buffer
+	WriteMarkdown(r, "API changes since v1.0.0", &buf)
+	out := buf.String()
+	for _, s := range []string{
+		"# API changes since v1.0.0",
+		"7 change(s), 3 breaking.",
+		"## `example.com/app/v2/models`",
+		"### ⚠️ Breaking changes",
+		"- `Load`: func signature changed\n  ```diff\n  - func Load(id string) (*User, error)\n  + func Load(id int) (*User, error)\n  ```",
+		"- `User.Name`: struct tag changed from `json:\"name\"` to `json:\"full_name\"`",
+		"- `Load`: func deprecated: Use Store.Get.",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected report to contain %q\n%s", s, out)
+		}
+	}
+}
+
+func TestCompare_Packages(t *testing.T) {
+	a := &model.Package{Name: "a", ImportPath: "example.com/m/a"}
+	b := &model.Package{Name: "b", ImportPath: "example.com/m/b"}
+
+	r := Compare([]*model.Package{a}, []*model.Package{b})
+	if len(r.Changes) != 2 {
+		t.Fatalf("expected two changes, got %+v", r.Changes)
+	}
+	if c := r.Changes[0]; c.Package != "example.com/m/a" || c.Kind != Removed || !c.Breaking {
+		t.Errorf("unexpected change %+v", c)
+	}
+	if c := r.Changes[1]; c.Package != "example.com/m/b" || c.Kind != Added || c.Breaking {
+		t.Errorf("unexpected change %+v", c)
+	}
+}
+
+func TestCompare_CheckedValues(t *testing.T) {
+	level := func(names []string, consts ...string) model.Value {
+		spec := model.ValueSpec{Names: names, Constants: consts}
+		for range names {
+			spec.Types = append(spec.Types, "Level")
+		}
+		return model.Value{Kind: "const", Specs: []model.ValueSpec{spec}}
+	}
+	oldPkg := &model.Package{
+		Name: "log", ImportPath: "example.com/log",
+		Consts: []model.Value{
+			level([]string{"Debug", "Info", "Warn"}, "0", "1", "2"),
+			{Kind: "const", Specs: []model.ValueSpec{{Names: []string{"Max"}, Values: []string{"10"}, Types: []string{"untyped int"}, Constants: []string{"10"}}}},
+		},
+		Vars: []model.Value{{Kind: "var", Specs: []model.ValueSpec{{Names: []string{"Timeout"}, Values: []string{"f()"}, Types: []string{"int"}}}}},
+	}
+	// Trace is inserted before Info, shifting the iota values of Info and Warn.
+	newPkg := &model.Package{
+		Name: "log", ImportPath: "example.com/log",
+		Consts: []model.Value{
+			level([]string{"Debug", "Trace", "Info", "Warn"}, "0", "1", "2", "3"),
+			{Kind: "const", Specs: []model.ValueSpec{{Names: []string{"Max"}, Values: []string{"10"}, Types: []string{"untyped int"}, Constants: []string{"10"}}}},
+		},
+		Vars: []model.Value{{Kind: "var", Specs: []model.ValueSpec{{Names: []string{"Timeout"}, Values: []string{"f()"}, Types: []string{"time.Duration"}}}}},
+	}
+
+	changes := map[string]Change{}
+	for _, c := range Compare([]*model.Package{oldPkg}, []*model.Package{newPkg}).Changes {
+		changes[c.Symbol] = c
+	}
+	if len(changes) != 4 {
+		t.Errorf("expected changes to Trace, Info, Warn and Timeout, got %+v", changes)
+	}
+	if c := changes["Trace"]; c.Kind != Added || c.New != "const Trace Level = 1" {
+		t.Errorf("unexpected Trace change %+v", c)
+	}
+	for _, name := range []string{"Info", "Warn"} {
+		if c := changes[name]; c.Kind != Changed || !c.Breaking {
+			t.Errorf("expected %s to change incompatibly, got %+v", name, c)
+		}
+	}
+	if c := changes["Timeout"]; c.Kind != Changed || !c.Breaking || c.Old != "var Timeout int" || c.New != "var Timeout time.Duration" {
+		t.Errorf("unexpected Timeout change %+v", c)
+	}
+}
+
+func TestCompare_UntypedConstDecl(t *testing.T) {
+	pkg := func(value string) *model.Package {
+		return &model.Package{
+			Name: "limits", ImportPath: "example.com/limits",
+			Consts: []model.Value{{Kind: "const", Specs: []model.ValueSpec{{Names: []string{"Max"}, Values: []string{value}}}}},
+		}
+	}
+	report := Compare([]*model.Package{pkg("10")}, []*model.Package{pkg("11")})
+	if len(report.Changes) != 1 || report.Changes[0].Old != "const Max = 10" || report.Changes[0].New != "const Max = 11" {
+		t.Errorf("unexpected changes %+v", report.Changes)
+	}
+}
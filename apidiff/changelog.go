@@ -0,0 +1,97 @@
+package apidiff
+
+import (
+	"fmt"
+	"io"
+)
+
+// Unreleased is the Release.Version of changes made since the latest tag.
+const Unreleased = "Unreleased"
+
+// Release is one entry of a changelog: the API changes a version introduced
+// relative to the previous one.
+type Release struct {
+	Version string // the tag, e.g. "v1.2.0", or Unreleased
+	Date    string // the commit date of the tag as YYYY-MM-DD, if known
+	Initial bool   // whether this is the first release, which has no previous version
+	Report  Report
+}
+
+// changelogSections lists the changelog sections in the order they are written.
+var changelogSections = []struct {
+	kind    string
+	heading string
+}{
+	{Added, "Added"},
+	{Changed, "Changed"},
+	{Deprecated, "Deprecated"},
+	{Removed, "Removed"},
+}
+
+// WriteChangelog writes releases as a Markdown changelog, listing the added,
+// changed, deprecated and removed API of each release. Breaking changes outside
+// the Removed section are marked with ⚠️.
+//
+// Parameters:
+//   - releases: The releases, newest first
+//   - out: The writer to output the markdown to
+func WriteChangelog(releases []Release, out io.Writer) {
+	fmt.Fprintln(out, "# Changelog")
+	if len(releases) == 0 {
+		fmt.Fprintln(out, "\nNo releases found.")
+		return
+	}
+
+	for _, rel := range releases {
+		if rel.Date != "" {
+			fmt.Fprintf(out, "\n## %s (%s)\n", rel.Version, rel.Date)
+		} else {
+			fmt.Fprintf(out, "\n## %s\n", rel.Version)
+		}
+		if rel.Initial {
+			fmt.Fprintln(out, "\nInitial release.")
+		}
+		if len(rel.Report.Changes) == 0 {
+			if !rel.Initial {
+				fmt.Fprintln(out, "\nNo changes to the exported API.")
+			}
+			continue
+		}
+
+		for _, section := range changelogSections {
+			var lines []string
+			for _, c := range rel.Report.Changes {
+				if c.Kind == section.kind {
+					lines = append(lines, changelogLine(c))
+				}
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "\n### %s\n\n", section.heading)
+			for _, line := range lines {
+				fmt.Fprintln(out, line)
+			}
+		}
+	}
+}
+
+// changelogLine formats a single change as a changelog list item, qualifying
+// the symbol with its import path.
+//
+// Parameters:
+//   - c: The change
+//
+// Returns:
+//   - string: The list item
+func changelogLine(c Change) string {
+	name := c.Package
+	if c.Symbol != "" {
+		name += "." + c.Symbol
+	}
+	line := fmt.Sprintf("- `%s`: %s", name, c.Message)
+	if c.Breaking && c.Kind != Removed {
+		line += " ⚠️ breaking"
+	}
+	return line
+}
@@ -0,0 +1,52 @@
+package apidiff
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteChangelog(t *testing.T) {
+	releases := []Release{
+		{
+			Version: Unreleased,
+			Report: Report{Changes: []Change{
+				{Package: "example.com/m/store", Symbol: "Close", Kind: Removed, Breaking: true, Message: "func removed"},
+				{Package: "example.com/m/store", Symbol: "Open", Kind: Changed, Breaking: true, Message: "func signature changed"},
+			}},
+		},
+		{
+			Version: "v1.1.0",
+			Date:    "2026-02-01",
+			Report: Report{Changes: []Change{
+				{Package: "example.com/m/store", Symbol: "Dial", Kind: Added, Message: "func added"},
+				{Package: "example.com/m/store", Symbol: "Open", Kind: Deprecated, Message: "func deprecated: Use Dial."},
+			}},
+		},
+		{Version: "v1.0.1", Date: "2026-01-15"},
+		{
+			Version: "v1.0.0",
+			Date:    "2026-01-01",
+			Initial: true,
+			Report: Report{Changes: []Change{
+				{Package: "example.com/m/store", Kind: Added, Message: "package added"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	WriteChangelog(releases, &buf)
+
+	want := "# Changelog\n" +
+		"\n## Unreleased\n" +
+		"\n### Changed\n\n- `example.com/m/store.Open`: func signature changed ⚠️ breaking\n" +
+		"\n### Removed\n\n- `example.com/m/store.Close`: func removed\n" +
+		"\n## v1.1.0 (2026-02-01)\n" +
+		"\n### Added\n\n- `example.com/m/store.Dial`: func added\n" +
+		"\n### Deprecated\n\n- `example.com/m/store.Open`: func deprecated: Use Dial.\n" +
+		"\n## v1.0.1 (2026-01-15)\n\nNo changes to the exported API.\n" +
+		"\n## v1.0.0 (2026-01-01)\n\nInitial release.\n" +
+		"\n### Added\n\n- `example.com/m/store`: package added\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected changelog:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package apidiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a report as Markdown, grouping the changes of each
+// package into breaking and compatible sections.
+//
+// Parameters:
+//   - r: The report to write
+//   - title: The document heading, e.g. "API changes since v1.2.0"
+//   - out: The writer to output the markdown to
+func WriteMarkdown(r Report, title string, out io.Writer) {
+	fmt.Fprintf(out, "# %s\n\n", title)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(out, "No changes to the exported API.")
+		return
+	}
+
+	breaking := 0
+	for _, c := range r.Changes {
+		if c.Breaking {
+			breaking++
+		}
+	}
+	fmt.Fprintf(out, "%d change(s), %d breaking.\n", len(r.Changes), breaking)
+
+	for start := 0; start < len(r.Changes); {
+		end := start
+		for end < len(r.Changes) && r.Changes[end].Package == r.Changes[start].Package {
+			end++
+		}
+		changes := r.Changes[start:end]
+		start = end
+
+		fmt.Fprintf(out, "\n## `%s`\n", changes[0].Package)
+		writeSection(out, "⚠️ Breaking changes", changes, true)
+		writeSection(out, "✅ Compatible changes", changes, false)
+	}
+}
+
+// writeSection writes the breaking or compatible changes of one package.
+//
+// Parameters:
+//   - out: The writer to output the markdown to
+//   - heading: The section heading
+//   - changes: The changes of the package
+//   - breaking: Whether to write the breaking or the compatible changes
+func writeSection(out io.Writer, heading string, changes []Change, breaking bool) {
+	var lines []string
+	for _, c := range changes {
+		if c.Breaking == breaking {
+			lines = append(lines, changeLine(c))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n### %s\n\n%s\n", heading, strings.Join(lines, "\n"))
+}
+
+// changeLine formats a single change as a Markdown list item.
+//
+// Parameters:
+//   - c: The change
+//
+// Returns:
+//   - string: The list item, including old and new declarations for changed symbols
+func changeLine(c Change) string {
+	if c.Symbol == "" {
+		return "- " + capitalize(c.Message)
+	}
+	line := fmt.Sprintf("- `%s`: %s", c.Symbol, c.Message)
+	if c.Kind == Changed && c.Old != "" && c.New != "" && !strings.Contains(c.Message, "tag") {
+		line += fmt.Sprintf("\n  ```diff\n  - %s\n  + %s\n  ```", indentDecl(c.Old), indentDecl(c.New))
+	}
+	return line
+}
+
+// indentDecl keeps a multi-line declaration inside a list item's code block.
+//
+// Parameters:
+//   - decl: The declaration
+//
+// Returns:
+//   - string: The declaration with continuation lines indented
+func indentDecl(decl string) string {
+	return strings.ReplaceAll(decl, "\n", "\n    ")
+}
+
+// capitalize upper-cases the first letter of a message.
+//
+// Parameters:
+//   - s: The message
+//
+// Returns:
+//   - string: The message starting with an upper-case letter
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
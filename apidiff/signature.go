@@ -0,0 +1,84 @@
+package apidiff
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/thinktide/godocmd/astprint"
+)
+
+// Signature returns the canonical form of a function declaration or function
+// type, with parameter and receiver names removed so that renaming them is not
+// reported as a change. For example, "func (u *User) Save(ctx context.Context,
+// force bool) error" becomes "(*User) (context.Context, bool) error".
+//
+// Parameters:
+//   - decl: A declaration such as "func Name(a int) error" or a type such as "func(a int) error"
+//
+// Returns:
+//   - string: The canonical signature, or decl itself if it cannot be parsed
+func Signature(decl string) string {
+	var recv *ast.FieldList
+	var typ *ast.FuncType
+
+	if strings.HasPrefix(decl, "func(") {
+		expr, err := parser.ParseExpr(decl)
+		if err != nil {
+			return decl
+		}
+		ft, ok := expr.(*ast.FuncType)
+		if !ok {
+			return decl
+		}
+		typ = ft
+	} else {
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+decl, 0)
+		if err != nil || len(file.Decls) == 0 {
+			return decl
+		}
+		fd, ok := file.Decls[0].(*ast.FuncDecl)
+		if !ok {
+			return decl
+		}
+		recv, typ = fd.Recv, fd.Type
+	}
+
+	var b strings.Builder
+	if recv != nil {
+		b.WriteString("(" + typeList(recv) + ") ")
+	}
+	if typ.TypeParams != nil {
+		b.WriteString("[" + typeList(typ.TypeParams) + "]")
+	}
+	b.WriteString("(" + typeList(typ.Params) + ")")
+	if results := typeList(typ.Results); results != "" {
+		if typ.Results.NumFields() > 1 {
+			results = "(" + results + ")"
+		}
+		b.WriteString(" " + results)
+	}
+	return b.String()
+}
+
+// typeList prints the types of a field list, repeating a type once per name.
+//
+// Parameters:
+//   - list: The parameter, result or receiver list
+//
+// Returns:
+//   - string: The comma-separated types
+func typeList(list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	var types []string
+	for _, f := range list.List {
+		typ := astprint.Expr(f.Type)
+		for i := 0; i < max(len(f.Names), 1); i++ {
+			types = append(types, typ)
+		}
+	}
+	return strings.Join(types, ", ")
+}
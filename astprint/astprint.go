@@ -0,0 +1,25 @@
+// Package astprint prints Go syntax tree nodes back to source text.
+package astprint
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+)
+
+// Expr prints an expression as Go source. The expression may come from any
+// file set, as identifiers and literals are printed without positions.
+//
+// Parameters:
+//   - expr: The expression
+//
+// Returns:
+//   - string: The source text, or an empty string if the expression cannot be printed
+func Expr(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
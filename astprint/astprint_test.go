@@ -0,0 +1,22 @@
+package astprint
+
+import (
+	"go/parser"
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	for _, src := range []string{
+		"map[string][]*User",
+		"func(ctx context.Context, id int) (string, error)",
+		`time.Second * 2`,
+	} {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Expr(expr); got != src {
+			t.Errorf("Expr(%q) = %q", src, got)
+		}
+	}
+}
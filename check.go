@@ -0,0 +1,185 @@
+package godocmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/thinktide/godocmd/textdiff"
+)
+
+// ErrStale is returned by Check when committed documentation differs from a fresh generation.
+var ErrStale = errors.New("generated documentation is out of date")
+
+// Output describes where generated documentation is written. Exactly one of
+// File, Dir and Inject is expected to be set.
+type Output struct {
+	// Format is "markdown" (the default) or "json". Dir and Inject require markdown.
+	Format string
+
+	// File is a single output file, as written by GenerateMarkdownWithOptions or GenerateJSON.
+	File string
+
+	// Dir is a directory of per-package markdown files, as written by GenerateMarkdownFiles.
+	Dir string
+
+	// Inject is a markdown file whose marked section is updated, as by InjectMarkdown.
+	Inject string
+}
+
+// Render generates the documentation described by output in memory.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - output: The output files to render
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - map[string][]byte: The expected content of each output file by path
+//   - error: Any error encountered during parsing or rendering
+func Render(rootDir string, output Output, opts Options) (map[string][]byte, error) {
+	outFormat := output.Format
+	if outFormat == "" {
+		outFormat = "markdown"
+	}
+	if outFormat != "markdown" && (output.Dir != "" || output.Inject != "") {
+		return nil, fmt.Errorf("format %s cannot be written per package or injected", outFormat)
+	}
+
+	switch {
+	case output.Inject != "":
+		_, updated, err := injectedMarkdown(rootDir, output.Inject, opts)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{output.Inject: updated}, nil
+
+	case output.Dir != "":
+		files, err := markdownFiles(rootDir, output.Dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string][]byte, len(files))
+		for file, content := range files {
+			out[filepath.Join(output.Dir, file)] = content
+		}
+		return out, nil
+
+	case output.File != "":
+		var buf bytes.Buffer
+		var err error
+		switch outFormat {
+		case "markdown":
+			opts.OutputDir = filepath.Dir(output.File)
+			err = GenerateMarkdownWithOptions(rootDir, &buf, opts)
+		case "json":
+			err = GenerateJSON(rootDir, &buf, opts)
+		default:
+			err = fmt.Errorf("unknown format %q", outFormat)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{output.File: buf.Bytes()}, nil
+	}
+	return nil, errors.New("no output file, directory or inject target given")
+}
+
+// Check regenerates the documentation described by output in memory and
+// compares it with the files on disk, writing a unified diff for each file that
+// differs. Missing files are diffed against an empty file. For an output
+// directory, package files left behind by packages that are no longer
+// documented are reported as removed.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - output: The committed output files to compare against
+//   - opts: The discovery and rendering options to apply
+//   - diffOut: The writer to output the diffs to
+//
+// Returns:
+//   - error: ErrStale if any file differs, or any error encountered during generation
+func Check(rootDir string, output Output, opts Options, diffOut io.Writer) error {
+	files, err := Render(rootDir, output, opts)
+	if err != nil {
+		return err
+	}
+
+	stale := 0
+	if output.Dir != "" {
+		orphans, err := orphanedFiles(output.Dir, files)
+		if err != nil {
+			return err
+		}
+		for _, path := range orphans {
+			committed, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			stale++
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "❌ %s is no longer generated\n", path)
+			}
+			if _, err := io.WriteString(diffOut, textdiff.Unified(path, "/dev/null", string(committed), "")); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, path := range sortedKeys(files) {
+		committed, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		diff := textdiff.Unified(path, path+" (generated)", string(committed), string(files[path]))
+		if diff == "" {
+			continue
+		}
+		stale++
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "❌ %s is out of date\n", path)
+		}
+		if _, err := io.WriteString(diffOut, diff); err != nil {
+			return err
+		}
+	}
+
+	if stale > 0 {
+		return fmt.Errorf("%w: %d file(s) differ", ErrStale, stale)
+	}
+	return nil
+}
+
+// orphanedFiles lists the package files in an output directory written by
+// GenerateMarkdownFiles that a fresh generation no longer produces.
+//
+// Parameters:
+//   - dir: The output directory
+//   - files: The generated files by path
+//
+// Returns:
+//   - []string: The paths of the orphaned files, in walk order
+//   - error: Any error encountered listing the directory
+func orphanedFiles(dir string, files map[string][]byte) ([]string, error) {
+	var orphans []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || d.Name() != markdownIndexFile {
+			return nil
+		}
+		if _, ok := files[path]; !ok {
+			orphans = append(orphans, path)
+		}
+		return nil
+	})
+	return orphans, err
+}
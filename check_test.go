@@ -0,0 +1,144 @@
+package godocmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree is a test helper that creates files below root.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - root: The directory to create the files in
+//   - files: The file contents keyed by slash-separated relative path
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// writeRendered is a test helper that writes the files rendered for an output
+// to disk, as generate would.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - rootDir: The directory to document
+//   - output: The output to render
+//   - opts: The generation options
+func writeRendered(t *testing.T, rootDir string, output Output, opts Options) {
+	t.Helper()
+	files, err := Render(rootDir, output, opts)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkModule is a small module with two documented packages.
+var checkModule = map[string]string{
+	"go.mod":         "module example.com/shop\n\ngo 1.21\n",
+	"models/user.go": "// Package models holds the data types.\npackage models\n\n// User is a customer.\ntype User struct {\n\t// Name is the display name.\n\tName string\n}\n",
+	"store/store.go": "// Package store persists models.\npackage store\n\n// Save stores a value.\nfunc Save(v any) error { return nil }\n",
+}
+
+func TestCheck_File(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, checkModule)
+	file := filepath.Join(root, "docs", "API.md")
+	output := Output{File: file}
+	opts := Options{Recursive: true}
+	writeRendered(t, root, output, opts)
+
+	var diff strings.Builder
+	if err := Check(root, output, opts, &diff); err != nil || diff.Len() != 0 {
+		t.Fatalf("expected up-to-date docs, got %v:\n%s", err, diff.String())
+	}
+
+	committed, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(strings.Replace(string(committed), "Save stores a value.", "Save stores.", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff.Reset()
+	if err := Check(root, output, opts, &diff); !errors.Is(err, ErrStale) {
+		t.Fatalf("expected ErrStale for a stale file, got %v", err)
+	}
+	if got := diff.String(); !strings.Contains(got, "--- "+file+"\n+++ "+file+" (generated)\n") ||
+		!strings.Contains(got, "-Save stores.") || !strings.Contains(got, "+Save stores a value.") {
+		t.Errorf("unexpected diff for a stale file:\n%s", got)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	diff.Reset()
+	if err := Check(root, output, opts, &diff); !errors.Is(err, ErrStale) {
+		t.Fatalf("expected ErrStale for a missing file, got %v", err)
+	}
+	if got := diff.String(); !strings.Contains(got, "@@ -0,0 +1,") || strings.Contains(got, "\n-") {
+		t.Errorf("expected a missing file to be diffed against an empty file:\n%s", got)
+	}
+}
+
+func TestCheck_Dir(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, checkModule)
+	outDir := filepath.Join(root, "docs")
+	output := Output{Dir: outDir}
+	opts := Options{Recursive: true}
+	writeRendered(t, root, output, opts)
+
+	var diff strings.Builder
+	if err := Check(root, output, opts, &diff); err != nil {
+		t.Fatalf("expected up-to-date docs, got %v:\n%s", err, diff.String())
+	}
+
+	// A package that is no longer documented leaves its file behind.
+	orphan := filepath.Join(outDir, "legacy", markdownIndexFile)
+	writeTree(t, outDir, map[string]string{"legacy/README.md": "# legacy\n"})
+	diff.Reset()
+	err := Check(root, output, opts, &diff)
+	if !errors.Is(err, ErrStale) || !strings.Contains(err.Error(), "1 file(s) differ") {
+		t.Fatalf("expected one orphaned file, got %v", err)
+	}
+	if want := "--- " + orphan + "\n+++ /dev/null\n@@ -1 +0,0 @@\n-# legacy\n"; diff.String() != want {
+		t.Errorf("unexpected diff for an orphaned file:\n%s\nwant:\n%s", diff.String(), want)
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, checkModule)
+	tests := map[string]struct {
+		output Output
+		want   string
+	}{
+		"json dir":    {Output{Format: "json", Dir: filepath.Join(root, "docs")}, "format json cannot be written per package or injected"},
+		"json inject": {Output{Format: "json", Inject: filepath.Join(root, "README.md")}, "format json cannot be written per package or injected"},
+		"empty":       {Output{}, "no output file, directory or inject target given"},
+	}
+	for name, tt := range tests {
+		if _, err := Render(root, tt.output, Options{Recursive: true}); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: Render() = %v, want %q", name, err, tt.want)
+		}
+	}
+}
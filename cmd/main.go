@@ -1,88 +1,621 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/thinktide/godocmd"
-	"github.com/thinktide/godocmd/enums"
+	"github.com/thinktide/godocmd/apidiff"
+	"github.com/thinktide/godocmd/config"
+	"github.com/thinktide/godocmd/coverage"
+	"github.com/thinktide/godocmd/envvars"
+	"github.com/thinktide/godocmd/lint"
+	"github.com/thinktide/godocmd/semver"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	app := &cli.App{
-		Name:  "godocmd",
-		Usage: "Generate Markdown documentation from Go packages",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:     "dir",
-				Aliases:  []string{"d"},
-				Usage:    "Directory to scan for Go packages",
-				Required: true,
+		Name:   "godocmd",
+		Usage:  "Generate Markdown documentation from Go packages",
+		Flags:  generateFlags(),
+		Action: generate,
+		Commands: []*cli.Command{
+			{
+				Name:      "check",
+				Usage:     "Regenerate the docs in memory and fail with a diff if the committed output is stale",
+				UsageText: "godocmd check [--out FILE | --out-dir DIR | --inject FILE] [options]",
+				Flags:     generateFlags(),
+				Action:    check,
 			},
-			&cli.StringFlag{
-				Name:    "out",
-				Aliases: []string{"o"},
-				Usage:   "Output markdown file (default is stdout)",
+			{
+				Name:  "coverage",
+				Usage: "Report the share of documented exported symbols and fail below a threshold",
+				Flags: append(discoveryFlags(),
+					&cli.Float64Flag{
+						Name:  "min",
+						Usage: "Minimum total coverage in percent; exit non-zero when below",
+					},
+				),
+				Action: reportCoverage,
 			},
-			&cli.BoolFlag{
-				Name:    "recursive",
-				Aliases: []string{"r"},
-				Usage:   "Recursively find and document all Go packages under the given directory",
+			{
+				Name:  "lint",
+				Usage: "Check doc comments of exported symbols and report issues as file:line:col: rule: message",
+				Flags: append(discoveryFlags(),
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: text or sarif",
+						Value: "text",
+					},
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "Output file (default is stdout)",
+					},
+				),
+				Action: lintDocs,
 			},
-			&cli.BoolFlag{
-				Name:    "include-private",
-				Aliases: []string{"p"},
-				Usage:   "Include unexported (non-exported) functions and types in the output",
+			{
+				Name:  "env",
+				Usage: "List the environment variables read by configuration structs (env, envDefault and envconfig tags)",
+				Flags: append(discoveryFlags(),
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: markdown or dotenv",
+						Value: "markdown",
+					},
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "Output file (default is stdout)",
+					},
+				),
+				Action: envVars,
 			},
-			&cli.BoolFlag{
-				Name:  "include-undocumented",
-				Usage: "Include functions and types that lack GoDoc comments",
+			{
+				Name:  "diff",
+				Usage: "Report exported API changes since a git revision, classified as breaking or compatible",
+				Flags: append(discoveryFlags(),
+					&cli.StringFlag{
+						Name:     "base",
+						Usage:    "Git revision to compare against, e.g. a tag or branch",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "Output markdown file (default is stdout)",
+					},
+				),
+				Action: diffAPI,
 			},
-			&cli.BoolFlag{
-				Name:  "verbose",
-				Usage: "Enable verbose log output",
+			{
+				Name:  "changelog",
+				Usage: "Write a changelog of API additions, changes, deprecations and removals per semver tag",
+				Flags: append(discoveryFlags(),
+					&cli.StringFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "Output markdown file (default is stdout)",
+					},
+				),
+				Action: changelog,
+			},
+			{
+				Name:  "semver",
+				Usage: "Print the minimum version bump the API changes since the latest semver tag require",
+				Flags: append(discoveryFlags(),
+					&cli.StringFlag{
+						Name:  "tag",
+						Usage: "Proposed version; exit non-zero if it is lower than required or does not match the module path",
+					},
+				),
+				Action: recommendVersion,
 			},
 		},
-		Action: func(c *cli.Context) error {
-			dir := c.String("dir")
-			outPath := c.String("out")
-
-			var out *os.File
-			var err error
-			if outPath != "" {
-				if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
-					return err
-				}
-				out, err = os.Create(outPath)
-				if err != nil {
-					return err
-				}
-				defer out.Close()
-			} else {
-				out = os.Stdout
-			}
+	}
 
-			var flags []enums.MarkdownFlag
-			if c.Bool("recursive") {
-				flags = append(flags, enums.Recursive)
-			}
-			if c.Bool("include-private") {
-				flags = append(flags, enums.IncludePrivate)
-			}
-			if c.Bool("include-undocumented") {
-				flags = append(flags, enums.IncludeUndocumented)
-			}
-			if c.Bool("verbose") {
-				flags = append(flags, enums.Verbose)
-			}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
 
-			return godocmd.GenerateMarkdown(dir, out, flags...)
+// discoveryFlags returns the flags selecting which packages are documented,
+// shared by every command.
+func discoveryFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "dir",
+			Aliases: []string{"d"},
+			Usage:   "Directory to scan for Go packages (required by godocmd itself unless the configuration file sets root)",
+			Value:   ".",
+		},
+		&cli.BoolFlag{
+			Name:    "recursive",
+			Aliases: []string{"r"},
+			Usage:   "Recursively find and document all Go packages under the given directory",
+		},
+		&cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "Enable verbose log output",
+		},
+		&cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "Only document package directories matching these patterns, e.g. ./api/... or cmd/* (relative to the config file's directory, or --dir)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Skip directories matching these patterns and everything below them, e.g. ./examples/... or **/generated",
+		},
+		&cli.BoolFlag{
+			Name:  "exclude-internal",
+			Usage: "Skip internal packages",
+		},
+		&cli.BoolFlag{
+			Name:  "no-default-excludes",
+			Usage: "Also scan vendor, testdata, docs, terraform* and dot- or underscore-prefixed directories",
+		},
+		&cli.BoolFlag{
+			Name:  "no-gitignore",
+			Usage: "Also scan directories and Go files ignored by .gitignore files",
+		},
+		&cli.StringFlag{
+			Name:  "goos",
+			Usage: "Target operating system for evaluating build constraints (default is $GOOS or the current one)",
+		},
+		&cli.StringFlag{
+			Name:  "goarch",
+			Usage: "Target architecture for evaluating build constraints (default is $GOARCH or the current one)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "tags",
+			Usage: "Additional build tags to satisfy (e.g. integration,purego)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "platforms",
+			Usage: "Further goos/goarch platforms to document, badging symbols missing on some of them (e.g. windows/amd64,darwin/arm64)",
+		},
+		&cli.BoolFlag{
+			Name:  "all-packages",
+			Usage: "Document every package of a directory, not only its primary package",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-main",
+			Usage: "Skip main packages",
+		},
+		&cli.BoolFlag{
+			Name:  "external-tests",
+			Usage: "Also document external test packages (package foo_test)",
+		},
+		&cli.StringFlag{
+			Name:  "module",
+			Usage: "Only document the packages of the module with this path, e.g. one module of a go.work workspace",
+		},
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Configuration file (default is the nearest .godocmd.yaml in --dir or its parents)",
 		},
 	}
+}
 
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+// generateFlags returns the flags shared by the generate action and the check command.
+func generateFlags() []cli.Flag {
+	return append(discoveryFlags(),
+		&cli.StringFlag{
+			Name:    "out",
+			Aliases: []string{"o"},
+			Usage:   "Output markdown file (default is stdout), or output directory for --format html",
+		},
+		&cli.StringFlag{
+			Name:  "out-dir",
+			Usage: "Write one markdown file per package to this directory, mirroring the package tree, plus an index README.md",
+		},
+		&cli.StringFlag{
+			Name:  "inject",
+			Usage: "Replace the content between <!-- godocmd:start --> and <!-- godocmd:end --> in this markdown file with the generated docs",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: markdown, html or json",
+			Value: "markdown",
+		},
+		&cli.BoolFlag{
+			Name:    "include-private",
+			Aliases: []string{"p"},
+			Usage:   "Include unexported (non-exported) functions, types and struct fields in the output",
+		},
+		&cli.BoolFlag{
+			Name:  "include-undocumented",
+			Usage: "Include functions and types that lack GoDoc comments",
+		},
+		&cli.BoolFlag{
+			Name:  "hide-deprecated",
+			Usage: "Omit symbols whose GoDoc contains a Deprecated: paragraph",
+		},
+		&cli.StringSliceFlag{
+			Name:  "note-markers",
+			Usage: "Note markers to list in each package's notes section (e.g. BUG,TODO)",
+			Value: cli.NewStringSlice("BUG"),
+		},
+		&cli.StringFlag{
+			Name:  "template-dir",
+			Usage: "Directory of text/template files (package.tmpl, type.tmpl, func.tmpl, field.tmpl, ...) overriding the built-in Markdown templates",
+		},
+		&cli.BoolFlag{
+			Name:  "verify-examples",
+			Usage: "Run example tests in a temporary copy of the module and fail if their output does not match the // Output: comment",
+		},
+	)
+}
+
+// configFromContext loads the configuration file named by --config, or the
+// nearest .godocmd.yaml found from --dir upward. It returns nil if there is none.
+func configFromContext(c *cli.Context) (*config.File, error) {
+	path := c.String("config")
+	if path == "" {
+		var ok bool
+		if path, ok = config.Find(c.String("dir")); !ok {
+			return nil, nil
+		}
+	}
+	if c.Bool("verbose") {
+		fmt.Fprintf(os.Stderr, "📝 Using config %s\n", path)
+	}
+	return config.Load(path)
+}
+
+// dirFromContext returns the directory to scan: --dir when set, otherwise the
+// root of the configuration file, if any, otherwise --dir's default.
+func dirFromContext(c *cli.Context, cfg *config.File) string {
+	if !c.IsSet("dir") && cfg != nil && cfg.Root != "" {
+		return cfg.Root
+	}
+	return c.String("dir")
+}
+
+// optionsFromContext builds the generation options from the configuration file,
+// if any, and the command-line flags, which take precedence over it.
+func optionsFromContext(c *cli.Context, cfg *config.File) godocmd.Options {
+	var opts godocmd.Options
+	if cfg != nil {
+		opts = cfg.Options()
+	}
+
+	for name, field := range map[string]*bool{
+		"recursive":            &opts.Recursive,
+		"include-private":      &opts.IncludePrivate,
+		"include-undocumented": &opts.IncludeUndocumented,
+		"hide-deprecated":      &opts.HideDeprecated,
+		"verify-examples":      &opts.VerifyExamples,
+		"exclude-internal":     &opts.ExcludeInternal,
+		"no-default-excludes":  &opts.NoDefaultExcludes,
+		"no-gitignore":         &opts.NoGitignore,
+		"all-packages":         &opts.AllPackages,
+		"skip-main":            &opts.SkipMain,
+		"external-tests":       &opts.ExternalTests,
+	} {
+		if c.IsSet(name) {
+			*field = c.Bool(name)
+		}
+	}
+	opts.Verbose = c.Bool("verbose")
+	if c.IsSet("note-markers") || opts.NoteMarkers == nil {
+		opts.NoteMarkers = c.StringSlice("note-markers")
+	}
+	if c.IsSet("include") {
+		opts.Include = c.StringSlice("include")
+	}
+	if c.IsSet("exclude") {
+		opts.Exclude = c.StringSlice("exclude")
+	}
+	if c.IsSet("template-dir") {
+		opts.TemplateDir = c.String("template-dir")
+	}
+	if c.IsSet("goos") {
+		opts.GOOS = c.String("goos")
+	}
+	if c.IsSet("goarch") {
+		opts.GOARCH = c.String("goarch")
+	}
+	if c.IsSet("tags") {
+		opts.BuildTags = c.StringSlice("tags")
+	}
+	if c.IsSet("platforms") {
+		opts.Platforms = c.StringSlice("platforms")
+	}
+	if c.IsSet("module") {
+		opts.Module = c.String("module")
+	}
+	return opts
+}
+
+// outputsFromContext returns the outputs selected by the flags or, when no
+// output flag is set, the outputs of the configuration file.
+func outputsFromContext(c *cli.Context, cfg *config.File) []godocmd.Output {
+	flagged := c.IsSet("out") || c.IsSet("out-dir") || c.IsSet("inject") || c.IsSet("format")
+	if flagged || cfg == nil || len(cfg.Outputs) == 0 {
+		return []godocmd.Output{{
+			Format: c.String("format"),
+			File:   c.String("out"),
+			Dir:    c.String("out-dir"),
+			Inject: c.String("inject"),
+		}}
+	}
+
+	outputs := cfg.Targets()
+	for i := range outputs {
+		if outputs[i].Format == "" {
+			outputs[i].Format = "markdown"
+		}
+	}
+	return outputs
+}
+
+// generate writes the documentation to each destination selected by the flags
+// or the configuration file.
+func generate(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	// Unlike the subcommands, generating never falls back to the working directory.
+	if !c.IsSet("dir") && (cfg == nil || cfg.Root == "") {
+		return errors.New("--dir is required unless the configuration file sets root")
+	}
+	opts := optionsFromContext(c, cfg)
+	for _, output := range outputsFromContext(c, cfg) {
+		if err := writeOutput(dirFromContext(c, cfg), output, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOutput writes the documentation of dir to a single destination.
+func writeOutput(dir string, output godocmd.Output, opts godocmd.Options) error {
+	outPath, outFormat := output.File, output.Format
+
+	if file := output.Inject; file != "" {
+		if outFormat != "markdown" {
+			return fmt.Errorf("--inject is only supported for --format markdown")
+		}
+		return godocmd.InjectMarkdown(dir, file, opts)
+	}
+
+	if outDir := output.Dir; outDir != "" {
+		if outFormat != "markdown" {
+			return fmt.Errorf("--out-dir is only supported for --format markdown")
+		}
+		return godocmd.GenerateMarkdownFiles(dir, outDir, opts)
+	}
+
+	switch outFormat {
+	case "markdown", "json":
+	case "html":
+		if outPath == "" {
+			return fmt.Errorf("--out is required for --format html")
+		}
+		return godocmd.GenerateHTML(dir, outPath, opts)
+	default:
+		return fmt.Errorf("unknown format %q", outFormat)
+	}
+
+	out, closeOut, err := createOutput(outPath)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	if outFormat == "json" {
+		return godocmd.GenerateJSON(dir, out, opts)
+	}
+	if outPath != "" {
+		opts.OutputDir = filepath.Dir(outPath)
+	}
+	return godocmd.GenerateMarkdownWithOptions(dir, out, opts)
+}
+
+// createOutput creates the file at outPath along with its parent directories, or
+// returns stdout when outPath is empty. The returned function closes the file.
+func createOutput(outPath string) (io.Writer, func() error, error) {
+	if outPath == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// check regenerates the documentation and compares it with the committed output
+// of each destination selected by the flags or the configuration file.
+func check(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	stale, checked := 0, 0
+	for _, output := range outputsFromContext(c, cfg) {
+		// Stdout and HTML sites have no committed output to compare with.
+		if output.Format == "html" || (output.File == "" && output.Dir == "" && output.Inject == "") {
+			continue
+		}
+		checked++
+		if err := godocmd.Check(dirFromContext(c, cfg), output, opts, os.Stdout); err != nil {
+			if !errors.Is(err, godocmd.ErrStale) {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			stale++
+		}
+	}
+	if checked == 0 {
+		return fmt.Errorf("check requires --out, --out-dir or --inject with --format markdown or json")
+	}
+	if stale > 0 {
+		return fmt.Errorf("%w: %d output(s) differ", godocmd.ErrStale, stale)
+	}
+	fmt.Fprintln(os.Stderr, "✅ Documentation is up to date")
+	return nil
+}
+
+// reportCoverage prints the documentation coverage and enforces the --min threshold.
+func reportCoverage(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	report, err := godocmd.Coverage(dirFromContext(c, cfg), opts)
+	if err != nil {
+		return err
+	}
+	coverage.WriteText(report, os.Stdout)
+
+	if min := c.Float64("min"); report.Percent() < min {
+		return fmt.Errorf("documentation coverage %.1f%% is below the minimum of %.1f%%", report.Percent(), min)
+	}
+	return nil
+}
+
+// lintDocs reports doc comment issues and fails when there are any.
+func lintDocs(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	issues, err := godocmd.Lint(dirFromContext(c, cfg), opts)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := createOutput(c.String("out"))
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	switch c.String("format") {
+	case "text":
+		lint.WriteText(issues, out)
+	case "sarif":
+		if err := lint.WriteSARIF(issues, out); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", c.String("format"))
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d doc comment issue(s) found", len(issues))
+	}
+	return nil
+}
+
+// envVars writes the environment variable reference or a sample .env file.
+func envVars(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	vars, err := godocmd.EnvVars(dirFromContext(c, cfg), opts)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := createOutput(c.String("out"))
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	switch c.String("format") {
+	case "markdown":
+		envvars.WriteMarkdown(vars, out)
+	case "dotenv":
+		envvars.WriteDotEnv(vars, out)
+	default:
+		return fmt.Errorf("unknown format %q", c.String("format"))
+	}
+	return nil
+}
+
+// diffAPI writes a markdown report of the API changes since --base.
+func diffAPI(c *cli.Context) error {
+	base := c.String("base")
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	report, err := godocmd.Diff(dirFromContext(c, cfg), base, opts)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := createOutput(c.String("out"))
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+	apidiff.WriteMarkdown(report, "API changes since "+base, out)
+	return nil
+}
+
+// changelog writes a markdown changelog of the API changes of every semver tag.
+func changelog(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	releases, err := godocmd.Changelog(dirFromContext(c, cfg), opts)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := createOutput(c.String("out"))
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+	apidiff.WriteChangelog(releases, out)
+	return nil
+}
+
+// recommendVersion prints the required version bump and validates --tag against it.
+func recommendVersion(c *cli.Context) error {
+	cfg, err := configFromContext(c)
+	if err != nil {
+		return err
+	}
+	opts := optionsFromContext(c, cfg)
+
+	r, err := godocmd.RecommendVersion(dirFromContext(c, cfg), opts)
+	if err != nil {
+		return err
+	}
+	semver.WriteText(r, os.Stdout)
+	if tag := c.String("tag"); tag != "" {
+		return r.Validate(tag)
 	}
+	return nil
 }
@@ -0,0 +1,323 @@
+package command
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// pflagTypes lists the flag types of spf13/pflag, the flag package cobra uses,
+// by the names of their FlagSet methods.
+var pflagTypes = map[string]bool{
+	"Bool": true, "BoolSlice": true, "BytesBase64": true, "BytesHex": true, "Count": true,
+	"Duration": true, "DurationSlice": true, "Float32": true, "Float32Slice": true,
+	"Float64": true, "Float64Slice": true, "IP": true, "IPMask": true, "IPNet": true,
+	"IPSlice": true, "Int": true, "Int8": true, "Int16": true, "Int32": true, "Int32Slice": true,
+	"Int64": true, "Int64Slice": true, "IntSlice": true, "String": true, "StringArray": true,
+	"StringSlice": true, "StringToInt": true, "StringToInt64": true, "StringToString": true,
+	"Uint": true, "Uint8": true, "Uint16": true, "Uint32": true, "Uint64": true, "UintSlice": true,
+}
+
+// detectCobra extracts a cobra application. Commands are cobra.Command
+// literals, linked by AddCommand calls; the root is the first command that is
+// not added to another. Flags are read from the calls on Flags() and
+// PersistentFlags(), and required markers from MarkFlagRequired.
+//
+// Parameters:
+//   - s: The package source
+//   - name: The default program name
+//
+// Returns:
+//   - *Command: The root command, or nil if cobra is not used
+func detectCobra(s *source, name string) *Command {
+	pkg := importName(s.files, "github.com/spf13/cobra")
+	if pkg == "" {
+		return nil
+	}
+
+	c := &cobra{
+		source:   s,
+		children: map[*ast.CompositeLit][]*ast.CompositeLit{},
+		added:    map[*ast.CompositeLit]bool{},
+		flags:    map[*ast.CompositeLit][]Flag{},
+		required: map[*ast.CompositeLit]map[string]bool{},
+		hidden:   map[*ast.CompositeLit]map[string]bool{},
+	}
+	var lits []*ast.CompositeLit
+	s.inspect(func(scope string, n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if _, ok := isSelector(n.Type, pkg, "Command"); ok {
+				lits = append(lits, n)
+			}
+		case *ast.CallExpr:
+			c.call(scope, n)
+		}
+	})
+
+	for _, lit := range lits {
+		if c.added[lit] {
+			continue
+		}
+		if root, ok := c.command(lit, map[*ast.CompositeLit]bool{}); ok {
+			if root.Name == "" {
+				root.Name = name
+			}
+			root.Framework = FrameworkCobra
+			return &root
+		}
+	}
+	return nil
+}
+
+// cobra collects the commands and flags of a cobra application.
+type cobra struct {
+	*source
+
+	// children holds the commands added to each command, in call order.
+	children map[*ast.CompositeLit][]*ast.CompositeLit
+
+	// added records the commands added to another command.
+	added map[*ast.CompositeLit]bool
+
+	// flags holds the flags defined on each command.
+	flags map[*ast.CompositeLit][]Flag
+
+	// required and hidden hold the flag names marked required or hidden per command.
+	required map[*ast.CompositeLit]map[string]bool
+	hidden   map[*ast.CompositeLit]map[string]bool
+}
+
+// call records what a call contributes: subcommands, flags or flag markers.
+//
+// Parameters:
+//   - scope: The function the call appears in
+//   - call: The call
+func (c *cobra) call(scope string, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	switch sel.Sel.Name {
+	case "AddCommand":
+		parent := c.literal(scope, sel.X)
+		if parent == nil {
+			return
+		}
+		for _, arg := range call.Args {
+			if child := c.literal(scope, arg); child != nil {
+				c.children[parent] = append(c.children[parent], child)
+				c.added[child] = true
+			}
+		}
+		return
+	case "MarkFlagRequired", "MarkPersistentFlagRequired":
+		c.mark(c.required, c.literal(scope, sel.X), call)
+		return
+	}
+
+	// The remaining calls are made on cmd.Flags() or cmd.PersistentFlags().
+	owner := c.flagsOwner(scope, sel.X)
+	if owner == nil {
+		return
+	}
+	if sel.Sel.Name == "MarkHidden" {
+		c.mark(c.hidden, owner, call)
+		return
+	}
+	if f, ok := pflagFlag(sel.Sel.Name, call.Args); ok {
+		c.flags[owner] = append(c.flags[owner], f)
+	}
+}
+
+// mark records the flag named by the first argument of a call in marks.
+//
+// Parameters:
+//   - marks: The marks per command
+//   - owner: The command, nil if it could not be resolved
+//   - call: The marking call
+func (c *cobra) mark(marks map[*ast.CompositeLit]map[string]bool, owner *ast.CompositeLit, call *ast.CallExpr) {
+	if owner == nil || len(call.Args) == 0 {
+		return
+	}
+	name, ok := stringValue(call.Args[0])
+	if !ok {
+		return
+	}
+	if marks[owner] == nil {
+		marks[owner] = map[string]bool{}
+	}
+	marks[owner][name] = true
+}
+
+// flagsOwner returns the command whose Flags() or PersistentFlags() an
+// expression is.
+//
+// Parameters:
+//   - scope: The function the expression appears in
+//   - expr: The expression, e.g. cmd.Flags() or a variable holding it
+//
+// Returns:
+//   - *ast.CompositeLit: The command literal, or nil
+func (c *cobra) flagsOwner(scope string, expr ast.Expr) *ast.CompositeLit {
+	scope, expr = c.resolve(scope, expr)
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	switch sel.Sel.Name {
+	case "Flags", "PersistentFlags", "LocalFlags":
+		return c.literal(scope, sel.X)
+	}
+	return nil
+}
+
+// literal resolves an expression to a cobra.Command literal, following calls
+// to package functions that construct the command.
+//
+// Parameters:
+//   - scope: The function the expression appears in
+//   - expr: The expression
+//
+// Returns:
+//   - *ast.CompositeLit: The literal, or nil
+func (c *cobra) literal(scope string, expr ast.Expr) *ast.CompositeLit {
+	scope, expr = c.resolve(scope, expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if id, ok := call.Fun.(*ast.Ident); ok {
+			if ret := c.returned(id.Name); ret != nil {
+				_, expr = c.resolve(id.Name, ret)
+			}
+		}
+	}
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit
+}
+
+// command converts a cobra.Command literal and its subcommands.
+//
+// Parameters:
+//   - lit: The literal
+//   - visiting: The commands being converted, guarding against cycles
+//
+// Returns:
+//   - Command: The command
+//   - bool: False if the command is hidden
+func (c *cobra) command(lit *ast.CompositeLit, visiting map[*ast.CompositeLit]bool) (Command, bool) {
+	var cmd Command
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch key.Name {
+		case "Use":
+			use, _ := stringValue(kv.Value)
+			cmd.Name, _, _ = strings.Cut(strings.TrimSpace(use), " ")
+			if strings.Contains(strings.TrimSpace(use), " ") {
+				cmd.UsageText = strings.TrimSpace(use)
+			}
+		case "Short":
+			cmd.Usage, _ = stringValue(kv.Value)
+		case "Long":
+			cmd.Description, _ = stringValue(kv.Value)
+		case "Aliases":
+			cmd.Aliases = stringList(kv.Value)
+		case "Hidden":
+			if isTrue(kv.Value) {
+				return Command{}, false
+			}
+		}
+	}
+
+	for _, f := range c.flags[lit] {
+		if c.hidden[lit][f.Name] {
+			continue
+		}
+		f.Required = c.required[lit][f.Name]
+		cmd.Flags = append(cmd.Flags, f)
+	}
+
+	visiting[lit] = true
+	for _, child := range c.children[lit] {
+		if visiting[child] {
+			continue
+		}
+		if sub, ok := c.command(child, visiting); ok {
+			cmd.Commands = append(cmd.Commands, sub)
+		}
+	}
+	delete(visiting, lit)
+	return cmd, true
+}
+
+// pflagFlag converts a pflag FlagSet method call such as StringVarP(&v,
+// "name", "n", "default", "usage").
+//
+// Parameters:
+//   - method: The method name
+//   - args: The call arguments
+//
+// Returns:
+//   - Flag: The flag
+//   - bool: False if the call does not define a flag
+func pflagFlag(method string, args []ast.Expr) (Flag, bool) {
+	typ, isVar, shorthand := method, false, false
+	switch {
+	case strings.HasSuffix(typ, "VarP"):
+		typ, isVar, shorthand = strings.TrimSuffix(typ, "VarP"), true, true
+	case strings.HasSuffix(typ, "Var"):
+		typ, isVar = strings.TrimSuffix(typ, "Var"), true
+	case strings.HasSuffix(typ, "P") && pflagTypes[strings.TrimSuffix(typ, "P")]:
+		typ, shorthand = strings.TrimSuffix(typ, "P"), true
+	}
+	if !pflagTypes[typ] {
+		return Flag{}, false
+	}
+
+	if isVar {
+		if len(args) == 0 {
+			return Flag{}, false
+		}
+		args = args[1:]
+	}
+	// Count flags take no default value.
+	want := 3
+	if typ == "Count" {
+		want = 2
+	}
+	if shorthand {
+		want++
+	}
+	if len(args) != want {
+		return Flag{}, false
+	}
+
+	name, ok := stringValue(args[0])
+	if !ok {
+		return Flag{}, false
+	}
+	f := Flag{Name: name, Type: flagType(typ)}
+	f.Usage, _ = stringValue(args[len(args)-1])
+	if shorthand {
+		if short, ok := stringValue(args[1]); ok && short != "" {
+			f.Aliases = []string{short}
+		}
+	}
+	if typ != "Count" {
+		value := args[len(args)-2]
+		if lit, ok := value.(*ast.CompositeLit); ok {
+			f.Default = strings.Join(stringList(lit), ", ")
+		} else {
+			f.Default = defaultValue(value)
+		}
+	}
+	return f, true
+}
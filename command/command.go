@@ -0,0 +1,461 @@
+// Package command extracts the command line interface of a main package from
+// its syntax tree. It recognises applications built with urfave/cli, cobra and
+// the standard flag package, without building or running them.
+package command
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/thinktide/godocmd/astprint"
+)
+
+// Frameworks recognised by Detect, as reported in Command.Framework.
+const (
+	FrameworkUrfave = "urfave/cli"
+	FrameworkCobra  = "cobra"
+	FrameworkFlag   = "flag"
+)
+
+// Command describes a command and its flags and subcommands.
+type Command struct {
+	Name        string    `json:"name"`
+	Framework   string    `json:"framework,omitempty"` // set on the root command only
+	Aliases     []string  `json:"aliases,omitempty"`
+	Usage       string    `json:"usage,omitempty"`     // a one-line description
+	UsageText   string    `json:"usageText,omitempty"` // the invocation syntax, e.g. "app serve [flags]"
+	Description string    `json:"description,omitempty"`
+	Flags       []Flag    `json:"flags,omitempty"`
+	Commands    []Command `json:"commands,omitempty"`
+}
+
+// Flag describes a command line flag.
+type Flag struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"` // other names, including one-letter shorthands
+	Type     string   `json:"type,omitempty"`    // e.g. "string", "bool" or "string slice"
+	Default  string   `json:"default,omitempty"` // the default value as written in the source, empty for zero values
+	Usage    string   `json:"usage,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	EnvVars  []string `json:"envVars,omitempty"` // environment variables the flag is read from
+}
+
+// Detect extracts the command line interface of a main package. Frameworks are
+// tried in the order urfave/cli, cobra and flag, by the packages the files import.
+//
+// Parameters:
+//   - name: The program name to use when the source does not set one, usually the directory name
+//   - files: The source files of the package
+//
+// Returns:
+//   - *Command: The root command, or nil if no supported framework is used
+func Detect(name string, files []*ast.File) *Command {
+	src := newSource(files)
+	for _, detect := range []func(*source, string) *Command{detectUrfave, detectCobra, detectFlag} {
+		if cmd := detect(src, name); cmd != nil {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// source indexes the declarations of a package for resolving the expressions
+// that flags and commands are built from.
+type source struct {
+	files []*ast.File
+
+	// funcs holds the package's functions by name.
+	funcs map[string]*ast.FuncDecl
+
+	// values holds the first value assigned to each variable, by scope and name.
+	values map[scopedName]ast.Expr
+}
+
+// scopedName identifies a variable: its name and the function declaring it,
+// empty for package-level variables.
+type scopedName struct {
+	scope string
+	name  string
+}
+
+// newSource indexes the functions and variable assignments of files.
+//
+// Parameters:
+//   - files: The source files
+//
+// Returns:
+//   - *source: The index
+func newSource(files []*ast.File) *source {
+	s := &source{files: files, funcs: map[string]*ast.FuncDecl{}, values: map[scopedName]ast.Expr{}}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					s.funcs[d.Name.Name] = d
+				}
+				if d.Body != nil {
+					s.indexAssignments(d.Name.Name, d.Body)
+				}
+			case *ast.GenDecl:
+				s.indexValueSpecs("", d)
+			}
+		}
+	}
+	return s
+}
+
+// indexAssignments records the variables assigned in a function body.
+//
+// Parameters:
+//   - scope: The function name
+//   - body: The function body
+func (s *source) indexAssignments(scope string, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok {
+					s.record(scopedName{scope, id.Name}, n.Rhs[i])
+				}
+			}
+		case *ast.GenDecl:
+			s.indexValueSpecs(scope, n)
+		}
+		return true
+	})
+}
+
+// indexValueSpecs records the variables of a var declaration.
+//
+// Parameters:
+//   - scope: The declaring function name, empty at package level
+//   - d: The declaration
+func (s *source) indexValueSpecs(scope string, d *ast.GenDecl) {
+	if d.Tok != token.VAR {
+		return
+	}
+	for _, spec := range d.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != len(vs.Values) {
+			continue
+		}
+		for i, n := range vs.Names {
+			s.record(scopedName{scope, n.Name}, vs.Values[i])
+		}
+	}
+}
+
+// record stores the first value assigned to a variable.
+//
+// Parameters:
+//   - name: The variable
+//   - value: The assigned expression
+func (s *source) record(name scopedName, value ast.Expr) {
+	if _, ok := s.values[name]; !ok {
+		s.values[name] = value
+	}
+}
+
+// resolve follows an expression through parentheses, address operators,
+// variables and calls to argument-less package functions to the expression
+// that produces its value.
+//
+// Parameters:
+//   - scope: The function the expression appears in, empty at package level
+//   - expr: The expression
+//
+// Returns:
+//   - string: The scope of the resolved expression
+//   - ast.Expr: The resolved expression
+func (s *source) resolve(scope string, expr ast.Expr) (string, ast.Expr) {
+	for depth := 0; depth < 16; depth++ {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.UnaryExpr:
+			if e.Op != token.AND {
+				return scope, expr
+			}
+			expr = e.X
+		case *ast.Ident:
+			if v, ok := s.values[scopedName{scope, e.Name}]; ok {
+				expr = v
+			} else if v, ok := s.values[scopedName{"", e.Name}]; ok {
+				scope, expr = "", v
+			} else {
+				return scope, expr
+			}
+		case *ast.CallExpr:
+			id, ok := e.Fun.(*ast.Ident)
+			if !ok || len(e.Args) != 0 {
+				return scope, expr
+			}
+			ret := s.returned(id.Name)
+			if ret == nil {
+				return scope, expr
+			}
+			scope, expr = id.Name, ret
+		default:
+			return scope, expr
+		}
+	}
+	return scope, expr
+}
+
+// returned returns the first result of the last return statement of a package
+// function.
+//
+// Parameters:
+//   - name: The function name
+//
+// Returns:
+//   - ast.Expr: The returned expression, or nil if the function is unknown or returns nothing
+func (s *source) returned(name string) ast.Expr {
+	fn, ok := s.funcs[name]
+	if !ok || fn.Body == nil {
+		return nil
+	}
+	var ret ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if r, ok := n.(*ast.ReturnStmt); ok && len(r.Results) > 0 {
+			ret = r.Results[0]
+		}
+		return true
+	})
+	return ret
+}
+
+// inspect visits every node of the package's declarations with the function
+// it appears in, empty for package-level declarations.
+//
+// Parameters:
+//   - visit: Called for each node
+func (s *source) inspect(visit func(scope string, n ast.Node)) {
+	for _, f := range s.files {
+		for _, decl := range f.Decls {
+			scope := ""
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				scope = fn.Name.Name
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if n != nil {
+					visit(scope, n)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// list resolves a slice expression to its elements: the elements of a slice
+// literal, or of every argument of an append call.
+//
+// Parameters:
+//   - scope: The function the expression appears in
+//   - expr: The expression
+//
+// Returns:
+//   - []scopedExpr: The elements with the scope they appear in
+func (s *source) list(scope string, expr ast.Expr) []scopedExpr {
+	scope, expr = s.resolve(scope, expr)
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		out := make([]scopedExpr, 0, len(e.Elts))
+		for _, elt := range e.Elts {
+			out = append(out, scopedExpr{scope, elt})
+		}
+		return out
+	case *ast.CallExpr:
+		if id, ok := e.Fun.(*ast.Ident); !ok || id.Name != "append" {
+			return nil
+		}
+		var out []scopedExpr
+		for i, arg := range e.Args {
+			if i == 0 || e.Ellipsis.IsValid() && i == len(e.Args)-1 {
+				out = append(out, s.list(scope, arg)...)
+			} else {
+				out = append(out, scopedExpr{scope, arg})
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// scopedExpr is an expression together with the function it appears in.
+type scopedExpr struct {
+	scope string
+	expr  ast.Expr
+}
+
+// importName returns the name a file set refers to an import path by.
+//
+// Parameters:
+//   - files: The source files
+//   - paths: The import paths to look for
+//
+// Returns:
+//   - string: The package name of the first matching import, or an empty string if none is imported
+func importName(files []*ast.File, paths ...string) string {
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			p, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			for _, want := range paths {
+				if p != want {
+					continue
+				}
+				if imp.Name != nil {
+					return imp.Name.Name
+				}
+				// urfave/cli/v2 and similar are imported as their last non-version element.
+				elems := strings.Split(p, "/")
+				name := elems[len(elems)-1]
+				if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+					name = elems[len(elems)-2]
+				}
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// isSelector reports whether an expression is pkg.name, such as cli.App.
+//
+// Parameters:
+//   - expr: The expression
+//   - pkg: The package name
+//   - name: The selected name, or an empty string for any
+//
+// Returns:
+//   - string: The selected name
+//   - bool: True if the expression matches
+func isSelector(expr ast.Expr, pkg, name string) (string, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok || id.Name != pkg || (name != "" && sel.Sel.Name != name) {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// stringValue returns the value of a string constant expression.
+//
+// Parameters:
+//   - expr: The expression, a string literal or a concatenation of them
+//
+// Returns:
+//   - string: The string
+//   - bool: False if the expression is not a constant string
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
+
+// stringList returns the constant strings of a []string literal.
+//
+// Parameters:
+//   - expr: The expression
+//
+// Returns:
+//   - []string: The strings, skipping elements that are not constant
+func stringList(expr ast.Expr) []string {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, elt := range lit.Elts {
+		if s, ok := stringValue(elt); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// defaultValue renders a default value expression, leaving out zero values.
+//
+// Parameters:
+//   - expr: The expression
+//
+// Returns:
+//   - string: The value as written in the source, unquoted for strings
+func defaultValue(expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	if s, ok := stringValue(expr); ok {
+		return s
+	}
+	text := astprint.Expr(expr)
+	switch text {
+	case "false", "0", "0.0", "nil", "\"\"":
+		return ""
+	}
+	return text
+}
+
+// isTrue reports whether an expression is the constant true.
+//
+// Parameters:
+//   - expr: The expression
+//
+// Returns:
+//   - bool: True for the identifier true
+func isTrue(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "true"
+}
+
+// flagType converts a flag constructor or method name such as "StringSlice"
+// into the type shown in documentation, e.g. "string slice".
+//
+// Parameters:
+//   - name: The name without its "Flag", "Var" or "P" affixes
+//
+// Returns:
+//   - string: The lower-case type with words separated by spaces
+func flagType(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
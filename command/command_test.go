@@ -0,0 +1,155 @@
+package command
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// detect parses a single source file and runs Detect on it.
+func detect(t *testing.T, code string) *Command {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Detect("prog", []*ast.File{file})
+}
+
+// toJSON renders a command compactly for comparison.
+func toJSON(t *testing.T, cmd *Command) string {
+	t.Helper()
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDetect_Urfave(t *testing.T) {
+	cmd := detect(t, `
+		package main
+
+		import "github.com/urfave/cli/v2"
+
+		func commonFlags() []cli.Flag {
+			return []cli.Flag{
+				&cli.StringFlag{Name: "dir", Aliases: []string{"d"}, Value: ".", Usage: "Root directory", EnvVars: []string{"APP_DIR"}},
+			}
+		}
+
+		func main() {
+			app := &cli.App{
+				Name:  "tool",
+				Usage: "Does things",
+				Flags: append(commonFlags(),
+					&cli.BoolFlag{Name: "verbose", Usage: "Log more"},
+					&cli.StringSliceFlag{Name: "tag", Value: cli.NewStringSlice("a", "b")},
+					&cli.BoolFlag{Name: "debug", Hidden: true},
+				),
+				Commands: []*cli.Command{
+					{
+						Name:    "check",
+						Aliases: []string{"c"},
+						Usage:   "Checks things",
+						Flags:   []cli.Flag{&cli.StringFlag{Name: "out", Required: true}},
+					},
+					{Name: "secret", Hidden: true},
+				},
+			}
+			app.Run(nil)
+		}
+	`)
+	want := `{"name":"tool","framework":"urfave/cli","usage":"Does things","flags":[` +
+		`{"name":"dir","aliases":["d"],"type":"string","default":".","usage":"Root directory","envVars":["APP_DIR"]},` +
+		`{"name":"verbose","type":"bool","usage":"Log more"},` +
+		`{"name":"tag","type":"string slice","default":"a, b"}],` +
+		`"commands":[{"name":"check","aliases":["c"],"usage":"Checks things","flags":[{"name":"out","type":"string","required":true}]}]}`
+	if got := toJSON(t, cmd); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestDetect_Cobra(t *testing.T) {
+	cmd := detect(t, `
+		package main
+
+		import "github.com/spf13/cobra"
+
+		var rootCmd = &cobra.Command{Use: "app", Short: "An app"}
+
+		func newServeCmd() *cobra.Command {
+			var port int
+			cmd := &cobra.Command{
+				Use:     "serve [flags]",
+				Aliases: []string{"s"},
+				Short:   "Serves",
+			}
+			cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to listen on")
+			cmd.Flags().String("token", "", "API token")
+			cmd.MarkFlagRequired("token")
+			cmd.Flags().Bool("trace", false, "Trace")
+			cmd.Flags().MarkHidden("trace")
+			return cmd
+		}
+
+		func init() {
+			flags := rootCmd.PersistentFlags()
+			flags.CountP("verbose", "v", "Verbosity")
+			flags.StringSlice("label", []string{"x"}, "Labels")
+			rootCmd.AddCommand(newServeCmd())
+		}
+
+		func main() { rootCmd.Execute() }
+	`)
+	want := `{"name":"app","framework":"cobra","usage":"An app","flags":[` +
+		`{"name":"verbose","aliases":["v"],"type":"count","usage":"Verbosity"},` +
+		`{"name":"label","type":"string slice","default":"x","usage":"Labels"}],` +
+		`"commands":[{"name":"serve","aliases":["s"],"usage":"Serves","usageText":"serve [flags]","flags":[` +
+		`{"name":"port","aliases":["p"],"type":"int","default":"8080","usage":"Port to listen on"},` +
+		`{"name":"token","type":"string","usage":"API token","required":true}]}]}`
+	if got := toJSON(t, cmd); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestDetect_Flag(t *testing.T) {
+	cmd := detect(t, `
+		package main
+
+		import (
+			"flag"
+			"os"
+			"time"
+		)
+
+		var addr = flag.String("addr", ":8080", "Listen address")
+
+		func main() {
+			var timeout time.Duration
+			flag.DurationVar(&timeout, "timeout", 5*time.Second, "Request timeout")
+			flag.Bool("quiet", false, "Suppress output")
+
+			migrate := flag.NewFlagSet("migrate", flag.ExitOnError)
+			migrate.Int("steps", 0, "Number of steps")
+			flag.Parse()
+			migrate.Parse(os.Args[2:])
+		}
+	`)
+	want := `{"name":"prog","framework":"flag","flags":[` +
+		`{"name":"addr","type":"string","default":":8080","usage":"Listen address"},` +
+		`{"name":"timeout","type":"duration","default":"5 * time.Second","usage":"Request timeout"},` +
+		`{"name":"quiet","type":"bool","usage":"Suppress output"}],` +
+		`"commands":[{"name":"migrate","flags":[{"name":"steps","type":"int","usage":"Number of steps"}]}]}`
+	if got := toJSON(t, cmd); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestDetect_None(t *testing.T) {
+	if cmd := detect(t, "package main\n\nfunc main() {}\n"); cmd != nil {
+		t.Errorf("expected no command, got %+v", cmd)
+	}
+}
@@ -0,0 +1,121 @@
+package command
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// stdFlagFuncs maps the flag definition functions of the standard flag
+// package, and the FlagSet methods of the same names, to the documented type.
+var stdFlagFuncs = map[string]string{
+	"Bool": "bool", "BoolVar": "bool", "BoolFunc": "bool",
+	"Duration": "duration", "DurationVar": "duration",
+	"Float64": "float64", "Float64Var": "float64",
+	"Func": "value", "Var": "value", "TextVar": "text",
+	"Int": "int", "IntVar": "int", "Int64": "int64", "Int64Var": "int64",
+	"String": "string", "StringVar": "string",
+	"Uint": "uint", "UintVar": "uint", "Uint64": "uint64", "Uint64Var": "uint64",
+}
+
+// detectFlag extracts the flags of a program using the standard flag package.
+// Flags defined on the package's command line become the root's flags; each
+// flag.NewFlagSet with a constant name other than the program's becomes a
+// subcommand.
+//
+// Parameters:
+//   - s: The package source
+//   - name: The program name
+//
+// Returns:
+//   - *Command: The root command, or nil if no flag is defined
+func detectFlag(s *source, name string) *Command {
+	pkg := importName(s.files, "flag")
+	if pkg == "" {
+		return nil
+	}
+
+	root := &Command{Name: name, Framework: FrameworkFlag}
+	sets := map[*ast.CallExpr]int{}
+	s.inspect(func(scope string, n ast.Node) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+		if _, ok := stdFlagFuncs[sel.Sel.Name]; !ok {
+			return
+		}
+
+		target := root
+		if _, ok := isSelector(sel, pkg, ""); !ok {
+			// A method call on a flag set.
+			_, x := s.resolve(scope, sel.X)
+			set, ok := x.(*ast.CallExpr)
+			if !ok {
+				return
+			}
+			if _, ok := isSelector(set.Fun, pkg, "NewFlagSet"); !ok || len(set.Args) == 0 {
+				return
+			}
+			if setName, ok := stringValue(set.Args[0]); ok && setName != name {
+				i, ok := sets[set]
+				if !ok {
+					i = len(root.Commands)
+					sets[set] = i
+					root.Commands = append(root.Commands, Command{Name: setName})
+				}
+				target = &root.Commands[i]
+			}
+		}
+		if f, ok := stdFlag(sel.Sel.Name, call.Args); ok {
+			target.Flags = append(target.Flags, f)
+		}
+	})
+	if len(root.Flags) == 0 && len(root.Commands) == 0 {
+		return nil
+	}
+	return root
+}
+
+// stdFlag converts a call defining a flag of the standard flag package.
+//
+// Parameters:
+//   - fn: The function or method name
+//   - args: The call arguments
+//
+// Returns:
+//   - Flag: The flag
+//   - bool: False if the arguments are not as expected
+func stdFlag(fn string, args []ast.Expr) (Flag, bool) {
+	var nameArg, valueArg, usageArg int
+	switch {
+	case fn == "Func" || fn == "BoolFunc":
+		// Func(name, usage, fn)
+		nameArg, valueArg, usageArg = 0, -1, 1
+	case fn == "Var":
+		// Var(value, name, usage)
+		nameArg, valueArg, usageArg = 1, -1, 2
+	case strings.HasSuffix(fn, "Var"):
+		// StringVar(&p, name, value, usage)
+		nameArg, valueArg, usageArg = 1, 2, 3
+	default:
+		// String(name, value, usage)
+		nameArg, valueArg, usageArg = 0, 1, 2
+	}
+	if len(args) <= usageArg {
+		return Flag{}, false
+	}
+	name, ok := stringValue(args[nameArg])
+	if !ok {
+		return Flag{}, false
+	}
+	f := Flag{Name: name, Type: stdFlagFuncs[fn]}
+	f.Usage, _ = stringValue(args[usageArg])
+	if valueArg >= 0 {
+		f.Default = defaultValue(args[valueArg])
+	}
+	return f, true
+}
@@ -0,0 +1,240 @@
+package command
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// urfavePaths lists the import paths of the urfave/cli major versions.
+var urfavePaths = []string{"github.com/urfave/cli/v3", "github.com/urfave/cli/v2", "github.com/urfave/cli"}
+
+// detectUrfave extracts an urfave/cli application: the cli.App literal of v1
+// and v2, or the v3 cli.Command literal that no other command lists.
+//
+// Parameters:
+//   - s: The package source
+//   - name: The default program name
+//
+// Returns:
+//   - *Command: The root command, or nil if urfave/cli is not used
+func detectUrfave(s *source, name string) *Command {
+	pkg := importName(s.files, urfavePaths...)
+	if pkg == "" {
+		return nil
+	}
+
+	var apps, commands []scopedExpr
+	s.inspect(func(scope string, n ast.Node) {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return
+		}
+		if _, ok := isSelector(lit.Type, pkg, "App"); ok {
+			apps = append(apps, scopedExpr{scope, lit})
+		} else if _, ok := isSelector(lit.Type, pkg, "Command"); ok {
+			commands = append(commands, scopedExpr{scope, lit})
+		}
+	})
+
+	u := &urfave{source: s, pkg: pkg, children: map[*ast.CompositeLit]bool{}}
+	var root *Command
+	if len(apps) > 0 {
+		cmd, _ := u.command(apps[0].scope, apps[0].expr.(*ast.CompositeLit))
+		root = &cmd
+	} else {
+		parsed := make([]Command, len(commands))
+		for i, c := range commands {
+			parsed[i], _ = u.command(c.scope, c.expr.(*ast.CompositeLit))
+		}
+		for i, c := range commands {
+			if !u.children[c.expr.(*ast.CompositeLit)] {
+				root = &parsed[i]
+				break
+			}
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	if root.Name == "" {
+		root.Name = name
+	}
+	root.Framework = FrameworkUrfave
+	return root
+}
+
+// urfave converts urfave/cli literals.
+type urfave struct {
+	*source
+	pkg string
+
+	// children records the command literals listed by other commands.
+	children map[*ast.CompositeLit]bool
+}
+
+// command converts a cli.App or cli.Command literal.
+//
+// Parameters:
+//   - scope: The function the literal appears in
+//   - lit: The literal
+//
+// Returns:
+//   - Command: The command
+//   - bool: False if the command is hidden
+func (u *urfave) command(scope string, lit *ast.CompositeLit) (Command, bool) {
+	var cmd Command
+	visible := true
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch key.Name {
+		case "Name":
+			cmd.Name, _ = stringValue(kv.Value)
+		case "Usage":
+			cmd.Usage, _ = stringValue(kv.Value)
+		case "UsageText":
+			cmd.UsageText, _ = stringValue(kv.Value)
+		case "Description":
+			cmd.Description, _ = stringValue(kv.Value)
+		case "Aliases":
+			_, v := u.resolve(scope, kv.Value)
+			cmd.Aliases = stringList(v)
+		case "Hidden":
+			visible = !isTrue(kv.Value)
+		case "Flags":
+			for _, e := range u.list(scope, kv.Value) {
+				if f, ok := u.flag(e.scope, e.expr); ok {
+					cmd.Flags = append(cmd.Flags, f)
+				}
+			}
+		case "Commands", "Subcommands":
+			for _, e := range u.list(scope, kv.Value) {
+				sc, x := u.resolve(e.scope, e.expr)
+				sub, ok := x.(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				u.children[sub] = true
+				if c, ok := u.command(sc, sub); ok {
+					cmd.Commands = append(cmd.Commands, c)
+				}
+			}
+		}
+	}
+	return cmd, visible
+}
+
+// flag converts a flag literal such as &cli.StringFlag{...}.
+//
+// Parameters:
+//   - scope: The function the expression appears in
+//   - expr: The flag expression
+//
+// Returns:
+//   - Flag: The flag
+//   - bool: False if the expression is not a flag literal or the flag is hidden
+func (u *urfave) flag(scope string, expr ast.Expr) (Flag, bool) {
+	scope, expr = u.resolve(scope, expr)
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return Flag{}, false
+	}
+	typeName, ok := isSelector(lit.Type, u.pkg, "")
+	if !ok || !strings.HasSuffix(typeName, "Flag") {
+		return Flag{}, false
+	}
+
+	f := Flag{Type: flagType(strings.TrimSuffix(typeName, "Flag"))}
+	defaultText := ""
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch key.Name {
+		case "Name":
+			// urfave/cli v1 lists aliases in the name: "dir, d".
+			name, _ := stringValue(kv.Value)
+			for i, n := range strings.Split(name, ",") {
+				if i == 0 {
+					f.Name = strings.TrimSpace(n)
+				} else {
+					f.Aliases = append(f.Aliases, strings.TrimSpace(n))
+				}
+			}
+		case "Aliases":
+			_, v := u.resolve(scope, kv.Value)
+			f.Aliases = append(f.Aliases, stringList(v)...)
+		case "Usage":
+			f.Usage, _ = stringValue(kv.Value)
+		case "Value":
+			f.Default = u.value(scope, kv.Value)
+		case "DefaultText":
+			defaultText, _ = stringValue(kv.Value)
+		case "Required":
+			f.Required = isTrue(kv.Value)
+		case "Hidden":
+			if isTrue(kv.Value) {
+				return Flag{}, false
+			}
+		case "EnvVars", "EnvVar":
+			_, v := u.resolve(scope, kv.Value)
+			if s, ok := stringValue(v); ok {
+				f.EnvVars = append(f.EnvVars, strings.Split(s, ",")...)
+			} else {
+				f.EnvVars = append(f.EnvVars, stringList(v)...)
+			}
+		case "Sources":
+			// v3: Sources: cli.EnvVars("A", "B")
+			if call, ok := kv.Value.(*ast.CallExpr); ok {
+				if _, ok := isSelector(call.Fun, u.pkg, "EnvVars"); ok {
+					for _, arg := range call.Args {
+						if s, ok := stringValue(arg); ok {
+							f.EnvVars = append(f.EnvVars, s)
+						}
+					}
+				}
+			}
+		}
+	}
+	if defaultText != "" {
+		f.Default = defaultText
+	}
+	return f, f.Name != ""
+}
+
+// value renders a flag's Value, listing the elements of cli.NewStringSlice and
+// similar constructors.
+//
+// Parameters:
+//   - scope: The function the expression appears in
+//   - expr: The expression
+//
+// Returns:
+//   - string: The default value
+func (u *urfave) value(scope string, expr ast.Expr) string {
+	_, resolved := u.resolve(scope, expr)
+	if call, ok := resolved.(*ast.CallExpr); ok {
+		if name, ok := isSelector(call.Fun, u.pkg, ""); ok && strings.HasPrefix(name, "New") && strings.HasSuffix(name, "Slice") {
+			var values []string
+			for _, arg := range call.Args {
+				values = append(values, defaultValue(arg))
+			}
+			return strings.Join(values, ", ")
+		}
+	}
+	if lit, ok := resolved.(*ast.CompositeLit); ok {
+		return strings.Join(stringList(lit), ", ")
+	}
+	return defaultValue(expr)
+}
@@ -0,0 +1,329 @@
+// Package config loads the .godocmd.yaml file that checks a repository's
+// documentation settings in alongside its code.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thinktide/godocmd"
+	"github.com/thinktide/godocmd/format"
+)
+
+// FileName is the name of the configuration file searched for by Find.
+const FileName = ".godocmd.yaml"
+
+// File is the content of a configuration file. Unset fields leave the
+// corresponding setting at its default; relative paths are resolved against Dir.
+type File struct {
+	// Dir is the directory containing the file, set by Load.
+	Dir string `yaml:"-"`
+
+	// Root is the directory to scan when --dir is not given, e.g. ".".
+	Root string `yaml:"root"`
+
+	Recursive           *bool    `yaml:"recursive"`
+	IncludePrivate      *bool    `yaml:"include_private"`
+	IncludeUndocumented *bool    `yaml:"include_undocumented"`
+	HideDeprecated      *bool    `yaml:"hide_deprecated"`
+	VerifyExamples      *bool    `yaml:"verify_examples"`
+	NoteMarkers         []string `yaml:"note_markers"`
+	TemplateDir         string   `yaml:"template_dir"`
+
+	// Include and Exclude select package directories relative to Dir (see discover.Match).
+	Include         []string `yaml:"include"`
+	Exclude         []string `yaml:"exclude"`
+	ExcludeInternal *bool    `yaml:"exclude_internal"`
+	DefaultExcludes *bool    `yaml:"default_excludes"` // false scans discover.DefaultExclude directories
+	Gitignore       *bool    `yaml:"gitignore"`        // false scans directories and Go files ignored by .gitignore
+
+	// AllPackages, SkipMain and ExternalTests select the packages of directories
+	// holding several.
+	AllPackages   *bool `yaml:"all_packages"`
+	SkipMain      *bool `yaml:"skip_main"`
+	ExternalTests *bool `yaml:"external_tests"`
+
+	// GOOS, GOARCH and BuildTags select files by their build constraints;
+	// Platforms lists further "goos/goarch" platforms to document.
+	GOOS      string   `yaml:"goos"`
+	GOARCH    string   `yaml:"goarch"`
+	BuildTags []string `yaml:"build_tags"`
+	Platforms []string `yaml:"platforms"`
+
+	// Module restricts generation to the module with this path.
+	Module string `yaml:"module"`
+
+	// Tags replaces the default struct tag blocks (json and dynamodbav).
+	Tags []Tag `yaml:"tags"`
+
+	// Outputs lists the documents generate and check produce.
+	Outputs []Output `yaml:"outputs"`
+
+	// Packages overrides visibility settings per package pattern relative to Dir.
+	// When several patterns match a package, the most specific one wins
+	// regardless of the order in the file: exact paths over "..." and glob
+	// patterns, then patterns with more literal segments, e.g. "internal/legacy"
+	// over "./internal/...".
+	Packages map[string]Package `yaml:"packages"`
+}
+
+// Tag configures a struct tag block (see format.TagRenderer).
+type Tag struct {
+	Name  string `yaml:"name"`  // the struct tag key, e.g. "bson"
+	Title string `yaml:"title"` // the block heading, defaults to Name
+	Style string `yaml:"style"` // "json", "dynamodb" or "list"
+}
+
+// Output is one document produced by a run. Exactly one of Out, OutDir and
+// Inject is expected to be set; Out may be empty to write to stdout.
+type Output struct {
+	Format string `yaml:"format"` // "markdown" (the default), "html" or "json"
+	Out    string `yaml:"out"`
+	OutDir string `yaml:"out_dir"`
+	Inject string `yaml:"inject"`
+}
+
+// Package overrides the visibility settings of matching packages.
+type Package struct {
+	IncludePrivate      *bool    `yaml:"include_private"`
+	IncludeUndocumented *bool    `yaml:"include_undocumented"`
+	HideDeprecated      *bool    `yaml:"hide_deprecated"`
+	NoteMarkers         []string `yaml:"note_markers"`
+}
+
+// Find looks for a configuration file in dir and each of its parents.
+//
+// Parameters:
+//   - dir: The directory to start from, typically the directory being documented
+//
+// Returns:
+//   - string: The path of the nearest configuration file
+//   - bool: False if no configuration file was found
+func Find(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(abs, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// Load reads and validates a configuration file. Unknown keys are rejected so
+// that typos do not silently fall back to defaults.
+//
+// Parameters:
+//   - path: The path of the configuration file
+//
+// Returns:
+//   - *File: The configuration, with relative paths resolved against its directory
+//   - error: Any error encountered reading or parsing the file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f.Dir = filepath.Dir(abs)
+	f.resolve()
+	return f, nil
+}
+
+// parse decodes and validates configuration YAML.
+//
+// Parameters:
+//   - r: The YAML document
+//
+// Returns:
+//   - *File: The configuration, with paths as written
+//   - error: Any syntax error, unknown key or invalid value
+func parse(r io.Reader) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	for i, o := range f.Outputs {
+		switch o.Format {
+		case "", "markdown", "json":
+		case "html":
+			if o.Out == "" {
+				return nil, fmt.Errorf("outputs[%d]: out is required for format html", i)
+			}
+		default:
+			return nil, fmt.Errorf("outputs[%d]: unknown format %q", i, o.Format)
+		}
+		set := 0
+		for _, v := range []string{o.Out, o.OutDir, o.Inject} {
+			if v != "" {
+				set++
+			}
+		}
+		if set > 1 {
+			return nil, fmt.Errorf("outputs[%d]: only one of out, out_dir and inject may be set", i)
+		}
+		if (o.OutDir != "" || o.Inject != "") && o.Format != "" && o.Format != "markdown" {
+			return nil, fmt.Errorf("outputs[%d]: out_dir and inject require format markdown", i)
+		}
+	}
+	for i, t := range f.Tags {
+		if t.Name == "" {
+			return nil, fmt.Errorf("tags[%d]: name is required", i)
+		}
+		switch t.Style {
+		case "", format.TagStyleJSON, format.TagStyleDynamoDB, format.TagStyleList:
+		default:
+			return nil, fmt.Errorf("tags[%d]: unknown style %q", i, t.Style)
+		}
+	}
+	return &f, nil
+}
+
+// resolve makes the file's root, output and template paths absolute.
+func (f *File) resolve() {
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(f.Dir, path)
+	}
+	f.Root = abs(f.Root)
+	f.TemplateDir = abs(f.TemplateDir)
+	for i := range f.Outputs {
+		f.Outputs[i].Out = abs(f.Outputs[i].Out)
+		f.Outputs[i].OutDir = abs(f.Outputs[i].OutDir)
+		f.Outputs[i].Inject = abs(f.Outputs[i].Inject)
+	}
+}
+
+// Options converts the configuration into generation options. Settings the
+// file leaves unset keep their zero value.
+//
+// Returns:
+//   - godocmd.Options: The options described by the file
+func (f *File) Options() godocmd.Options {
+	value := func(b *bool) bool { return b != nil && *b }
+	opts := godocmd.Options{
+		Recursive:           value(f.Recursive),
+		IncludePrivate:      value(f.IncludePrivate),
+		IncludeUndocumented: value(f.IncludeUndocumented),
+		HideDeprecated:      value(f.HideDeprecated),
+		VerifyExamples:      value(f.VerifyExamples),
+		NoteMarkers:         f.NoteMarkers,
+		TemplateDir:         f.TemplateDir,
+		Include:             f.Include,
+		Exclude:             f.Exclude,
+		ExcludeInternal:     value(f.ExcludeInternal),
+		NoDefaultExcludes:   f.DefaultExcludes != nil && !*f.DefaultExcludes,
+		NoGitignore:         f.Gitignore != nil && !*f.Gitignore,
+		AllPackages:         value(f.AllPackages),
+		SkipMain:            value(f.SkipMain),
+		ExternalTests:       value(f.ExternalTests),
+		GOOS:                f.GOOS,
+		GOARCH:              f.GOARCH,
+		BuildTags:           f.BuildTags,
+		Platforms:           f.Platforms,
+		Module:              f.Module,
+		PatternDir:          f.Dir,
+	}
+
+	if f.Tags != nil {
+		opts.TagRenderers = []format.TagRenderer{}
+		for _, t := range f.Tags {
+			opts.TagRenderers = append(opts.TagRenderers, format.TagRenderer{Tag: t.Name, Title: t.Title, Style: t.Style})
+		}
+	}
+
+	// Apply overrides from the least to the most specific pattern (see
+	// specificity), so the most specific matching pattern wins.
+	patterns := make([]string, 0, len(f.Packages))
+	for pattern := range f.Packages {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		exactI, literalsI := specificity(patterns[i])
+		exactJ, literalsJ := specificity(patterns[j])
+		if exactI != exactJ {
+			return exactJ
+		}
+		if literalsI != literalsJ {
+			return literalsI < literalsJ
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		p := f.Packages[pattern]
+		opts.Packages = append(opts.Packages, godocmd.PackageOptions{
+			Pattern:             pattern,
+			IncludePrivate:      p.IncludePrivate,
+			IncludeUndocumented: p.IncludeUndocumented,
+			HideDeprecated:      p.HideDeprecated,
+			NoteMarkers:         p.NoteMarkers,
+		})
+	}
+	return opts
+}
+
+// specificity ranks a package pattern. Exact paths such as "api/v1" are more
+// specific than "..." and glob patterns; otherwise patterns with more literal
+// path segments are more specific, so "internal/legacy/..." outranks
+// "./internal/..." and "**/internal".
+//
+// Parameters:
+//   - pattern: The package pattern (see discover.Match)
+//
+// Returns:
+//   - bool: True if the pattern is an exact path
+//   - int: The number of path segments without wildcards
+func specificity(pattern string) (bool, int) {
+	pattern = strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "./")
+	exact, literals := true, 0
+	for _, segment := range strings.Split(pattern, "/") {
+		switch {
+		case segment == ".":
+		case strings.Contains(segment, "...") || strings.ContainsAny(segment, "*?[{"):
+			exact = false
+		default:
+			literals++
+		}
+	}
+	return exact, literals
+}
+
+// Targets converts the configured outputs into godocmd outputs.
+//
+// Returns:
+//   - []godocmd.Output: The outputs in file order
+func (f *File) Targets() []godocmd.Output {
+	var out []godocmd.Output
+	for _, o := range f.Outputs {
+		out = append(out, godocmd.Output{Format: o.Format, File: o.Out, Dir: o.OutDir, Inject: o.Inject})
+	}
+	return out
+}
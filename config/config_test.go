@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd"
+)
+
+const sample = `
+root: .
+recursive: true
+hide_deprecated: true
+note_markers: [BUG, TODO]
+template_dir: doctmpl
+exclude: [examples, "gen/*"]
+exclude_internal: true
+gitignore: false
+module: example.com/monorepo/api
+goos: linux
+skip_main: true
+build_tags: [integration]
+platforms: [windows/amd64]
+tags:
+  - name: json
+    title: JSON
+    style: json
+  - name: bson
+    title: MongoDB
+outputs:
+  - out_dir: docs
+  - format: json
+    out: docs/api.json
+packages:
+  internal:
+    include_undocumented: true
+  internal/legacy:
+    hide_deprecated: false
+`
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "pkg", "models")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok := Find(nested)
+	if !ok || path != filepath.Join(root, FileName) {
+		t.Fatalf("Find(%q) = %q, %v", nested, path, ok)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Dir != root || f.Root != root || f.TemplateDir != filepath.Join(root, "doctmpl") {
+		t.Errorf("paths not resolved: dir %q, template dir %q", f.Dir, f.TemplateDir)
+	}
+
+	targets := f.Targets()
+	if len(targets) != 2 || targets[0].Dir != filepath.Join(root, "docs") ||
+		targets[1].Format != "json" || targets[1].File != filepath.Join(root, "docs", "api.json") {
+		t.Errorf("unexpected targets %+v", targets)
+	}
+
+	opts := f.Options()
+	if !opts.Recursive || !opts.HideDeprecated || opts.IncludePrivate || opts.PatternDir != root ||
+		!opts.ExcludeInternal || !opts.NoGitignore || opts.NoDefaultExcludes || opts.Module != "example.com/monorepo/api" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if strings.Join(opts.NoteMarkers, ",") != "BUG,TODO" || strings.Join(opts.Exclude, ",") != "examples,gen/*" {
+		t.Errorf("unexpected lists %v %v", opts.NoteMarkers, opts.Exclude)
+	}
+	if !opts.SkipMain || opts.AllPackages || opts.ExternalTests {
+		t.Errorf("unexpected package selection %+v", opts)
+	}
+	if opts.GOOS != "linux" || opts.GOARCH != "" || strings.Join(opts.BuildTags, ",") != "integration" ||
+		strings.Join(opts.Platforms, ",") != "windows/amd64" {
+		t.Errorf("unexpected build options %q %q %v %v", opts.GOOS, opts.GOARCH, opts.BuildTags, opts.Platforms)
+	}
+	if len(opts.TagRenderers) != 2 || opts.TagRenderers[1].Tag != "bson" || opts.TagRenderers[1].Title != "MongoDB" {
+		t.Errorf("unexpected tag renderers %+v", opts.TagRenderers)
+	}
+	if len(opts.Packages) != 2 || opts.Packages[0].Pattern != "internal" || opts.Packages[1].Pattern != "internal/legacy" {
+		t.Fatalf("unexpected package overrides %+v", opts.Packages)
+	}
+	if p := opts.Packages[1]; p.HideDeprecated == nil || *p.HideDeprecated || p.IncludeUndocumented != nil {
+		t.Errorf("unexpected override %+v", p)
+	}
+}
+
+func TestFind_None(t *testing.T) {
+	if path, ok := Find(t.TempDir()); ok {
+		t.Errorf("expected no config file, found %q", path)
+	}
+}
+
+func TestParse_EmptyTags(t *testing.T) {
+	f, err := parse(strings.NewReader("tags: []\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := f.Options(); opts.TagRenderers == nil || len(opts.TagRenderers) != 0 {
+		t.Errorf("expected an empty, non-nil tag renderer list, got %#v", opts.TagRenderers)
+	}
+
+	f, err = parse(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := f.Options(); opts.TagRenderers != nil {
+		t.Errorf("expected default tag renderers, got %#v", opts.TagRenderers)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"recursve: true\n":                                 "field recursve not found",
+		"outputs:\n  - format: pdf\n":                      `unknown format "pdf"`,
+		"outputs:\n  - format: html\n":                     "out is required for format html",
+		"outputs:\n  - out: a.md\n    inject: README.md\n": "only one of out, out_dir and inject",
+		"outputs:\n  - format: json\n    out_dir: docs\n":  "require format markdown",
+		"tags:\n  - title: X\n":                            "name is required",
+		"tags:\n  - name: xml\n    style: table\n":         `unknown style "table"`,
+	}
+	for input, want := range tests {
+		_, err := parse(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parse(%q) = %v, want error containing %q", input, err, want)
+		}
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	// Each pattern is less specific than the next.
+	patterns := []string{"./...", "./api/...", "api/*/legacy", "api/v1/legacy/...", "api"}
+	for i := 1; i < len(patterns); i++ {
+		exactA, literalsA := specificity(patterns[i-1])
+		exactB, literalsB := specificity(patterns[i])
+		if exactA && !exactB || exactA == exactB && literalsA >= literalsB {
+			t.Errorf("expected %q to be less specific than %q", patterns[i-1], patterns[i])
+		}
+	}
+}
+
+func TestOptions_OverlappingPackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/overlap\n\ngo 1.21\n",
+		// The exact path is shorter than the wildcard and listed first; it still wins.
+		FileName:       "recursive: true\npackages:\n  api/v1:\n    hide_deprecated: false\n  ./api/...:\n    hide_deprecated: true\n",
+		"api/api.go":   "// Package api is current.\npackage api\n\n// OldAPI is old.\n//\n// Deprecated: use NewAPI.\nfunc OldAPI() {}\n",
+		"api/v1/v1.go": "// Package v1 is old.\npackage v1\n\n// OldV1 is old.\n//\n// Deprecated: use NewV1.\nfunc OldV1() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := Load(filepath.Join(root, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := f.Options()
+	if len(opts.Packages) != 2 || opts.Packages[0].Pattern != "./api/..." || opts.Packages[1].Pattern != "api/v1" {
+		t.Fatalf("expected overrides from the least to the most specific pattern, got %+v", opts.Packages)
+	}
+
+	var out strings.Builder
+	if err := godocmd.GenerateMarkdownWithOptions(root, &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "OldAPI") || !strings.Contains(out.String(), "OldV1") {
+		t.Errorf("expected api/v1 to override ./api/..., got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,182 @@
+// Package coverage measures how much of a package's exported API is documented.
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// Symbol identifies an exported symbol that lacks documentation.
+type Symbol struct {
+	Kind string // "func", "method", "type", "field" or "const"
+	Name string // the qualified name, e.g. "User.Save" or "User.Name"
+	Pos  model.Position
+}
+
+// Package holds the documentation coverage of a single package.
+type Package struct {
+	ImportPath   string
+	Total        int
+	Documented   int
+	Undocumented []Symbol
+}
+
+// Percent returns the share of documented symbols as a percentage. A package
+// without exported symbols is fully covered.
+//
+// Returns:
+//   - float64: The coverage between 0 and 100
+func (p Package) Percent() float64 {
+	return percent(p.Documented, p.Total)
+}
+
+// Report holds the documentation coverage of a set of packages.
+type Report struct {
+	Packages   []Package
+	Total      int
+	Documented int
+}
+
+// Percent returns the share of documented symbols across all packages as a percentage.
+//
+// Returns:
+//   - float64: The coverage between 0 and 100
+func (r Report) Percent() float64 {
+	return percent(r.Documented, r.Total)
+}
+
+// Measure computes the documentation coverage of package models. The models are
+// expected to include undocumented symbols (model.Options.IncludeUndocumented);
+// unexported symbols are ignored.
+//
+// Parameters:
+//   - pkgs: The package models to measure
+//
+// Returns:
+//   - Report: The per-package and total coverage
+func Measure(pkgs []*model.Package) Report {
+	var r Report
+	for _, pkg := range pkgs {
+		p := measurePackage(pkg)
+		r.Packages = append(r.Packages, p)
+		r.Total += p.Total
+		r.Documented += p.Documented
+	}
+	return r
+}
+
+// measurePackage computes the documentation coverage of a single package model.
+//
+// Parameters:
+//   - pkg: The package model
+//
+// Returns:
+//   - Package: The package's coverage
+func measurePackage(pkg *model.Package) Package {
+	p := Package{ImportPath: pkg.ImportPath}
+	if p.ImportPath == "" {
+		p.ImportPath = pkg.Name
+	}
+
+	count := func(kind, name string, documented bool, pos model.Position) {
+		if !model.IsExported(name[strings.LastIndex(name, ".")+1:]) {
+			return
+		}
+		p.Total++
+		if documented {
+			p.Documented++
+			return
+		}
+		p.Undocumented = append(p.Undocumented, Symbol{Kind: kind, Name: name, Pos: pos})
+	}
+
+	countValues := func(values []model.Value) {
+		for _, v := range values {
+			if v.Kind != "const" {
+				continue
+			}
+			for _, s := range v.Specs {
+				documented := v.Doc != "" || v.Deprecated || s.Doc != "" || s.Comment != ""
+				for _, name := range s.Names {
+					count("const", name, documented, s.Pos)
+				}
+			}
+		}
+	}
+
+	countValues(pkg.Consts)
+	for _, f := range pkg.Funcs {
+		count("func", f.Name, documentedFunc(f), f.Pos)
+	}
+	for _, t := range pkg.Types {
+		if !model.IsExported(t.Name) {
+			continue
+		}
+		count("type", t.Name, t.Doc != "" || t.Deprecated, t.Pos)
+		for _, f := range t.Fields {
+			if f.Embedded {
+				continue
+			}
+			kind := "field"
+			if t.Kind == "interface" {
+				kind = "method"
+			}
+			count(kind, t.Name+"."+f.Name, f.Doc != "" || f.Comment != "", f.Pos)
+		}
+		countValues(t.Consts)
+		for _, f := range t.Funcs {
+			count("func", f.Name, documentedFunc(f), f.Pos)
+		}
+		for _, m := range t.Methods {
+			count("method", t.Name+"."+m.Name, documentedFunc(m), m.Pos)
+		}
+	}
+	return p
+}
+
+// documentedFunc reports whether a function or method has a doc comment.
+//
+// Parameters:
+//   - f: The function model
+//
+// Returns:
+//   - bool: True if the function is documented
+func documentedFunc(f model.Func) bool {
+	return f.Doc != "" || f.Deprecated
+}
+
+// percent returns documented as a percentage of total.
+//
+// Parameters:
+//   - documented: The number of documented symbols
+//   - total: The number of symbols
+//
+// Returns:
+//   - float64: The percentage, or 100 when total is zero
+func percent(documented, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(documented) * 100 / float64(total)
+}
+
+// WriteText writes a coverage report as plain text: one line per package with
+// its percentage followed by its undocumented symbols as "file:line: kind name",
+// then the total.
+//
+// Parameters:
+//   - r: The coverage report
+//   - out: The writer to output the report to
+func WriteText(r Report, out io.Writer) {
+	for _, p := range r.Packages {
+		fmt.Fprintf(out, "%-50s %6.1f%% (%d/%d)\n", p.ImportPath, p.Percent(), p.Documented, p.Total)
+		for _, s := range p.Undocumented {
+			fmt.Fprintf(out, "    %s:%d: %s %s\n", filepath.ToSlash(s.Pos.File), s.Pos.Line, s.Kind, s.Name)
+		}
+	}
+	fmt.Fprintf(out, "%-50s %6.1f%% (%d/%d)\n", "total", r.Percent(), r.Documented, r.Total)
+}
@@ -0,0 +1,69 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func TestMeasure(t *testing.T) {
+	pkg := &model.Package{
+		Name:       "models",
+		ImportPath: "example.com/app/models",
+		Consts: []model.Value{{
+			Kind: "const",
+			Specs: []model.ValueSpec{
+				{Names: []string{"MaxUsers"}, Comment: "upper bound", Pos: model.Position{File: "models/user.go", Line: 4}},
+				{Names: []string{"MinUsers", "minAge"}, Pos: model.Position{File: "models/user.go", Line: 5}},
+			},
+		}},
+		Funcs: []model.Func{
+			{Name: "Open", Doc: "Open opens the store."},
+			{Name: "Close", Pos: model.Position{File: "models/store.go", Line: 12}},
+		},
+		Types: []model.Type{{
+			Name: "User",
+			Kind: "struct",
+			Doc:  "User is an account.",
+			Fields: []model.Field{
+				{Name: "Name", Doc: "Name is the display name."},
+				{Name: "Email", Pos: model.Position{File: "models/user.go", Line: 9}},
+				{Name: "Base", Embedded: true},
+			},
+			Funcs:   []model.Func{{Name: "NewUser", Deprecated: true}},
+			Methods: []model.Func{{Name: "Save", Recv: "User", Pos: model.Position{File: "models/user.go", Line: 20}}},
+		}},
+	}
+
+	r := Measure([]*model.Package{pkg})
+	// MaxUsers, MinUsers, Open, Close, User, Name, Email, NewUser, Save
+	if r.Total != 9 || r.Documented != 5 {
+		t.Fatalf("expected 5/9 documented, got %d/%d", r.Documented, r.Total)
+	}
+
+	var names []string
+	for _, s := range r.Packages[0].Undocumented {
+		names = append(names, s.Kind+" "+s.Name)
+	}
+	want := "const MinUsers, func Close, field User.Email, method User.Save"
+	if got := strings.Join(names, ", "); got != want {
+		t.Errorf("expected undocumented %q, got %q", want, got)
+	}
+
+	var buf bytes.Buffer
+	WriteText(r, &buf)
+	out := buf.String()
+	for _, s := range []string{"example.com/app/models", "55.6% (5/9)", "models/user.go:9: field User.Email", "total"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected report to contain %q\n%s", s, out)
+		}
+	}
+}
+
+func TestPercent_Empty(t *testing.T) {
+	if p := Measure(nil).Percent(); p != 100 {
+		t.Errorf("expected an empty report to be fully covered, got %.1f", p)
+	}
+}
@@ -0,0 +1,236 @@
+// Package discover finds the Go package directories below a root directory,
+// applying include and exclude patterns, default exclusions and .gitignore files.
+package discover
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// DefaultExclude lists the directories skipped unless Options.NoDefaultExcludes
+// is set, matched against paths relative to the scanned root. Besides the
+// directories the go command ignores, it skips a top-level docs directory and
+// Terraform configuration.
+var DefaultExclude = []string{
+	"**/vendor",
+	"**/testdata",
+	"**/.*",
+	"**/_*",
+	"docs",
+	"terraform*",
+}
+
+// InternalExclude is the pattern added to the exclusions by Options.ExcludeInternal.
+const InternalExclude = "**/internal"
+
+// Options controls which directories Dirs returns.
+type Options struct {
+	// Include restricts the result to directories matching at least one pattern
+	// (see Match). All package directories are included when it is empty.
+	Include []string
+
+	// Exclude skips directories matching any pattern, along with everything below them.
+	Exclude []string
+
+	// ExcludeInternal skips internal packages and everything below them.
+	ExcludeInternal bool
+
+	// NoDefaultExcludes disables DefaultExclude.
+	NoDefaultExcludes bool
+
+	// NoGitignore disables skipping directories and Go files ignored by .gitignore files.
+	NoGitignore bool
+
+	// PatternDir is the directory Include and Exclude patterns are relative to.
+	// Defaults to the scanned root directory.
+	PatternDir string
+}
+
+// Match reports whether a pattern matches a directory. Patterns are either Go
+// package patterns ending in "...", such as "./..." or "./api/...", which match
+// a directory and everything below it, or doublestar globs such as "cmd/*" or
+// "**/generated".
+//
+// Parameters:
+//   - pattern: The pattern, relative to the pattern directory
+//   - rel: The slash-separated directory relative to the pattern directory, "." for itself
+//
+// Returns:
+//   - bool: True if the pattern matches
+func Match(pattern, rel string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if pattern != "." && pattern != "./" {
+		pattern = strings.TrimPrefix(pattern, "./")
+	}
+
+	if strings.HasSuffix(pattern, "...") {
+		prefix := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if prefix == "" || prefix == "." {
+			return true
+		}
+		if !strings.Contains(prefix, "...") && !strings.ContainsAny(prefix, "*?[{") {
+			return rel == prefix || strings.HasPrefix(rel, prefix+"/")
+		}
+		// A wildcard prefix such as "api/*/..." matches like "api/*" and "api/*/**".
+		return Match(prefix, rel) || Match(prefix+"/**", rel)
+	}
+
+	ok, err := doublestar.Match(path.Clean(pattern), rel)
+	return err == nil && ok
+}
+
+// MatchAny reports whether any pattern matches a directory.
+//
+// Parameters:
+//   - patterns: The patterns
+//   - rel: The slash-separated relative directory
+//
+// Returns:
+//   - bool: True if at least one pattern matches
+func MatchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if Match(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dirs walks root and returns every directory containing non-test Go files,
+// in the order their first Go file is visited by filepath.WalkDir, as paths
+// joined to root. Excluded and ignored directories
+// are not descended into; root itself is never excluded. Go files ignored by
+// .gitignore do not make their directory a package directory, but the loader
+// still reads every Go file of the directories returned.
+//
+// Parameters:
+//   - root: The directory to scan
+//   - opts: The patterns and exclusions to apply
+//
+// Returns:
+//   - []string: The package directories
+//   - error: Any error encountered walking the tree or resolving paths
+func Dirs(root string, opts Options) ([]string, error) {
+	patternDir := opts.PatternDir
+	if patternDir == "" {
+		patternDir = root
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	absPatternDir, err := filepath.Abs(patternDir)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := opts.Exclude
+	if opts.ExcludeInternal {
+		exclude = append(append([]string(nil), exclude...), InternalExclude)
+	}
+	var ignore *gitignore
+	if !opts.NoGitignore {
+		if ignore, err = loadGitignore(absRoot); err != nil {
+			return nil, err
+		}
+	}
+
+	var dirs []string
+	seen := map[string]bool{}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rootRel, err := relPath(root, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rootRel == "." {
+				return nil
+			}
+			if !opts.NoDefaultExcludes && MatchAny(DefaultExclude, rootRel) {
+				return filepath.SkipDir
+			}
+			if rel, ok := relTo(absPatternDir, filepath.Join(absRoot, rootRel)); ok && MatchAny(exclude, rel) {
+				return filepath.SkipDir
+			}
+			if ignore != nil {
+				if ignore.ignored(p, true) {
+					return filepath.SkipDir
+				}
+				return ignore.enter(p)
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return nil
+		}
+		if ignore != nil && ignore.ignored(p, false) {
+			return nil
+		}
+		dir := filepath.Dir(p)
+		if seen[dir] {
+			return nil
+		}
+		seen[dir] = true
+
+		if len(opts.Include) > 0 {
+			rel, ok := relTo(absPatternDir, filepath.Join(absRoot, path.Dir(rootRel)))
+			if !ok || !MatchAny(opts.Include, rel) {
+				return nil
+			}
+		}
+		dirs = append(dirs, dir)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// relPath returns the slash-separated path of p relative to root.
+//
+// Parameters:
+//   - root: The walked root, as given to filepath.WalkDir
+//   - p: A path below root
+//
+// Returns:
+//   - string: The relative path, "." for root itself
+//   - error: An error if p cannot be made relative to root
+func relPath(root, p string) (string, error) {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// relTo returns the slash-separated path of dir relative to base.
+//
+// Parameters:
+//   - base: The absolute base directory
+//   - dir: An absolute directory
+//
+// Returns:
+//   - string: The relative path, "." for base itself
+//   - bool: False if dir is not inside base
+func relTo(base, dir string) (string, bool) {
+	rel, err := filepath.Rel(base, dir)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
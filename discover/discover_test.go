@@ -0,0 +1,170 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"./...", ".", true},
+		{"./...", "api/v1", true},
+		{"...", "api", true},
+		{"./api/...", "api", true},
+		{"./api/...", "api/v1", true},
+		{"./api/...", "apis", false},
+		{"api/*/...", "api/v1/types", true},
+		{"api/*/...", "api", false},
+		{"cmd/*", "cmd/tool", true},
+		{"cmd/*", "cmd/tool/sub", false},
+		{"**/generated", "a/b/generated", true},
+		{"**/generated", "generated", true},
+		{"./examples", "examples", true},
+		{"examples/", "examples", true},
+		{".", ".", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+// writeTree creates the given files, with Go files declaring a package.
+func writeTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := ""
+		if strings.HasSuffix(f, ".go") {
+			content = "package " + filepath.Base(filepath.Dir(path)) + "\n"
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// relDirs returns dirs relative to root, slash-separated.
+func relDirs(t *testing.T, root string, dirs []string) string {
+	t.Helper()
+	var rels []string
+	for _, d := range dirs {
+		rel, err := filepath.Rel(root, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	return strings.Join(rels, " ")
+}
+
+func TestDirs(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root,
+		"root.go",
+		"api/api.go",
+		"api/v1/types.go",
+		"api/internal/auth/auth.go",
+		"cmd/tool/main.go",
+		"examples/hello/main.go",
+		"onlytests/x_test.go",
+		"vendor/dep/dep.go",
+		"api/testdata/fixture.go",
+		".hidden/h.go",
+		"_scratch/s.go",
+		"docs/gen.go",
+		"terraform-modules/tf.go",
+		"gen/out/out.go",
+		"gen/keep/keep.go",
+		".gitignore",
+		"gen/.gitignore",
+	)
+	// A .git directory marks root as the repository root.
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("# generated\n/gen/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"defaults", Options{}, "api api/internal/auth api/v1 cmd/tool examples/hello ."},
+		{"exclude", Options{Exclude: []string{"./examples/...", "cmd"}}, "api api/internal/auth api/v1 ."},
+		{"include", Options{Include: []string{"./api/..."}}, "api api/internal/auth api/v1"},
+		{"include glob", Options{Include: []string{"api/*", "cmd/*"}}, "api/v1 cmd/tool"},
+		{"exclude internal", Options{ExcludeInternal: true}, "api api/v1 cmd/tool examples/hello ."},
+		{
+			"no default excludes",
+			Options{NoDefaultExcludes: true, Include: []string{"vendor/...", "docs", "api/testdata", "_scratch"}},
+			"_scratch api/testdata docs vendor/dep",
+		},
+		{"no gitignore", Options{NoGitignore: true, Include: []string{"gen/..."}}, "gen/keep gen/out"},
+		{"pattern dir", Options{PatternDir: filepath.Dir(root), Include: []string{"*/api"}}, "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, err := Dirs(root, tt.opts)
+			if err != nil {
+				t.Fatalf("Dirs: %v", err)
+			}
+			if got := relDirs(t, root, dirs); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirs_NestedGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "a/gen/x.go", "a/keep/x.go", "a/mocks/x.go", "b/gen/x.go")
+	if err := os.WriteFile(filepath.Join(root, "a", ".gitignore"), []byte("gen\nmocks/\n!keep\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs, err := Dirs(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := relDirs(t, root, dirs), "a/keep b/gen"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDirs_IgnoredFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "pb/api.pb.go", "mixed/api.pb.go", "mixed/mixed.go", "gen/x.go", "gen.go/x.go")
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.pb.go\ngen.go/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory whose Go files are all ignored is not a package; a
+	// directory-only pattern does not ignore files.
+	dirs, err := Dirs(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := relDirs(t, root, dirs), "gen mixed"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	dirs, err = Dirs(root, Options{NoGitignore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := relDirs(t, root, dirs), "gen gen.go mixed pb"; got != want {
+		t.Errorf("with NoGitignore: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package discover
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreRule is one pattern line of a .gitignore file.
+type ignoreRule struct {
+	dir     string // the absolute, slash-separated directory of the .gitignore file
+	pattern string // a doublestar pattern relative to dir
+	negate  bool   // whether the line re-includes matching paths ("!pattern")
+	dirOnly bool   // whether the pattern only matches directories ("pattern/")
+}
+
+// gitignore holds the rules of the .gitignore files that apply to a walk: those
+// from the repository root down to the scanned root, and those found below it.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// loadGitignore reads the .gitignore files of root and its parents up to the
+// root of the enclosing git repository. Without a repository, only root's own
+// file is read.
+//
+// Parameters:
+//   - root: The absolute directory being scanned
+//
+// Returns:
+//   - *gitignore: The rules found so far
+//   - error: Any error encountered reading a .gitignore file
+func loadGitignore(root string) (*gitignore, error) {
+	dirs := []string{root}
+	for dir := root; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Not inside a repository: only root's own file applies.
+			dirs = []string{root}
+			break
+		}
+		dir = parent
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	g := &gitignore{}
+	for _, dir := range dirs {
+		if err := g.read(dir); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// enter reads the .gitignore file of a directory the walk descends into.
+//
+// Parameters:
+//   - dir: The directory, as passed to the walk function
+//
+// Returns:
+//   - error: Any error encountered reading the file
+func (g *gitignore) enter(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	return g.read(abs)
+}
+
+// read appends the rules of dir's .gitignore file, if it has one.
+//
+// Parameters:
+//   - dir: The absolute directory
+//
+// Returns:
+//   - error: Any error other than the file not existing
+func (g *gitignore) read(dir string) error {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	slashDir := filepath.ToSlash(dir)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text()); ok {
+			rule.dir = slashDir
+			g.rules = append(g.rules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseIgnoreLine converts a .gitignore line into a rule. Patterns without a
+// slash match at any depth; a leading or inner slash anchors the pattern to
+// the file's directory, and a trailing slash restricts it to directories.
+//
+// Parameters:
+//   - line: The line
+//
+// Returns:
+//   - ignoreRule: The rule, without its directory
+//   - bool: False for blank lines and comments
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	if strings.Contains(line, "/") {
+		rule.pattern = strings.TrimPrefix(line, "/")
+	} else {
+		rule.pattern = "**/" + line
+	}
+	return rule, true
+}
+
+// ignored reports whether a directory or file is ignored. The last matching rule wins.
+//
+// Parameters:
+//   - p: The directory or file
+//   - isDir: Whether p is a directory, which directory-only rules require
+//
+// Returns:
+//   - bool: True if the path is ignored
+func (g *gitignore) ignored(p string, isDir bool) bool {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+	abs = filepath.ToSlash(abs)
+
+	ignored := false
+	for _, rule := range g.rules {
+		if !strings.HasPrefix(abs, rule.dir+"/") || (rule.dirOnly && !isDir) {
+			continue
+		}
+		rel := path.Clean(strings.TrimPrefix(abs, rule.dir+"/"))
+		if ok, err := doublestar.Match(rule.pattern, rel); err == nil && ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
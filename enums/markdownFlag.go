@@ -17,4 +17,12 @@ const (
 
 	// Verbose enables detailed logging of package parsing, filtering, and markdown rendering steps.
 	Verbose
+
+	// VerifyExamples runs each package's example tests in a temporary copy of its module and flags
+	// examples whose output does not match their `// Output:` comment.
+	VerifyExamples
+
+	// HideDeprecated omits functions, methods, types, fields and constants whose GoDoc contains a
+	// "Deprecated:" paragraph.
+	HideDeprecated
 )
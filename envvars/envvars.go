@@ -0,0 +1,406 @@
+// Package envvars collects the environment variables that configuration
+// structs are loaded from, as declared by the struct tags of
+// github.com/caarlos0/env (env, envDefault, envPrefix) and
+// github.com/kelseyhightower/envconfig (envconfig, default, required, desc,
+// split_words). As envconfig reads untagged fields too, the untagged fields of
+// a struct with an envconfig or split_words tag, and of the structs nested in
+// it, are named after the field. Names never include the prefix passed to
+// envconfig.Process.
+package envvars
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// Var describes an environment variable read into a struct field.
+type Var struct {
+	Name     string // the variable name, including any envPrefix of enclosing structs
+	Type     string // the Go type of the field
+	Default  string // the default value, empty if none
+	Required bool   // set for required and notEmpty variables
+	Doc      string // the field's doc comment, trailing comment or desc tag on one line
+	Package  string // the import path, or name, of the package declaring the root struct
+	Field    string // the field path from the root struct, e.g. "Config.DB.Host"
+	Pos      model.Position
+}
+
+// Collect returns the environment variables of every configuration struct in
+// the packages, sorted by name. Structs nested in another configuration
+// struct are only reported through it, with its prefix applied.
+//
+// Parameters:
+//   - pkgs: The package models, including unexported types and undocumented fields
+//
+// Returns:
+//   - []Var: The variables, in the order of their names
+func Collect(pkgs []*model.Package) []Var {
+	c := &collector{types: map[string]*model.Type{}, byName: map[string]*model.Package{}}
+	for _, pkg := range pkgs {
+		c.byName[pkg.Name] = pkg
+		for i := range pkg.Types {
+			t := &pkg.Types[i]
+			if t.Kind == "struct" {
+				c.types[typeKey(pkg, t.Name)] = t
+			}
+		}
+	}
+
+	// Walk every struct once to find those nested in other configuration structs.
+	nested := map[*model.Type]bool{}
+	for _, pkg := range pkgs {
+		for i := range pkg.Types {
+			t := &pkg.Types[i]
+			if t.Kind != "struct" {
+				continue
+			}
+			c.nested = nil
+			if len(c.structVars(pkg, t, "", t.Name, false, map[*model.Type]bool{})) == 0 {
+				continue
+			}
+			for _, n := range c.nested {
+				nested[n] = true
+			}
+		}
+	}
+
+	var vars []Var
+	for _, pkg := range pkgs {
+		for i := range pkg.Types {
+			t := &pkg.Types[i]
+			if t.Kind != "struct" || nested[t] {
+				continue
+			}
+			vars = append(vars, c.structVars(pkg, t, "", t.Name, false, map[*model.Type]bool{})...)
+		}
+	}
+	sort.SliceStable(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// collector resolves the struct types fields refer to across packages.
+type collector struct {
+	// types holds the struct types by package key and name.
+	types map[string]*model.Type
+
+	// byName holds the packages by package name, for qualified field types.
+	byName map[string]*model.Package
+
+	// nested collects the structs entered while walking a root struct.
+	nested []*model.Type
+}
+
+// structVars returns the environment variables of a struct's fields,
+// descending into fields of struct types declared in the scanned packages.
+//
+// Parameters:
+//   - pkg: The package declaring the struct
+//   - t: The struct type
+//   - prefix: The prefix of the enclosing structs
+//   - path: The field path down to the struct
+//   - envconfig: Whether an enclosing struct is read by envconfig
+//   - visiting: The structs being walked, guarding against cycles
+//
+// Returns:
+//   - []Var: The variables, in field order
+func (c *collector) structVars(pkg *model.Package, t *model.Type, prefix, path string, envconfig bool, visiting map[*model.Type]bool) []Var {
+	visiting[t] = true
+	defer delete(visiting, t)
+	envconfig = envconfig || usesEnvconfig(t)
+
+	var vars []Var
+	for _, f := range t.Fields {
+		if !model.IsExported(f.Name) || f.Tags["env"] == "-" || f.Tags["envconfig"] == "-" || f.Tags["ignored"] == "true" {
+			continue
+		}
+		fieldPath := path + "." + f.Name
+
+		npkg, nt := c.lookup(pkg, f.Type)
+		if _, ok := f.Tags["env"]; ok || nt == nil {
+			if v, ok := fieldVar(f, prefix, envconfig); ok {
+				v.Package = pkgKey(pkg)
+				v.Field = fieldPath
+				vars = append(vars, v)
+			}
+			continue
+		}
+		if visiting[nt] {
+			continue
+		}
+		nestedPrefix := prefix + f.Tags["envPrefix"]
+		if name := f.Tags["envconfig"]; name != "" {
+			nestedPrefix = prefix + strings.ToUpper(name) + "_"
+		} else if envconfig && !f.Embedded {
+			nestedPrefix = prefix + strings.ToUpper(envconfigName(f)) + "_"
+		}
+		if f.Embedded {
+			fieldPath = path
+		}
+		if sub := c.structVars(npkg, nt, nestedPrefix, fieldPath, envconfig, visiting); len(sub) > 0 {
+			for i := range sub {
+				sub[i].Package = pkgKey(pkg)
+			}
+			c.nested = append(c.nested, nt)
+			vars = append(vars, sub...)
+		}
+	}
+	return vars
+}
+
+// fieldVar returns the variable a field is read from, if its tags name one or
+// its struct is read by envconfig.
+//
+// Parameters:
+//   - f: The struct field
+//   - prefix: The prefix of the enclosing structs
+//   - envconfig: Whether the field's struct is read by envconfig
+//
+// Returns:
+//   - Var: The variable, without Package and Field
+//   - bool: False if the field has no env or envconfig tag outside an envconfig struct
+func fieldVar(f model.Field, prefix string, envconfig bool) (Var, bool) {
+	v := Var{Type: f.Type, Doc: f.Doc, Pos: f.Pos}
+	if v.Doc == "" {
+		v.Doc = f.Comment
+	}
+	if v.Doc == "" {
+		v.Doc = f.Tags["desc"]
+	}
+	v.Doc = strings.Join(strings.Fields(v.Doc), " ")
+
+	if tag, ok := f.Tags["env"]; ok {
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return Var{}, false
+		}
+		v.Name = prefix + name
+		v.Default = f.Tags["envDefault"]
+		for _, o := range strings.Split(options, ",") {
+			if o == "required" || o == "notEmpty" {
+				v.Required = true
+			}
+		}
+		return v, true
+	}
+
+	if tag, ok := f.Tags["envconfig"]; ok || envconfig {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = envconfigName(f)
+		}
+		v.Name = prefix + strings.ToUpper(name)
+		v.Default = f.Tags["default"]
+		v.Required = f.Tags["required"] == "true"
+		return v, true
+	}
+	return Var{}, false
+}
+
+// usesEnvconfig reports whether a struct is read by envconfig, as told by an
+// envconfig or split_words tag on one of its fields.
+//
+// Parameters:
+//   - t: The struct type
+//
+// Returns:
+//   - bool: True if a field carries an envconfig-only tag
+func usesEnvconfig(t *model.Type) bool {
+	for _, f := range t.Fields {
+		_, named := f.Tags["envconfig"]
+		_, split := f.Tags["split_words"]
+		if named || split {
+			return true
+		}
+	}
+	return false
+}
+
+// wordPattern and acronymPattern are the expressions envconfig splits field
+// names into words with when their split_words tag is set.
+var (
+	wordPattern    = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymPattern = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// envconfigName returns the name envconfig gives an untagged field: the
+// field name, split into underscore-separated words when its split_words tag
+// is "true", e.g. "MaxRetries" becomes "Max_Retries" and "APIKey" "API_Key".
+//
+// Parameters:
+//   - f: The struct field
+//
+// Returns:
+//   - string: The name, before upper-casing and prefixing
+func envconfigName(f model.Field) string {
+	if f.Tags["split_words"] != "true" {
+		return f.Name
+	}
+	var words []string
+	for _, w := range wordPattern.FindAllString(f.Name, -1) {
+		if m := acronymPattern.FindStringSubmatch(w); m != nil {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+// lookup resolves a field type to a struct declared in the scanned packages.
+//
+// Parameters:
+//   - pkg: The package declaring the field
+//   - typ: The field type, e.g. "DBConfig", "*DBConfig" or "db.Config"
+//
+// Returns:
+//   - *model.Package: The package declaring the struct
+//   - *model.Type: The struct, or nil if the type is not a known struct
+func (c *collector) lookup(pkg *model.Package, typ string) (*model.Package, *model.Type) {
+	typ = strings.TrimLeft(typ, "*")
+	if qualifier, name, ok := strings.Cut(typ, "."); ok {
+		other, ok := c.byName[qualifier]
+		if !ok || !imports(pkg, other.ImportPath) {
+			return nil, nil
+		}
+		return other, c.types[typeKey(other, name)]
+	}
+	return pkg, c.types[typeKey(pkg, typ)]
+}
+
+// imports reports whether a package imports an import path.
+//
+// Parameters:
+//   - pkg: The importing package
+//   - importPath: The import path
+//
+// Returns:
+//   - bool: True if pkg imports importPath
+func imports(pkg *model.Package, importPath string) bool {
+	for _, imp := range pkg.Imports {
+		if imp == importPath {
+			return true
+		}
+	}
+	return false
+}
+
+// typeKey identifies a type across packages.
+//
+// Parameters:
+//   - pkg: The declaring package
+//   - name: The type name
+//
+// Returns:
+//   - string: The key
+func typeKey(pkg *model.Package, name string) string {
+	return pkgKey(pkg) + "." + name
+}
+
+// pkgKey returns the import path of a package, or its directory and name
+// outside a module.
+//
+// Parameters:
+//   - pkg: The package
+//
+// Returns:
+//   - string: The key
+func pkgKey(pkg *model.Package) string {
+	if pkg.ImportPath != "" {
+		return pkg.ImportPath
+	}
+	return pkg.Dir + ":" + pkg.Name
+}
+
+// WriteMarkdown writes the variables as a markdown reference table.
+//
+// Parameters:
+//   - vars: The variables, as returned by Collect
+//   - out: The writer to output the table to
+func WriteMarkdown(vars []Var, out io.Writer) {
+	fmt.Fprintln(out, "# Environment Variables")
+	fmt.Fprintln(out)
+	if len(vars) == 0 {
+		fmt.Fprintln(out, "No environment variables found.")
+		return
+	}
+	fmt.Fprintln(out, "| Variable | Type | Default | Required | Description | Field |")
+	fmt.Fprintln(out, "| --- | --- | --- | --- | --- | --- |")
+	for _, v := range vars {
+		def := ""
+		if v.Default != "" {
+			def = "`" + cell(v.Default) + "`"
+		}
+		required := ""
+		if v.Required {
+			required = "✅"
+		}
+		fmt.Fprintf(out, "| `%s` | `%s` | %s | %s | %s | `%s.%s` |\n",
+			v.Name, cell(v.Type), def, required, cell(v.Doc), v.Package, v.Field)
+	}
+}
+
+// WriteDotEnv writes the variables as a sample .env file: each variable with
+// its description as a comment and its default value. Optional variables
+// without a default are commented out; variables read by several fields are
+// written once.
+//
+// Parameters:
+//   - vars: The variables, as returned by Collect
+//   - out: The writer to output the file to
+func WriteDotEnv(vars []Var, out io.Writer) {
+	seen := map[string]bool{}
+	for _, v := range vars {
+		if seen[v.Name] {
+			continue
+		}
+		seen[v.Name] = true
+		if len(seen) > 1 {
+			fmt.Fprintln(out)
+		}
+
+		if v.Doc != "" {
+			fmt.Fprintf(out, "# %s\n", v.Doc)
+		}
+		details := v.Type
+		if v.Required {
+			details += ", required"
+		}
+		fmt.Fprintf(out, "# (%s)\n", details)
+		if !v.Required && v.Default == "" {
+			fmt.Fprint(out, "# ")
+		}
+		fmt.Fprintf(out, "%s=%s\n", v.Name, dotEnvValue(v.Default))
+	}
+}
+
+// dotEnvValue quotes a value for a .env file when it contains spaces, quotes
+// or comment characters.
+//
+// Parameters:
+//   - s: The value
+//
+// Returns:
+//   - string: The value, quoted if necessary
+func dotEnvValue(s string) string {
+	if strings.ContainsAny(s, " \t\n#\"'$\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// cell escapes text for use in a markdown table cell.
+//
+// Parameters:
+//   - s: The text
+//
+// Returns:
+//   - string: The text with pipes escaped
+func cell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,146 @@
+package envvars
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func TestCollect(t *testing.T) {
+	pkg := &model.Package{
+		Name:       "config",
+		ImportPath: "example.com/app/config",
+		Types: []model.Type{
+			{
+				Name: "Config",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "Port", Type: "int", Doc: "Port to listen on.", Tags: map[string]string{"env": "PORT", "envDefault": "8080"}},
+					{Name: "Token", Type: "string", Comment: "API token", Tags: map[string]string{"env": "TOKEN,required"}},
+					{Name: "DB", Type: "*DBConfig", Tags: map[string]string{"envPrefix": "DB_"}},
+					{Name: "Skipped", Type: "string", Tags: map[string]string{"env": "-"}},
+					{Name: "plain", Type: "string", Tags: map[string]string{"env": "PLAIN"}},
+				},
+			},
+			{
+				Name: "DBConfig",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "Host", Type: "string", Tags: map[string]string{"env": "HOST", "envDefault": "localhost"}},
+				},
+			},
+			{
+				Name: "Worker",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "Queue", Type: "string", Tags: map[string]string{"envconfig": "queue", "default": "jobs", "desc": "Queue name"}},
+					{Name: "Concurrency", Type: "int", Tags: map[string]string{"envconfig": "concurrency", "required": "true"}},
+				},
+			},
+		},
+	}
+
+	vars := Collect([]*model.Package{pkg})
+	var got []string
+	for _, v := range vars {
+		got = append(got, v.Name+"="+v.Default+" "+v.Field)
+	}
+	want := "CONCURRENCY= Worker.Concurrency, DB_HOST=localhost Config.DB.Host, PORT=8080 Config.Port, QUEUE=jobs Worker.Queue, TOKEN= Config.Token"
+	if s := strings.Join(got, ", "); s != want {
+		t.Fatalf("got  %s\nwant %s", s, want)
+	}
+	if !vars[0].Required || !vars[4].Required || vars[2].Required {
+		t.Errorf("unexpected required flags: %+v", vars)
+	}
+	if vars[3].Doc != "Queue name" || vars[4].Doc != "API token" {
+		t.Errorf("unexpected docs: %q, %q", vars[3].Doc, vars[4].Doc)
+	}
+
+	var md bytes.Buffer
+	WriteMarkdown(vars, &md)
+	for _, s := range []string{
+		"| Variable | Type | Default | Required | Description | Field |",
+		"| `PORT` | `int` | `8080` |  | Port to listen on. | `example.com/app/config.Config.Port` |",
+		"| `TOKEN` | `string` |  | ✅ | API token | `example.com/app/config.Config.Token` |",
+	} {
+		if !strings.Contains(md.String(), s) {
+			t.Errorf("expected markdown to contain %q\n%s", s, md.String())
+		}
+	}
+
+	var env bytes.Buffer
+	WriteDotEnv(vars, &env)
+	for _, s := range []string{
+		"# Port to listen on.\n# (int)\nPORT=8080\n",
+		"# (string, required)\nTOKEN=\n",
+		"# Queue name\n# (string)\nQUEUE=jobs\n",
+	} {
+		if !strings.Contains(env.String(), s) {
+			t.Errorf("expected .env to contain %q\n%s", s, env.String())
+		}
+	}
+}
+
+func TestCollect_EnvconfigUntagged(t *testing.T) {
+	pkg := &model.Package{
+		Name: "config",
+		Types: []model.Type{
+			{
+				Name: "Spec",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "Debug", Type: "bool"},
+					{Name: "MaxRetries", Type: "int", Tags: map[string]string{"split_words": "true", "default": "3"}},
+					{Name: "APIKey", Type: "string", Tags: map[string]string{"split_words": "true", "required": "true"}},
+					{Name: "Queue", Type: "string", Tags: map[string]string{"envconfig": "queue_name"}},
+					{Name: "Store", Type: "StoreSpec"},
+					{Name: "Skipped", Type: "string", Tags: map[string]string{"ignored": "true"}},
+					{Name: "internal", Type: "string"},
+				},
+			},
+			{
+				Name: "StoreSpec",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "URL", Type: "string", Comment: "database URL"},
+				},
+			},
+			{
+				// Untagged fields of a struct without envconfig tags are not variables.
+				Name: "Options",
+				Kind: "struct",
+				Fields: []model.Field{
+					{Name: "Verbose", Type: "bool"},
+				},
+			},
+		},
+	}
+
+	vars := Collect([]*model.Package{pkg})
+	var got []string
+	for _, v := range vars {
+		got = append(got, v.Name+"="+v.Default+" "+v.Field)
+	}
+	want := "API_KEY= Spec.APIKey, DEBUG= Spec.Debug, MAX_RETRIES=3 Spec.MaxRetries, QUEUE_NAME= Spec.Queue, STORE_URL= Spec.Store.URL"
+	if s := strings.Join(got, ", "); s != want {
+		t.Fatalf("got  %s\nwant %s", s, want)
+	}
+	if !vars[0].Required || vars[1].Required || vars[4].Doc != "database URL" {
+		t.Errorf("unexpected variables: %+v", vars)
+	}
+}
+
+func TestWriteDotEnv_OptionalAndQuoted(t *testing.T) {
+	var env bytes.Buffer
+	WriteDotEnv([]Var{
+		{Name: "GREETING", Type: "string", Default: "hello world"},
+		{Name: "LOG_LEVEL", Type: "string"},
+		{Name: "LOG_LEVEL", Type: "string"},
+	}, &env)
+	want := "# (string)\nGREETING=\"hello world\"\n\n# (string)\n# LOG_LEVEL=\n"
+	if env.String() != want {
+		t.Errorf("got %q, want %q", env.String(), want)
+	}
+}
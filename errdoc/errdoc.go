@@ -0,0 +1,423 @@
+// Package errdoc catalogs the errors callers of a package can match: sentinel
+// error variables created with errors.New or fmt.Errorf, for errors.Is, and
+// types implementing error, for errors.As, along with the functions wrapping
+// the sentinels with fmt.Errorf and %w.
+package errdoc
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Kinds of catalogued errors, as reported in Error.Kind.
+const (
+	KindSentinel = "sentinel"
+	KindType     = "type"
+)
+
+// Error describes a sentinel error variable or an error type.
+type Error struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+
+	// Message is the text of a sentinel, or the format string when it is
+	// created with fmt.Errorf. For types, it is the string their Error method
+	// returns when that is a constant or a fmt.Sprintf format.
+	Message string `json:"message,omitempty"`
+
+	Doc string `json:"doc,omitempty"`
+
+	// Pointer is set for types whose Error method has a pointer receiver, which
+	// errors.As must be given a **T for.
+	Pointer bool `json:"pointer,omitempty"`
+
+	// Wraps lists the sentinels a sentinel created with fmt.Errorf wraps with %w.
+	Wraps []string `json:"wraps,omitempty"`
+
+	// WrappedBy lists the functions and methods ("Type.Method") that wrap a
+	// sentinel with fmt.Errorf and %w.
+	WrappedBy []string `json:"wrappedBy,omitempty"`
+}
+
+// Catalog returns the sentinel errors declared at package level, in source
+// order, followed by the package's error types.
+//
+// Parameters:
+//   - files: The source files of the package, with function bodies
+//
+// Returns:
+//   - []Error: The errors, exported or not
+func Catalog(files []*ast.File) []Error {
+	var sentinels, types []Error
+	index := map[string]int{}
+
+	// Sentinel variables: var ErrX = errors.New("...").
+	for _, f := range files {
+		errorsPkg, fmtPkg := importedAs(f, "errors"), importedAs(f, "fmt")
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vs.Names) != len(vs.Values) {
+					continue
+				}
+				for i, name := range vs.Names {
+					call, ok := vs.Values[i].(*ast.CallExpr)
+					if !ok || len(call.Args) == 0 {
+						continue
+					}
+					var e Error
+					switch {
+					case isCall(call, errorsPkg, "New"):
+						e.Message, _ = stringValue(call.Args[0])
+					case isCall(call, fmtPkg, "Errorf"):
+						e.Message, _ = stringValue(call.Args[0])
+					default:
+						continue
+					}
+					e.Name, e.Kind = name.Name, KindSentinel
+					e.Doc = specDoc(gd, vs.Doc, len(gd.Specs))
+					index[e.Name] = len(sentinels)
+					sentinels = append(sentinels, e)
+				}
+			}
+		}
+	}
+
+	// Wrapping: fmt.Errorf("...: %w", ErrX) in sentinel definitions and function bodies.
+	for _, f := range files {
+		fmtPkg := importedAs(f, "fmt")
+		if fmtPkg == "" {
+			continue
+		}
+		for _, decl := range f.Decls {
+			scope := ""
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				scope = funcName(fn)
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if vs, ok := n.(*ast.ValueSpec); ok && scope == "" {
+					for i, name := range vs.Names {
+						j, ok := index[name.Name]
+						if !ok || i >= len(vs.Values) {
+							continue
+						}
+						if call, ok := vs.Values[i].(*ast.CallExpr); ok && isCall(call, fmtPkg, "Errorf") {
+							for _, w := range wrapped(call, index) {
+								sentinels[j].Wraps = appendUnique(sentinels[j].Wraps, w)
+							}
+						}
+					}
+					return false
+				}
+				call, ok := n.(*ast.CallExpr)
+				if !ok || scope == "" || !isCall(call, fmtPkg, "Errorf") {
+					return true
+				}
+				for _, w := range wrapped(call, index) {
+					sentinels[index[w]].WrappedBy = appendUnique(sentinels[index[w]].WrappedBy, scope)
+				}
+				return true
+			})
+		}
+	}
+
+	// Error types: types with an Error() string method.
+	typeDocs := map[string]string{}
+	var typeNames []string
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				typeNames = append(typeNames, ts.Name.Name)
+				typeDocs[ts.Name.Name] = specDoc(gd, ts.Doc, len(gd.Specs))
+			}
+		}
+	}
+	methods := map[string]*ast.FuncDecl{}
+	fmtPkgs := map[*ast.FuncDecl]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !isErrorMethod(fn) {
+				continue
+			}
+			recv, _ := receiver(fn)
+			methods[recv] = fn
+			fmtPkgs[fn] = importedAs(f, "fmt")
+		}
+	}
+	for _, name := range typeNames {
+		fn, ok := methods[name]
+		if !ok {
+			continue
+		}
+		_, pointer := receiver(fn)
+		types = append(types, Error{
+			Name:    name,
+			Kind:    KindType,
+			Message: returnedMessage(fn, fmtPkgs[fn]),
+			Doc:     typeDocs[name],
+			Pointer: pointer,
+		})
+	}
+
+	return append(sentinels, types...)
+}
+
+// wrapped returns the sentinels an fmt.Errorf call wraps with %w.
+//
+// Parameters:
+//   - call: The fmt.Errorf call
+//   - sentinels: The index of the package's sentinels by name
+//
+// Returns:
+//   - []string: The wrapped sentinel names
+func wrapped(call *ast.CallExpr, sentinels map[string]int) []string {
+	if len(call.Args) == 0 {
+		return nil
+	}
+	format, ok := stringValue(call.Args[0])
+	if !ok {
+		return nil
+	}
+	var out []string
+	for i, verb := range verbs(format) {
+		if verb != 'w' || i+1 >= len(call.Args) {
+			continue
+		}
+		if id, ok := call.Args[i+1].(*ast.Ident); ok {
+			if _, ok := sentinels[id.Name]; ok {
+				out = append(out, id.Name)
+			}
+		}
+	}
+	return out
+}
+
+// verbs returns the verbs of a format string in argument order, ignoring %%.
+// Explicit argument indexes such as %[1]w are not supported.
+//
+// Parameters:
+//   - format: The format string
+//
+// Returns:
+//   - []rune: The verbs
+func verbs(format string) []rune {
+	var out []rune
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] != '%' {
+			out = append(out, rune(format[i]))
+		}
+	}
+	return out
+}
+
+// isErrorMethod reports whether a declaration is a method Error() string.
+//
+// Parameters:
+//   - fn: The function declaration
+//
+// Returns:
+//   - bool: True for an Error method satisfying the error interface
+func isErrorMethod(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || fn.Name.Name != "Error" || fn.Type.Params.NumFields() != 0 {
+		return false
+	}
+	results := fn.Type.Results
+	if results.NumFields() != 1 {
+		return false
+	}
+	id, ok := results.List[0].Type.(*ast.Ident)
+	return ok && id.Name == "string"
+}
+
+// returnedMessage returns the message an Error method returns when it is a
+// constant string or the format of a fmt.Sprintf call.
+//
+// Parameters:
+//   - fn: The Error method
+//   - fmtPkg: The name the method's file imports fmt as
+//
+// Returns:
+//   - string: The message, or an empty string if it is computed otherwise
+func returnedMessage(fn *ast.FuncDecl, fmtPkg string) string {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return ""
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return ""
+	}
+	if s, ok := stringValue(ret.Results[0]); ok {
+		return s
+	}
+	if call, ok := ret.Results[0].(*ast.CallExpr); ok && isCall(call, fmtPkg, "Sprintf") && len(call.Args) > 0 {
+		s, _ := stringValue(call.Args[0])
+		return s
+	}
+	return ""
+}
+
+// receiver returns the base type name of a method's receiver.
+//
+// Parameters:
+//   - fn: The method declaration
+//
+// Returns:
+//   - string: The receiver type name
+//   - bool: True for a pointer receiver
+func receiver(fn *ast.FuncDecl) (string, bool) {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return "", false
+	}
+	typ, pointer := fn.Recv.List[0].Type, false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, pointer = star.X, true
+	}
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ = t.X
+	case *ast.IndexListExpr:
+		typ = t.X
+	}
+	if id, ok := typ.(*ast.Ident); ok {
+		return id.Name, pointer
+	}
+	return "", pointer
+}
+
+// funcName returns the name of a function, "Type.Method" for methods.
+//
+// Parameters:
+//   - fn: The function declaration
+//
+// Returns:
+//   - string: The name
+func funcName(fn *ast.FuncDecl) string {
+	if recv, _ := receiver(fn); recv != "" {
+		return recv + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// specDoc returns the doc comment of a spec, or of its declaration when the
+// declaration holds a single spec.
+//
+// Parameters:
+//   - gd: The declaration
+//   - doc: The spec's doc comment
+//   - specs: The number of specs in the declaration
+//
+// Returns:
+//   - string: The trimmed comment text
+func specDoc(gd *ast.GenDecl, doc *ast.CommentGroup, specs int) string {
+	if doc == nil && specs == 1 {
+		doc = gd.Doc
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// importedAs returns the name a file refers to a standard library package by.
+//
+// Parameters:
+//   - f: The file
+//   - path: The import path, e.g. "fmt"
+//
+// Returns:
+//   - string: The package name, or an empty string if the file does not import it
+func importedAs(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path
+	}
+	return ""
+}
+
+// isCall reports whether a call is pkg.name(...).
+//
+// Parameters:
+//   - call: The call
+//   - pkg: The package name, empty if the package is not imported
+//   - name: The function name
+//
+// Returns:
+//   - bool: True if the call matches
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || pkg == "" || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == pkg
+}
+
+// stringValue returns the value of a string literal or a concatenation of them.
+//
+// Parameters:
+//   - expr: The expression
+//
+// Returns:
+//   - string: The string
+//   - bool: False if the expression is not a constant string
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
+
+// appendUnique appends a value to a list unless it is already present.
+//
+// Parameters:
+//   - list: The list
+//   - v: The value
+//
+// Returns:
+//   - []string: The list
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,71 @@
+package errdoc
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	const src = `package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned when a key does not exist.
+var ErrNotFound = errors.New("store: not found")
+
+var (
+	// ErrClosed is returned after Close.
+	ErrClosed = errors.New("store: " + "closed")
+
+	// ErrMissing wraps ErrNotFound.
+	ErrMissing = fmt.Errorf("missing: %w", ErrNotFound)
+
+	limit = 10
+)
+
+// ValidationError reports an invalid value.
+type ValidationError struct{ Field string }
+
+func (e *ValidationError) Error() string { return fmt.Sprintf("invalid %s", e.Field) }
+
+type timeout struct{}
+
+func (timeout) Error() string { return "timeout" }
+
+type plain struct{}
+
+func Get(key string) error {
+	return fmt.Errorf("get %q: %w", key, ErrNotFound)
+}
+
+func (s *Store) Load() error {
+	if s == nil {
+		return fmt.Errorf("load: %w (%d%%)", ErrClosed, 100)
+	}
+	return fmt.Errorf("load: %v", ErrNotFound)
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "store.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(Catalog([]*ast.File{file}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[` +
+		`{"name":"ErrNotFound","kind":"sentinel","message":"store: not found","doc":"ErrNotFound is returned when a key does not exist.","wrappedBy":["Get"]},` +
+		`{"name":"ErrClosed","kind":"sentinel","message":"store: closed","doc":"ErrClosed is returned after Close.","wrappedBy":["Store.Load"]},` +
+		`{"name":"ErrMissing","kind":"sentinel","message":"missing: %w","doc":"ErrMissing wraps ErrNotFound.","wraps":["ErrNotFound"]},` +
+		`{"name":"ValidationError","kind":"type","message":"invalid %s","doc":"ValidationError reports an invalid value.","pointer":true},` +
+		`{"name":"timeout","kind":"type","message":"timeout"}]`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
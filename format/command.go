@@ -0,0 +1,182 @@
+package format
+
+import (
+	"fmt"
+	"go/doc"
+	"io"
+	"strings"
+
+	"github.com/thinktide/godocmd/command"
+)
+
+// WriteCommandMarkdown writes the documentation of a main package as a command
+// reference: how to install it, its usage, and tables of its flags and
+// subcommands, followed by a section per subcommand.
+//
+// Parameters:
+//   - pkg: The main package
+//   - cmd: The command line interface extracted from the package
+//   - out: The writer to output the markdown to
+//   - cfg: The rendering context; only doc links are used
+//
+// Returns:
+//   - error: Any error encountered during processing
+func WriteCommandMarkdown(pkg *doc.Package, cmd *command.Command, out io.Writer, cfg Config) error {
+	cfg.links = newDocLinker(pkg, cfg)
+	key := packageKey(pkg)
+
+	summary := fmt.Sprintf("<strong>⌨️ %s</strong>", cmd.Name)
+	if synopsis := pkg.Synopsis(pkg.Doc); synopsis != "" {
+		summary += " — " + synopsis
+	} else if cmd.Usage != "" {
+		summary += " — " + cmd.Usage
+	}
+	fmt.Fprintf(out, "%s\n<details>\n<summary>%s</summary>\n\n", anchorTag(Anchor(key, "")), summary)
+
+	if isImportPath(pkg.ImportPath) {
+		fmt.Fprintf(out, "```sh\ngo install %s@latest\n```\n\n", pkg.ImportPath)
+	}
+	if strings.TrimSpace(pkg.Doc) != "" {
+		printDoc(pkg.Doc, out, cfg)
+		fmt.Fprintln(out)
+	}
+
+	writeCommand(out, cmd, []string{cmd.Name}, cmd.Framework, key)
+
+	fmt.Fprintf(out, "</details>\n")
+	return nil
+}
+
+// writeCommand writes the usage, flags and subcommand tables of a command,
+// then a section for each subcommand.
+//
+// Parameters:
+//   - out: The writer to output the markdown to
+//   - cmd: The command
+//   - path: The names from the root command down to cmd
+//   - framework: The framework of the root command, which decides the flag syntax
+//   - key: The package's anchor key
+func writeCommand(out io.Writer, cmd *command.Command, path []string, framework, key string) {
+	fmt.Fprintf(out, "**Usage:** `%s`\n\n", commandUsage(cmd, path))
+
+	if len(path) > 1 && strings.TrimSpace(cmd.Description) != "" {
+		fmt.Fprintf(out, "%s\n\n", strings.TrimSpace(cmd.Description))
+	}
+
+	if len(cmd.Flags) > 0 {
+		fmt.Fprintln(out, "| Flag | Alias | Type | Default | Description |")
+		fmt.Fprintln(out, "| --- | --- | --- | --- | --- |")
+		for _, f := range cmd.Flags {
+			aliases := make([]string, 0, len(f.Aliases))
+			for _, a := range f.Aliases {
+				aliases = append(aliases, "`"+flagName(a, framework)+"`")
+			}
+			def := ""
+			if f.Default != "" {
+				def = "`" + tableCell(f.Default) + "`"
+			}
+			usage := tableCell(f.Usage)
+			if f.Required {
+				usage = strings.TrimSpace("**Required.** " + usage)
+			}
+			if len(f.EnvVars) > 0 {
+				usage = strings.TrimSpace(usage + " Env: `" + strings.Join(f.EnvVars, "`, `") + "`")
+			}
+			fmt.Fprintf(out, "| `%s` | %s | %s | %s | %s |\n",
+				flagName(f.Name, framework), strings.Join(aliases, ", "), f.Type, def, usage)
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(cmd.Commands) > 0 {
+		fmt.Fprintln(out, "| Command | Aliases | Description |")
+		fmt.Fprintln(out, "| --- | --- | --- |")
+		for _, sub := range cmd.Commands {
+			aliases := ""
+			if len(sub.Aliases) > 0 {
+				aliases = "`" + strings.Join(sub.Aliases, "`, `") + "`"
+			}
+			fmt.Fprintf(out, "| [`%s`](#%s) | %s | %s |\n",
+				sub.Name, commandAnchor(key, append(path, sub.Name)), aliases, tableCell(sub.Usage))
+		}
+		fmt.Fprintln(out)
+	}
+
+	for i := range cmd.Commands {
+		sub := &cmd.Commands[i]
+		subPath := append(append([]string(nil), path...), sub.Name)
+		fmt.Fprintln(out, "---")
+		fmt.Fprintf(out, "## `%s` %s\n\n", strings.Join(subPath, " "), anchorTag(commandAnchor(key, subPath)))
+		if sub.Usage != "" {
+			fmt.Fprintf(out, "%s\n\n", sub.Usage)
+		}
+		writeCommand(out, sub, subPath, framework, key)
+	}
+}
+
+// commandUsage returns the invocation syntax of a command: its UsageText,
+// prefixed with the parent commands when it starts with the command's name,
+// or one derived from its flags and subcommands.
+//
+// Parameters:
+//   - cmd: The command
+//   - path: The names from the root command down to cmd
+//
+// Returns:
+//   - string: The usage line
+func commandUsage(cmd *command.Command, path []string) string {
+	if text := strings.TrimSpace(cmd.UsageText); text != "" {
+		if strings.HasPrefix(text, cmd.Name+" ") && len(path) > 1 {
+			return strings.Join(path[:len(path)-1], " ") + " " + text
+		}
+		return text
+	}
+	usage := strings.Join(path, " ")
+	if len(cmd.Flags) > 0 {
+		usage += " [flags]"
+	}
+	if len(cmd.Commands) > 0 {
+		usage += " <command>"
+	}
+	return usage
+}
+
+// flagName returns how a flag is written on the command line: "-name" for the
+// standard flag package, otherwise "--name", or "-n" for one-letter names.
+//
+// Parameters:
+//   - name: The flag name
+//   - framework: The framework of the root command
+//
+// Returns:
+//   - string: The flag with its dashes
+func flagName(name, framework string) string {
+	if framework == command.FrameworkFlag || len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// commandAnchor returns the anchor id of a subcommand section.
+//
+// Parameters:
+//   - key: The package's anchor key
+//   - path: The names from the root command down to the subcommand
+//
+// Returns:
+//   - string: The anchor id
+func commandAnchor(key string, path []string) string {
+	return Anchor(key, "command."+strings.Join(path[1:], "."))
+}
+
+// tableCell escapes text for use in a markdown table cell.
+//
+// Parameters:
+//   - s: The text
+//
+// Returns:
+//   - string: The text on one line with pipes escaped
+func tableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,48 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/thinktide/godocmd/command"
+)
+
+func TestWriteCommandMarkdown(t *testing.T) {
+	docPkg := parseGoDocPackage("main", "// Tool does things.\npackage main\n\nfunc main() {}\n")
+	docPkg.ImportPath = "example.com/tool"
+	cmd := &command.Command{
+		Name:      "tool",
+		Framework: command.FrameworkUrfave,
+		Flags: []command.Flag{
+			{Name: "dir", Aliases: []string{"d"}, Type: "string", Default: ".", Usage: "Root | directory", EnvVars: []string{"TOOL_DIR"}},
+			{Name: "token", Type: "string", Usage: "API token", Required: true},
+		},
+		Commands: []command.Command{
+			{Name: "serve", Aliases: []string{"s"}, Usage: "Serves", UsageText: "serve [options] ADDR",
+				Commands: []command.Command{{Name: "tls", Usage: "Serves TLS"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCommandMarkdown(docPkg, cmd, &buf, Config{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	assertContains(t, out, "<summary><strong>⌨️ tool</strong> — Tool does things.</summary>", "missing summary")
+	assertContains(t, out, "go install example.com/tool@latest", "missing install snippet")
+	assertContains(t, out, "**Usage:** `tool [flags] <command>`", "missing derived usage")
+	assertContains(t, out, "| `--dir` | `-d` | string | `.` | Root \\| directory Env: `TOOL_DIR` |", "missing flag row")
+	assertContains(t, out, "| `--token` |  | string |  | **Required.** API token |", "missing required marker")
+	assertContains(t, out, "| [`serve`](#example-com-tool.command.serve) | `s` | Serves |", "missing command row")
+	assertContains(t, out, "## `tool serve` <a id=\"example-com-tool.command.serve\"></a>", "missing subcommand section")
+	assertContains(t, out, "**Usage:** `tool serve [options] ADDR`", "usage text should be prefixed with the parent command")
+	assertContains(t, out, "## `tool serve tls`", "missing nested subcommand section")
+
+	buf.Reset()
+	cmd.Framework = command.FrameworkFlag
+	if err := WriteCommandMarkdown(docPkg, cmd, &buf, Config{}); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, buf.String(), "| `-dir` | `-d` |", "flag package flags take a single dash")
+}
@@ -0,0 +1,63 @@
+package format
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/thinktide/godocmd/parse"
+)
+
+// printDoc writes a GoDoc comment as markdown, lifting any deprecation notice
+// into a GitHub warning admonition above the remaining text and converting
+// doc links into markdown links.
+//
+// Parameters:
+//   - doc: The raw GoDoc comment
+//   - out: The writer to output the markdown to
+//   - cfg: The rendering configuration used to resolve doc links
+func printDoc(doc string, out io.Writer, cfg Config) {
+	body, notice, ok := parse.SplitDeprecation(doc)
+	if ok {
+		printDeprecationCallout(out, []string{deprecationLine("", cfg.links.linkify(notice))})
+		if body != "" {
+			fmt.Fprintln(out)
+		}
+	}
+	if body != "" {
+		fmt.Fprintln(out, cfg.links.linkify(formatDocComment(body)))
+	}
+}
+
+// printDeprecationCallout writes a GitHub warning admonition containing the given lines.
+//
+// Parameters:
+//   - out: The writer to output the markdown to
+//   - lines: The notices to list in the callout, one per line
+func printDeprecationCallout(out io.Writer, lines []string) {
+	fmt.Fprintln(out, "> [!WARNING]")
+	for i, line := range lines {
+		if i > 0 {
+			fmt.Fprintln(out, ">")
+		}
+		fmt.Fprintf(out, "> %s\n", line)
+	}
+}
+
+// deprecationLine formats a single deprecation notice for a callout.
+//
+// Parameters:
+//   - name: The deprecated symbol, or empty when the callout belongs to the symbol itself
+//   - notice: The replacement text following "Deprecated:"
+//
+// Returns:
+//   - string: The formatted notice
+func deprecationLine(name, notice string) string {
+	label := "**Deprecated:**"
+	if name != "" {
+		label = fmt.Sprintf("**Deprecated:** `%s` —", name)
+	}
+	if notice == "" {
+		return label
+	}
+	return label + " " + notice
+}
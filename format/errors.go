@@ -0,0 +1,45 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thinktide/godocmd/errdoc"
+)
+
+// errorRow renders an error as a row of the Errors table.
+//
+// Parameters:
+//   - pkgName: The package name, used to qualify the error
+//   - e: The error
+//
+// Returns:
+//   - string: The table row, without a trailing newline
+func errorRow(pkgName string, e errdoc.Error) string {
+	name := e.Name
+	if pkgName != "" && pkgName != "main" {
+		name = pkgName + "." + e.Name
+	}
+
+	match := fmt.Sprintf("`errors.Is(err, %s)`", name)
+	if e.Kind == errdoc.KindType {
+		if e.Pointer {
+			name = "*" + name
+		}
+		match = fmt.Sprintf("`errors.As` with a `%s`", name)
+	}
+
+	message := ""
+	if e.Message != "" {
+		message = "`" + strings.ReplaceAll(e.Message, "|", `\|`) + "`"
+	}
+
+	desc := strings.Join(strings.Fields(e.Doc), " ")
+	if len(e.Wraps) > 0 {
+		desc = strings.TrimSpace(desc + " Wraps `" + strings.Join(e.Wraps, "`, `") + "`.")
+	}
+	if len(e.WrappedBy) > 0 {
+		desc = strings.TrimSpace(desc + " Wrapped by `" + strings.Join(e.WrappedBy, "`, `") + "`.")
+	}
+	return fmt.Sprintf("| `%s` | %s | %s | %s |", e.Name, match, message, strings.ReplaceAll(desc, "|", `\|`))
+}
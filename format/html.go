@@ -0,0 +1,617 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc/comment"
+	"go/scanner"
+	"go/token"
+	"html"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// WriteHTMLSite writes a self-contained static documentation site for the given
+// packages: an index page, one page per package, and a shared stylesheet. Every
+// page carries a sidebar navigation tree of all packages.
+//
+// Parameters:
+//   - pkgs: The packages to document
+//   - dir: The output directory, created if it does not exist
+//
+// Returns:
+//   - error: Any error encountered while rendering or writing the site
+func WriteHTMLSite(pkgs []*model.Package, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	site := &htmlSite{pages: map[string]string{}}
+	for _, p := range pkgs {
+		site.pages[modelKey(p)] = Anchor(modelKey(p), "") + ".html"
+	}
+	site.nav = buildNavTree(pkgs, site.pages)
+
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(htmlStyle), 0644); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&buf, "index", site.indexView(pkgs)); err != nil {
+		return fmt.Errorf("rendering index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	for _, p := range pkgs {
+		buf.Reset()
+		if err := htmlTemplates.ExecuteTemplate(&buf, "package", site.packageView(p)); err != nil {
+			return fmt.Errorf("rendering %s: %w", modelKey(p), err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, site.pages[modelKey(p)]), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// htmlSite holds the page locations and navigation tree shared by all pages.
+type htmlSite struct {
+	pages map[string]string
+	nav   *navNode
+}
+
+// navNode is a node of the sidebar package tree, keyed by import path element.
+type navNode struct {
+	Name     string
+	Page     string
+	Children []*navNode
+}
+
+// htmlPage is the data passed to the page templates.
+type htmlPage struct {
+	Title   string
+	Nav     *navNode
+	Current string
+	Modules []htmlModule
+	Pkg     *htmlPackage
+}
+
+// htmlPackageLink is an entry of the index page.
+type htmlPackageLink struct {
+	ImportPath string
+	Synopsis   string
+	Page       string
+}
+
+// htmlModule groups the index entries of the packages of one module. Path is
+// empty when all packages belong to a single module or to none.
+type htmlModule struct {
+	Path      string
+	GoVersion string
+	Pkgs      []htmlPackageLink
+}
+
+// htmlPackage is the view of a package page.
+type htmlPackage struct {
+	*model.Package
+	Anchor   string
+	DocHTML  template.HTML
+	Consts   []htmlValue
+	Vars     []htmlValue
+	Funcs    []htmlFunc
+	Types    []htmlType
+	Examples []htmlExample
+}
+
+// htmlFunc is the view of a function or method.
+type htmlFunc struct {
+	model.Func
+	Anchor   string
+	DeclHTML template.HTML
+	DocHTML  template.HTML
+	Examples []htmlExample
+}
+
+// htmlType is the view of a type and its associated declarations.
+type htmlType struct {
+	model.Type
+	Anchor   string
+	DeclHTML template.HTML
+	DocHTML  template.HTML
+	Consts   []htmlValue
+	Vars     []htmlValue
+	Funcs    []htmlFunc
+	Methods  []htmlFunc
+	Examples []htmlExample
+}
+
+// htmlValue is the view of a const or var group.
+type htmlValue struct {
+	model.Value
+	DeclHTML template.HTML
+	DocHTML  template.HTML
+}
+
+// htmlExample is the view of an example.
+type htmlExample struct {
+	model.Example
+	CodeHTML template.HTML
+	DocHTML  template.HTML
+}
+
+// indexView builds the data for the index page, grouping the packages by
+// module when they span several.
+//
+// Parameters:
+//   - pkgs: The documented packages
+//
+// Returns:
+//   - htmlPage: The index page data
+func (s *htmlSite) indexView(pkgs []*model.Package) htmlPage {
+	page := htmlPage{Title: "Packages", Nav: s.nav}
+	index := map[string]int{}
+	for _, p := range pkgs {
+		i, ok := index[p.Module]
+		if !ok {
+			i = len(page.Modules)
+			index[p.Module] = i
+			page.Modules = append(page.Modules, htmlModule{Path: p.Module, GoVersion: p.GoVersion})
+		}
+		page.Modules[i].Pkgs = append(page.Modules[i].Pkgs, htmlPackageLink{
+			ImportPath: modelKey(p),
+			Synopsis:   p.Synopsis,
+			Page:       s.pages[modelKey(p)],
+		})
+	}
+	sort.Slice(page.Modules, func(i, j int) bool { return page.Modules[i].Path < page.Modules[j].Path })
+	for i := range page.Modules {
+		m := &page.Modules[i]
+		sort.Slice(m.Pkgs, func(i, j int) bool { return m.Pkgs[i].ImportPath < m.Pkgs[j].ImportPath })
+	}
+	if len(page.Modules) == 1 {
+		page.Modules[0].Path = ""
+	}
+	return page
+}
+
+// packageView builds the data for a package page.
+//
+// Parameters:
+//   - p: The package to render
+//
+// Returns:
+//   - htmlPage: The package page data
+func (s *htmlSite) packageView(p *model.Package) htmlPage {
+	key := modelKey(p)
+	docs := s.docRenderer(p)
+
+	view := &htmlPackage{
+		Package:  p,
+		Anchor:   Anchor(key, ""),
+		DocHTML:  docs(p.Doc),
+		Consts:   valueViews(p.Consts, docs),
+		Vars:     valueViews(p.Vars, docs),
+		Funcs:    funcViews(key, p.Funcs, docs),
+		Examples: exampleViews(p.Examples, docs),
+	}
+	for _, t := range p.Types {
+		view.Types = append(view.Types, htmlType{
+			Type:     t,
+			Anchor:   Anchor(key, t.Name),
+			DeclHTML: highlightGo(t.Decl),
+			DocHTML:  docs(t.Doc),
+			Consts:   valueViews(t.Consts, docs),
+			Vars:     valueViews(t.Vars, docs),
+			Funcs:    funcViews(key, t.Funcs, docs),
+			Methods:  funcViews(key, t.Methods, docs),
+			Examples: exampleViews(t.Examples, docs),
+		})
+	}
+
+	return htmlPage{Title: key, Nav: s.nav, Current: s.pages[key], Pkg: view}
+}
+
+// docRenderer returns a function converting doc comments of a package into
+// HTML, with doc links resolved to pages of the site or to pkg.go.dev.
+//
+// Parameters:
+//   - p: The package whose comments will be rendered
+//
+// Returns:
+//   - func(string) template.HTML: The doc comment renderer
+func (s *htmlSite) docRenderer(p *model.Package) func(string) template.HTML {
+	key := modelKey(p)
+	symbols := map[string]bool{}
+	for _, f := range p.Funcs {
+		symbols[f.Name] = true
+	}
+	for _, t := range p.Types {
+		symbols[t.Name] = true
+		for _, m := range t.Methods {
+			symbols[t.Name+"."+m.Name] = true
+		}
+		for _, f := range t.Funcs {
+			symbols[f.Name] = true
+		}
+	}
+	imports := map[string]string{}
+	for _, imp := range p.Imports {
+		imports[importName(imp)] = imp
+	}
+
+	parser := &comment.Parser{
+		LookupPackage: func(name string) (string, bool) {
+			if name == p.Name {
+				return "", true
+			}
+			imp, ok := imports[name]
+			return imp, ok
+		},
+		LookupSym: func(recv, name string) bool {
+			if recv != "" {
+				return symbols[recv+"."+name]
+			}
+			return symbols[name]
+		},
+	}
+	printer := &comment.Printer{
+		HeadingLevel: 4,
+		DocLinkURL: func(link *comment.DocLink) string {
+			symbol := link.Name
+			if link.Recv != "" {
+				symbol = link.Recv + "." + link.Name
+			}
+			importPath := link.ImportPath
+			if importPath == "" {
+				importPath = key
+			}
+			if page, ok := s.pages[importPath]; ok {
+				if importPath == key {
+					page = ""
+				}
+				return page + "#" + Anchor(importPath, symbol)
+			}
+			return link.DefaultURL("https://pkg.go.dev")
+		},
+	}
+
+	return func(text string) template.HTML {
+		if strings.TrimSpace(text) == "" {
+			return ""
+		}
+		return template.HTML(printer.HTML(parser.Parse(text)))
+	}
+}
+
+// funcViews converts functions into their page views.
+//
+// Parameters:
+//   - key: The package's anchor key
+//   - funcs: The functions or methods
+//   - docs: The doc comment renderer
+//
+// Returns:
+//   - []htmlFunc: The function views
+func funcViews(key string, funcs []model.Func, docs func(string) template.HTML) []htmlFunc {
+	var out []htmlFunc
+	for _, f := range funcs {
+		name := f.Name
+		if f.Recv != "" {
+			name = f.Recv + "." + f.Name
+		}
+		out = append(out, htmlFunc{
+			Func:     f,
+			Anchor:   Anchor(key, name),
+			DeclHTML: highlightGo(f.Decl),
+			DocHTML:  docs(f.Doc),
+			Examples: exampleViews(f.Examples, docs),
+		})
+	}
+	return out
+}
+
+// valueViews converts const or var groups into their page views.
+//
+// Parameters:
+//   - values: The declaration groups
+//   - docs: The doc comment renderer
+//
+// Returns:
+//   - []htmlValue: The value views
+func valueViews(values []model.Value, docs func(string) template.HTML) []htmlValue {
+	var out []htmlValue
+	for _, v := range values {
+		out = append(out, htmlValue{Value: v, DeclHTML: highlightGo(v.Decl), DocHTML: docs(v.Doc)})
+	}
+	return out
+}
+
+// exampleViews converts examples into their page views.
+//
+// Parameters:
+//   - examples: The examples
+//   - docs: The doc comment renderer
+//
+// Returns:
+//   - []htmlExample: The example views
+func exampleViews(examples []model.Example, docs func(string) template.HTML) []htmlExample {
+	var out []htmlExample
+	for _, ex := range examples {
+		out = append(out, htmlExample{Example: ex, CodeHTML: highlightGo(ex.Code), DocHTML: docs(ex.Doc)})
+	}
+	return out
+}
+
+// buildNavTree arranges packages into a tree by import path element, collapsing
+// the elements shared by every package into the root.
+//
+// Parameters:
+//   - pkgs: The documented packages
+//   - pages: The page of each package, keyed by import path
+//
+// Returns:
+//   - *navNode: The root of the tree
+func buildNavTree(pkgs []*model.Package, pages map[string]string) *navNode {
+	var paths [][]string
+	for _, p := range pkgs {
+		paths = append(paths, strings.Split(modelKey(p), "/"))
+	}
+	sort.Slice(paths, func(i, j int) bool { return strings.Join(paths[i], "/") < strings.Join(paths[j], "/") })
+
+	common := 0
+	if len(paths) > 0 {
+		common = len(paths[0]) - 1
+		for _, p := range paths[1:] {
+			n := 0
+			for n < common && n < len(p) && p[n] == paths[0][n] {
+				n++
+			}
+			common = n
+		}
+	}
+
+	root := &navNode{}
+	if len(paths) > 0 {
+		root.Name = strings.Join(paths[0][:common], "/")
+	}
+	for _, p := range paths {
+		node := root
+		for _, elem := range p[common:] {
+			var child *navNode
+			for _, c := range node.Children {
+				if c.Name == elem {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &navNode{Name: elem}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+		node.Page = pages[strings.Join(p, "/")]
+	}
+	return root
+}
+
+// highlightGo renders Go source as HTML, wrapping keywords, literals, comments
+// and predeclared identifiers in spans for syntax highlighting.
+//
+// Parameters:
+//   - src: The Go source to highlight
+//
+// Returns:
+//   - template.HTML: The highlighted, HTML-escaped source
+func highlightGo(src string) template.HTML {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		start := file.Offset(pos)
+		end := start + len(tok.String())
+		if lit != "" {
+			end = start + len(lit)
+		}
+		if start < last || end > len(src) {
+			continue
+		}
+		b.WriteString(html.EscapeString(src[last:start]))
+		text := html.EscapeString(src[start:end])
+
+		class := ""
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok == token.IDENT && predeclared[lit]:
+			class = "typ"
+		}
+		if class != "" {
+			fmt.Fprintf(&b, `<span class="%s">%s</span>`, class, text)
+		} else {
+			b.WriteString(text)
+		}
+		last = end
+	}
+	b.WriteString(html.EscapeString(src[last:]))
+	return template.HTML(b.String())
+}
+
+// predeclared lists the predeclared Go identifiers highlighted as types.
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"true": true, "false": true, "nil": true, "iota": true,
+}
+
+// modelKey returns the identifier used for a package's page and anchors: its
+// import path when known, otherwise its name.
+//
+// Parameters:
+//   - p: The package model
+//
+// Returns:
+//   - string: The import path or package name
+func modelKey(p *model.Package) string {
+	if isImportPath(p.ImportPath) {
+		return p.ImportPath
+	}
+	return p.Name
+}
+
+// htmlTemplates holds the page layouts of the HTML site.
+var htmlTemplates = template.Must(template.New("site").Parse(`
+{{define "nav"}}<li>{{if .Page}}<a href="{{.Page}}">{{.Name}}</a>{{else}}<span>{{.Name}}</span>{{end}}
+{{- if .Children}}<ul>{{range .Children}}{{template "nav" .}}{{end}}</ul>{{end}}</li>{{end}}
+
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<nav class="sidebar">
+<a class="home" href="index.html">Packages</a>
+<ul>{{if .Nav.Name}}<li><span>{{.Nav.Name}}</span><ul>{{range .Nav.Children}}{{template "nav" .}}{{end}}</ul></li>{{else}}{{range .Nav.Children}}{{template "nav" .}}{{end}}{{end}}</ul>
+</nav>
+<main>
+{{end}}
+
+{{define "footer"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "examples"}}{{range .}}
+<details class="example">
+<summary>Example{{if .Suffix}} ({{.Suffix}}){{end}}</summary>
+{{.DocHTML}}
+<pre><code>{{.CodeHTML}}</code></pre>
+{{if .Output}}<p>Output:</p>
+<pre class="output">{{.Output}}</pre>{{end}}
+</details>
+{{end}}{{end}}
+
+{{define "deprecation"}}{{if .Deprecated}}<div class="deprecated"><strong>Deprecated:</strong> {{.Deprecation}}</div>{{end}}{{end}}
+
+{{define "platforms"}}{{with .Platforms}}<p class="platforms">Platforms:{{range .}} <code>{{.}}</code>{{end}}</p>{{end}}{{end}}
+
+{{define "func"}}
+<section class="symbol{{if .Deprecated}} is-deprecated{{end}}" id="{{.Anchor}}">
+<h3>{{if .Recv}}<small>{{.Recv}}.</small>{{end}}<a href="#{{.Anchor}}">{{.Name}}</a></h3>
+<pre><code>{{.DeclHTML}}</code></pre>
+{{template "platforms" .}}
+{{template "deprecation" .}}
+{{.DocHTML}}
+{{template "examples" .Examples}}
+</section>
+{{end}}
+
+{{define "values"}}{{range .}}
+<pre><code>{{.DeclHTML}}</code></pre>
+{{template "platforms" .}}
+{{template "deprecation" .}}
+{{.DocHTML}}
+{{end}}{{end}}
+
+{{define "index"}}{{template "header" .}}
+<h1>Packages</h1>
+{{range .Modules}}{{if .Path}}<h2>Module {{.Path}}</h2>
+{{with .GoVersion}}<p>Go {{.}}</p>
+{{end}}{{end}}<table class="packages">
+{{range .Pkgs}}<tr><td><a href="{{.Page}}">{{.ImportPath}}</a></td><td>{{.Synopsis}}</td></tr>
+{{end}}</table>
+{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "package"}}{{template "header" .}}
+{{with .Pkg}}
+<h1 id="{{.Anchor}}">Package {{.Name}}</h1>
+{{if .ImportPath}}<pre><code><span class="kw">import</span> <span class="str">"{{.ImportPath}}"</span></code></pre>{{end}}
+{{.DocHTML}}
+{{if .Files}}<p class="files">Files: {{range $i, $f := .Files}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}</p>{{end}}
+{{template "examples" .Examples}}
+
+<h2>Index</h2>
+<ul class="index">
+{{range .Funcs}}<li><a href="#{{.Anchor}}">func {{.Name}}</a></li>
+{{end}}{{range .Types}}<li><a href="#{{.Anchor}}">type {{.Name}}</a>
+{{- if or .Funcs .Methods}}<ul>{{range .Funcs}}<li><a href="#{{.Anchor}}">func {{.Name}}</a></li>{{end}}{{range .Methods}}<li><a href="#{{.Anchor}}">func ({{.Recv}}) {{.Name}}</a></li>{{end}}</ul>{{end}}</li>
+{{end}}</ul>
+
+{{if .Consts}}<h2>Constants</h2>{{template "values" .Consts}}{{end}}
+{{if .Vars}}<h2>Variables</h2>{{template "values" .Vars}}{{end}}
+{{if .Funcs}}<h2>Functions</h2>{{range .Funcs}}{{template "func" .}}{{end}}{{end}}
+{{if .Types}}<h2>Types</h2>{{range .Types}}
+<section class="symbol{{if .Deprecated}} is-deprecated{{end}}" id="{{.Anchor}}">
+<h3><a href="#{{.Anchor}}">{{.Name}}</a></h3>
+<pre><code>{{.DeclHTML}}</code></pre>
+{{template "platforms" .}}
+{{template "deprecation" .}}
+{{.DocHTML}}
+{{if .Fields}}<table class="fields">
+<tr><th>{{if eq .Kind "interface"}}Method{{else}}Field{{end}}</th><th>Type</th><th>Tag</th><th>Description</th></tr>
+{{range .Fields}}<tr{{if .Deprecated}} class="is-deprecated"{{end}}><td><code>{{.Name}}</code></td><td><code>{{.Type}}</code></td><td>{{if .Tag}}<code>{{.Tag}}</code>{{end}}</td><td>{{.Doc}}{{if and .Doc .Comment}} {{end}}{{.Comment}}</td></tr>
+{{end}}</table>{{end}}
+{{template "values" .Consts}}
+{{template "values" .Vars}}
+{{template "examples" .Examples}}
+{{range .Funcs}}{{template "func" .}}{{end}}
+{{range .Methods}}{{template "func" .}}{{end}}
+</section>
+{{end}}{{end}}
+
+{{if .Notes}}<h2>Notes</h2>
+<ul class="notes">{{range .Notes}}<li><strong>{{.Marker}}({{.UID}})</strong>: {{.Body}} <small>{{.Pos.File}}:{{.Pos.Line}}</small></li>
+{{end}}</ul>{{end}}
+{{end}}
+{{template "footer" .}}{{end}}
+`))
+
+// htmlStyle is the stylesheet shared by all pages of the HTML site.
+const htmlStyle = `body { margin: 0; display: flex; font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+.sidebar { width: 260px; flex-shrink: 0; height: 100vh; position: sticky; top: 0; overflow-y: auto; padding: 1rem; background: #f6f8fa; border-right: 1px solid #d0d7de; box-sizing: border-box; }
+.sidebar ul { list-style: none; padding-left: 0.8rem; margin: 0; }
+.sidebar .home { font-weight: 600; display: block; margin-bottom: 0.5rem; }
+main { flex: 1; max-width: 960px; padding: 1rem 2rem 4rem; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+pre { background: #f6f8fa; padding: 0.8rem; border-radius: 6px; overflow-x: auto; }
+code { font: 13px/1.45 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+.symbol { border-top: 1px solid #d0d7de; margin-top: 1.5rem; }
+.is-deprecated > h3 a, tr.is-deprecated code { text-decoration: line-through; }
+.platforms { color: #57606a; font-size: 0.9rem; }
+.deprecated { border-left: 4px solid #9a6700; background: #fff8c5; padding: 0.5rem 1rem; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+.kw { color: #cf222e; } .str { color: #0a3069; } .com { color: #6e7781; font-style: italic; } .num { color: #0550ae; } .typ { color: #8250df; }
+`
@@ -0,0 +1,60 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func TestWriteHTMLSite(t *testing.T) {
+	pkgs := []*model.Package{
+		{
+			Name:       "models",
+			ImportPath: "example.com/app/models",
+			Synopsis:   "Package models defines data types.",
+			Types: []model.Type{{
+				Name: "User",
+				Kind: "struct",
+				Decl: "type User struct {\n    Name string\n}",
+				Doc:  "User is stored by [store.Save].",
+			}},
+			Imports: []string{"example.com/app/store"},
+		},
+		{
+			Name:       "store",
+			ImportPath: "example.com/app/store",
+			Funcs: []model.Func{{
+				Name: "Save",
+				Decl: "func Save(u models.User) error",
+				Doc:  "Save stores a user.",
+			}},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := WriteHTMLSite(pkgs, dir); err != nil {
+		t.Fatalf("WriteHTMLSite failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(index), `<a href="example-com-app-models.html">example.com/app/models</a>`, "missing package link on index")
+
+	page, err := os.ReadFile(filepath.Join(dir, "example-com-app-models.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(page)
+	assertContains(t, out, `<span>example.com/app</span>`, "missing collapsed nav root")
+	assertContains(t, out, `id="example-com-app-models.User"`, "missing symbol anchor")
+	assertContains(t, out, `<span class="kw">type</span> User <span class="kw">struct</span>`, "missing highlighted declaration")
+	assertContains(t, out, `href="example-com-app-store.html#example-com-app-store.Save"`, "missing cross-page doc link")
+
+	if _, err := os.Stat(filepath.Join(dir, "style.css")); err != nil {
+		t.Errorf("missing stylesheet: %v", err)
+	}
+}
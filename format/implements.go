@@ -0,0 +1,57 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/model"
+)
+
+// modelImplementations renders the implementation relations of a type model.
+//
+// Parameters:
+//   - t: The type model
+//
+// Returns:
+//   - string: The Implements and Implemented by lines
+func modelImplementations(t model.Type) string {
+	return implementationLines(t.Name, t.Implements, t.ImplementedBy)
+}
+
+// implementationLines renders the interfaces a type implements and the types
+// implementing it. Interfaces only its pointer implements are followed by the
+// pointer type, and such implementing types are shown as pointers.
+//
+// Parameters:
+//   - name: The type name
+//   - impls: The interfaces the type implements
+//   - implementedBy: The types implementing the type
+//
+// Returns:
+//   - string: The lines, each followed by a blank line, or an empty string when there are none
+func implementationLines(name string, impls, implementedBy []implements.Ref) string {
+	var b strings.Builder
+	if len(impls) > 0 {
+		names := make([]string, 0, len(impls))
+		for _, r := range impls {
+			entry := "`" + r.Name + "`"
+			if r.Pointer {
+				entry += " (as `*" + name + "`)"
+			}
+			names = append(names, entry)
+		}
+		b.WriteString("**Implements:** " + strings.Join(names, ", ") + "\n\n")
+	}
+	if len(implementedBy) > 0 {
+		names := make([]string, 0, len(implementedBy))
+		for _, r := range implementedBy {
+			if r.Pointer {
+				names = append(names, "`*"+r.Name+"`")
+			} else {
+				names = append(names, "`"+r.Name+"`")
+			}
+		}
+		b.WriteString("**Implemented by:** " + strings.Join(names, ", ") + "\n\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// InjectStart marks the beginning of the generated section of a Markdown file.
+	InjectStart = "<!-- godocmd:start -->"
+
+	// InjectEnd marks the end of the generated section of a Markdown file.
+	InjectEnd = "<!-- godocmd:end -->"
+)
+
+// ErrNoMarkers is returned by Inject when a document has no InjectStart marker.
+var ErrNoMarkers = errors.New("no " + InjectStart + " marker found")
+
+// Inject replaces the content between the InjectStart and InjectEnd markers of
+// a Markdown document with generated documentation, leaving the markers and
+// everything around them untouched.
+//
+// Parameters:
+//   - document: The existing Markdown document
+//   - generated: The documentation to place between the markers
+//
+// Returns:
+//   - string: The updated document
+//   - error: ErrNoMarkers if the document has no start marker, or an error if the
+//     markers are unbalanced
+func Inject(document, generated string) (string, error) {
+	start := strings.Index(document, InjectStart)
+	if start < 0 {
+		return "", ErrNoMarkers
+	}
+	bodyStart := start + len(InjectStart)
+
+	end := strings.Index(document[bodyStart:], InjectEnd)
+	if end < 0 {
+		return "", fmt.Errorf("%s marker has no matching %s", InjectStart, InjectEnd)
+	}
+	end += bodyStart
+
+	if strings.Contains(document[end+len(InjectEnd):], InjectStart) {
+		return "", fmt.Errorf("multiple %s markers found", InjectStart)
+	}
+	if strings.Contains(document[bodyStart:end], InjectStart) {
+		return "", fmt.Errorf("nested %s marker found", InjectStart)
+	}
+
+	var b strings.Builder
+	b.WriteString(document[:bodyStart])
+	b.WriteString("\n")
+	if generated = strings.Trim(generated, "\n"); generated != "" {
+		b.WriteString(generated)
+		b.WriteString("\n")
+	}
+	b.WriteString(document[end:])
+	return b.String(), nil
+}
@@ -0,0 +1,49 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInject(t *testing.T) {
+	document := "# Models\n\nHand-written intro.\n\n" +
+		InjectStart + "\nstale output\n" + InjectEnd + "\n\n## License\n"
+
+	got, err := Inject(document, "## User\n\nfresh output\n\n")
+	if err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+	want := "# Models\n\nHand-written intro.\n\n" +
+		InjectStart + "\n## User\n\nfresh output\n" + InjectEnd + "\n\n## License\n"
+	if got != want {
+		t.Errorf("unexpected document:\n%s", got)
+	}
+
+	// Injecting the same output again is a no-op.
+	again, err := Inject(got, "## User\n\nfresh output\n")
+	if err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+	if again != got {
+		t.Errorf("expected re-injection to be stable:\n%s", again)
+	}
+}
+
+func TestInject_Errors(t *testing.T) {
+	if _, err := Inject("# Models\n", "docs"); !errors.Is(err, ErrNoMarkers) {
+		t.Errorf("expected ErrNoMarkers, got %v", err)
+	}
+
+	for name, document := range map[string]string{
+		"unterminated": InjectStart + "\ndocs\n",
+		"multiple":     InjectStart + InjectEnd + "\n" + InjectStart + InjectEnd,
+		"nested":       InjectStart + InjectStart + InjectEnd,
+	} {
+		if _, err := Inject(document, "docs"); err == nil || errors.Is(err, ErrNoMarkers) {
+			t.Errorf("%s: expected a marker error, got %v", name, err)
+		} else if !strings.Contains(err.Error(), "godocmd:") {
+			t.Errorf("%s: expected the error to name the markers, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// WriteJSON writes the documentation model of the given packages as an indented,
+// versioned JSON document (see model.Version).
+//
+// Parameters:
+//   - pkgs: The packages to encode
+//   - out: The writer to output the JSON to
+//
+// Returns:
+//   - error: Any error encountered while encoding
+func WriteJSON(pkgs []*model.Package, out io.Writer) error {
+	if pkgs == nil {
+		pkgs = []*model.Package{}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(model.Document{Version: model.Version, Packages: pkgs})
+}
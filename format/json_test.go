@@ -0,0 +1,62 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func TestWriteJSON(t *testing.T) {
+	pkgs := []*model.Package{{
+		Name:       "models",
+		ImportPath: "example.com/app/models",
+		Types: []model.Type{{
+			Name: "User",
+			Kind: "struct",
+			Fields: []model.Field{{
+				Name: "Name",
+				Type: "string",
+				Tag:  `json:"name,omitempty"`,
+				Tags: model.ParseTag(`json:"name,omitempty"`),
+				Pos:  model.Position{File: "models/user.go", Line: 7, Column: 2},
+			}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(pkgs, &buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var doc struct {
+		Version  int
+		Packages []struct {
+			ImportPath string
+			Types      []struct {
+				Fields []struct {
+					Tags map[string]string
+					Pos  struct {
+						File string
+						Line int
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Version != model.Version {
+		t.Errorf("expected version %d, got %d", model.Version, doc.Version)
+	}
+	field := doc.Packages[0].Types[0].Fields[0]
+	if field.Tags["json"] != "name,omitempty" {
+		t.Errorf("unexpected tags %v", field.Tags)
+	}
+	if field.Pos.File != "models/user.go" || field.Pos.Line != 7 {
+		t.Errorf("unexpected position %+v", field.Pos)
+	}
+	assertContains(t, buf.String(), `"importPath": "example.com/app/models"`, "missing import path key")
+}
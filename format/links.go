@@ -0,0 +1,192 @@
+package format
+
+import (
+	"fmt"
+	"go/doc"
+	"path"
+	"strings"
+
+	"github.com/thinktide/godocmd/parse"
+)
+
+// Anchor returns the HTML anchor id used for a package or, when symbol is set,
+// for a symbol within it (e.g. "User" or "User.Save").
+//
+// Parameters:
+//   - importPath: The import path of the package
+//   - symbol: The symbol name, or an empty string for the package itself
+//
+// Returns:
+//   - string: The anchor id, safe to use in an HTML id attribute and URL fragment
+func Anchor(importPath, symbol string) string {
+	var b strings.Builder
+	for _, r := range importPath {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	if symbol != "" {
+		b.WriteString("." + symbol)
+	}
+	return b.String()
+}
+
+// anchorTag returns an empty HTML anchor element with the given id.
+//
+// Parameters:
+//   - id: The anchor id
+//
+// Returns:
+//   - string: The anchor element
+func anchorTag(id string) string {
+	return fmt.Sprintf(`<a id="%s"></a>`, id)
+}
+
+// docLinker resolves GoDoc links in comments of a single package to markdown links.
+type docLinker struct {
+	importPath string
+	symbols    map[string]bool
+	imports    map[string]string
+	packageURL func(importPath string) (string, bool)
+}
+
+// newDocLinker indexes the symbols and imports of a package for resolving its doc links.
+//
+// Parameters:
+//   - pkg: The documentation package whose comments will be linked
+//   - cfg: The rendering configuration supplying PackageURL
+//
+// Returns:
+//   - *docLinker: The linker for the package
+func newDocLinker(pkg *doc.Package, cfg Config) *docLinker {
+	l := &docLinker{
+		importPath: packageKey(pkg),
+		symbols:    map[string]bool{},
+		imports:    map[string]string{},
+		packageURL: cfg.PackageURL,
+	}
+	for _, f := range pkg.Funcs {
+		l.symbols[f.Name] = true
+	}
+	for _, t := range pkg.Types {
+		l.symbols[t.Name] = true
+		for _, m := range t.Methods {
+			l.symbols[t.Name+"."+m.Name] = true
+		}
+	}
+	for _, imp := range pkg.Imports {
+		l.imports[importName(imp)] = imp
+	}
+	return l
+}
+
+// linkify rewrites the GoDoc links in a rendered comment into markdown links.
+// Links that cannot be resolved are left untouched.
+//
+// Parameters:
+//   - text: The markdown text of a comment
+//
+// Returns:
+//   - string: The text with resolvable doc links converted to markdown links
+func (l *docLinker) linkify(text string) string {
+	if l == nil {
+		return text
+	}
+	links := parse.DocLinks(text)
+	if len(links) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, link := range links {
+		url, ok := l.resolve(link.Target)
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:link.Start])
+		fmt.Fprintf(&b, "[%s](%s)", text[link.Start+1:link.End-1], url)
+		last = link.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// resolve maps a doc link target to a URL.
+//
+// Parameters:
+//   - target: The link target without brackets or leading star
+//
+// Returns:
+//   - string: The URL of the linked package or symbol
+//   - bool: True if the target could be resolved
+func (l *docLinker) resolve(target string) (string, bool) {
+	if l.symbols[target] {
+		return "#" + Anchor(l.importPath, target), true
+	}
+
+	pkgPath, symbol := target, ""
+	slash := strings.LastIndex(target, "/")
+	if dot := strings.Index(target[slash+1:], "."); dot >= 0 {
+		pkgPath, symbol = target[:slash+1+dot], target[slash+1+dot+1:]
+	}
+	if !strings.Contains(pkgPath, "/") {
+		// As in go/doc/comment, packages that are not imported are assumed
+		// to be in the standard library.
+		if imp, ok := l.imports[pkgPath]; ok {
+			pkgPath = imp
+		} else if symbol == "" || strings.ToLower(pkgPath) != pkgPath {
+			return "", false
+		}
+	}
+	if pkgPath == l.importPath {
+		return "#" + Anchor(pkgPath, symbol), true
+	}
+
+	if l.packageURL != nil {
+		if base, ok := l.packageURL(pkgPath); ok {
+			return base + "#" + Anchor(pkgPath, symbol), true
+		}
+	}
+	url := "https://pkg.go.dev/" + pkgPath
+	if symbol != "" {
+		url += "#" + symbol
+	}
+	return url, true
+}
+
+// packageKey returns the identifier used for a package's anchors: its import
+// path when known, otherwise its name.
+//
+// Parameters:
+//   - pkg: The documentation package
+//
+// Returns:
+//   - string: The import path or package name
+func packageKey(pkg *doc.Package) string {
+	if isImportPath(pkg.ImportPath) {
+		return pkg.ImportPath
+	}
+	return pkg.Name
+}
+
+// importName guesses the package name of an import path from its last element,
+// skipping major version suffixes such as "/v2".
+//
+// Parameters:
+//   - importPath: The import path
+//
+// Returns:
+//   - string: The probable package name
+func importName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.IndexAny(base, ".-"); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
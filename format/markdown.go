@@ -1,16 +1,19 @@
 package format
 
 import (
-	"fmt"
-	"go/ast"
 	"go/doc"
+	"go/token"
 	"io"
-	"reflect"
 	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/thinktide/godocmd/model"
+	"github.com/thinktide/godocmd/parse"
 )
 
 // StructFieldInfo represents metadata about a struct field, including its name, type,
-// comments, and any struct tags like json or dynamodbav.
+// comments, any struct tags like json or dynamodbav, and its deprecation notice.
 type StructFieldInfo struct {
 	Name       string
 	Type       string
@@ -18,278 +21,135 @@ type StructFieldInfo struct {
 	JSONTag    string
 	DynamoTag  string
 	DynamoType string
+	Tags       map[string]string // every struct tag of the field, keyed by tag key
+	Deprecated bool
+	Notice     string
 }
 
-// WriteMarkdownWithOptions generates a markdown representation of a Go package with options for visibility and documentation filters.
-//
-// Parameters:
-//   - pkg: The Go package to document.
-//   - out: The writer to output the markdown to.
-//   - includePrivate: Flag to include non-exported (private) symbols.
-//   - includeUndocumented: Flag to include symbols missing documentation.
-//
-// Returns:
-//   - error: Any error encountered during processing.
-func WriteMarkdownWithOptions(pkg *doc.Package, out io.Writer, includePrivate, includeUndocumented bool) error {
-	// First count visible symbols after applying filters
-	visibleSymbols := 0
-
-	// Count visible functions
-	for _, f := range pkg.Funcs {
-		if (!includePrivate && !isExported(f.Name)) ||
-			(!includeUndocumented && strings.TrimSpace(f.Doc) == "") {
-			continue
-		}
-		visibleSymbols++
-	}
-
-	// Count visible types
-	for _, t := range pkg.Types {
-		if (!includePrivate && !isExported(t.Name)) ||
-			(!includeUndocumented && strings.TrimSpace(t.Doc) == "") {
-			continue
-		}
-		visibleSymbols++
-	}
-
-	if visibleSymbols == 0 {
-		return nil
-	}
+// Config controls which symbols WriteMarkdownWithConfig renders and supplies
+// the context needed to render them.
+type Config struct {
+	// IncludePrivate includes non-exported (private) symbols.
+	IncludePrivate bool
 
-	fmt.Fprintf(out, "<details>\n<summary><strong>📦 %s</strong></summary>\n\n", pkg.Name)
+	// IncludeUndocumented includes symbols missing documentation.
+	IncludeUndocumented bool
 
-	// Process visible functions
-	for _, f := range pkg.Funcs {
-		if !includePrivate && !isExported(f.Name) {
-			continue
-		}
-		if !includeUndocumented && strings.TrimSpace(f.Doc) == "" {
-			continue
-		}
-		printFunc(f, out)
-	}
+	// HideDeprecated omits symbols, fields and constants marked "Deprecated:".
+	HideDeprecated bool
 
-	for _, t := range pkg.Types {
-		if !includePrivate && !isExported(t.Name) {
-			continue
-		}
-		if !includeUndocumented && strings.TrimSpace(t.Doc) == "" {
-			continue
-		}
+	// NoteMarkers lists the `MARKER(who):` note markers to render, in order.
+	// DefaultNoteMarkers is used when it is empty.
+	NoteMarkers []string
 
-		fmt.Fprintln(out, "\n---")
-		fmt.Fprintf(out, "## %s\n\n", t.Name)
+	// Fset is the file set the package was parsed with. Examples are only
+	// rendered when it is set.
+	Fset *token.FileSet
 
-		// Process type declaration and definition
-		for _, spec := range t.Decl.Specs {
-			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-				switch underlying := typeSpec.Type.(type) {
-				case *ast.StructType:
-					if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-						// Print struct type definition first
-						structText, fields := renderStructType(typeSpec, structType)
-						fmt.Fprintf(out, "```go\n%s\n```\n\n", structText)
+	// PackageURL locates the documentation of other packages for doc links such
+	// as [models.User]. It returns the document's URL relative to the current one
+	// (an empty string for the same document) and whether the package is documented.
+	// Links to undocumented packages point to pkg.go.dev.
+	PackageURL func(importPath string) (string, bool)
 
-						// Print documentation after
-						if t.Doc != "" {
-							fmt.Fprintln(out, formatDocComment(t.Doc))
-							fmt.Fprintln(out)
-						}
+	// TagRenderers lists the struct tag blocks rendered after each struct, in
+	// order. DefaultTagRenderers is used when it is nil.
+	TagRenderers []TagRenderer
 
-						// Add JSON and DynamoDB blocks (if available)
-						if jsonOut := renderJSONBlock(fields); jsonOut != "" {
-							fmt.Fprintln(out, jsonOut)
-						}
-						if dynamoOut := renderDynamoBlock(fields); dynamoOut != "" {
-							fmt.Fprintln(out, dynamoOut)
-						}
-					}
-				case *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.InterfaceType, *ast.StarExpr, *ast.SelectorExpr:
-					// Print type alias or other complex types
-					fmt.Fprintf(out, "```go\ntype %s %s\n```\n\n", typeSpec.Name.Name, exprToString(underlying))
+	// OutputDir is the directory of the Markdown file being written. Links to
+	// source files, such as those of notes, are relative to it; they are
+	// relative to the working directory when it is empty.
+	OutputDir string
 
-					// Print documentation after the type definition
-					if t.Doc != "" {
-						fmt.Fprintln(out, formatDocComment(t.Doc))
-						fmt.Fprintln(out)
-					}
-				default:
-					// Handle unknown type
-					fmt.Fprintf(out, "```go\ntype %s <unknown type>\n```\n\n", typeSpec.Name.Name)
+	// ExampleFailures holds the go test report of examples whose output did not
+	// match their `// Output:` comment, keyed by example function name.
+	ExampleFailures map[string]string
 
-					if t.Doc != "" {
-						fmt.Fprintln(out, formatDocComment(t.Doc))
-						fmt.Fprintln(out)
-					}
-				}
-			}
-		}
-
-		// Add method details
-		for _, m := range t.Methods {
-			if !includePrivate && !isExported(m.Name) {
-				continue
-			}
-			if !includeUndocumented && strings.TrimSpace(m.Doc) == "" {
-				continue
-			}
-			printFunc(m, out)
-		}
-	}
-
-	fmt.Fprintf(out, "</details>\n")
-
-	return nil
+	// links resolves doc links for the package being rendered.
+	links *docLinker
 }
 
-// WriteMarkdown is a convenience alias that includes all symbols.
-// Deprecated: use WriteMarkdownWithOptions with explicit flags.
-func WriteMarkdown(pkg *doc.Package, out io.Writer) error {
-	return WriteMarkdownWithOptions(pkg, out, true, true)
-}
-
-// printFunc writes a markdown section for a Go function or method.
+// WriteMarkdownWithOptions generates a markdown representation of a Go package with options for visibility and documentation filters.
 //
 // Parameters:
-//   - f: The Go function or method to document
-//   - out: The writer to output the markdown to
-func printFunc(f *doc.Func, out io.Writer) {
-	decl := formatFuncDecl(f.Decl)
-
-	fmt.Fprintln(out, "\n---")
-	if f.Recv != "" {
-		recv := formatReceiverName(f.Decl)
-		fmt.Fprintf(out, "## <small><em>%s.</em></small>%s\n\n", recv, f.Name)
-	} else {
-		fmt.Fprintf(out, "## %s\n\n", f.Name)
-	}
-
-	fmt.Fprintf(out, "```go\n%s\n```\n\n", decl)
-
-	if f.Doc != "" {
-		fmt.Fprintln(out, formatDocComment(f.Doc))
-	}
+//   - pkg: The Go package to document.
+//   - out: The writer to output the markdown to.
+//   - includePrivate: Flag to include non-exported (private) symbols.
+//   - includeUndocumented: Flag to include symbols missing documentation.
+//
+// Returns:
+//   - error: Any error encountered during processing.
+func WriteMarkdownWithOptions(pkg *doc.Package, out io.Writer, includePrivate, includeUndocumented bool) error {
+	return WriteMarkdownWithConfig(pkg, out, Config{
+		IncludePrivate:      includePrivate,
+		IncludeUndocumented: includeUndocumented,
+	})
 }
 
-// renderStructType formats a Go struct type as a Go code block and extracts field metadata.
+// WriteMarkdownWithConfig generates a markdown representation of a Go package as described by cfg.
+// The package is converted into its model and rendered through the built-in
+// templates, as the godocmd command renders every package.
 //
 // Parameters:
-//   - spec: The AST TypeSpec for the struct
-//   - structType: The AST StructType to render
+//   - pkg: The Go package to document.
+//   - out: The writer to output the markdown to.
+//   - cfg: The visibility filters and rendering context to apply.
 //
 // Returns:
-//   - string: Formatted Go code block of the struct
-//   - []StructFieldInfo: Metadata for each struct field
-func renderStructType(spec *ast.TypeSpec, structType *ast.StructType) (string, []StructFieldInfo) {
-	var b strings.Builder
-	var fields []StructFieldInfo
-	maxFieldLen := 0
-	maxTypeLen := 0
-
-	for _, field := range structType.Fields.List {
-		if len(field.Names) == 0 {
-			continue
-		}
-		name := field.Names[0].Name
-		typ := exprToString(field.Type)
-		if len(name) > maxFieldLen {
-			maxFieldLen = len(name)
-		}
-		if len(typ) > maxTypeLen {
-			maxTypeLen = len(typ)
-		}
+//   - error: Any error encountered during processing.
+func WriteMarkdownWithConfig(pkg *doc.Package, out io.Writer, cfg Config) error {
+	tmpl, err := defaultTemplates()
+	if err != nil {
+		return err
 	}
 
-	b.WriteString(fmt.Sprintf("type %s struct {\n", spec.Name.Name))
-	for _, field := range structType.Fields.List {
-		if len(field.Names) == 0 {
-			continue
-		}
-		name := field.Names[0].Name
-		typ := exprToString(field.Type)
-		comment := ""
-		if field.Comment != nil && len(field.Comment.List) > 0 {
-			comment = strings.TrimPrefix(field.Comment.List[0].Text, "//")
-			comment = strings.TrimSpace(comment)
-		}
-
-		jsonTag, dynamoTag := "", ""
-		if field.Tag != nil {
-			tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
-			raw := tag.Get("json")
-			jsonTag = strings.Split(raw, ",")[0]
-			dynamoTag = tag.Get("dynamodbav")
-		}
-
-		line := fmt.Sprintf("    %-*s %-*s", maxFieldLen, name, maxTypeLen, typ)
-		if comment != "" {
-			line += " // " + comment
-		}
-		b.WriteString(line + "\n")
-
-		fields = append(fields, StructFieldInfo{
-			Name:       name,
-			Type:       typ,
-			Comment:    comment,
-			JSONTag:    jsonTag,
-			DynamoTag:  dynamoTag,
-			DynamoType: mapGoTypeToDynamoType(typ),
-		})
+	fset := cfg.Fset
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	m := model.Build(&parse.Package{Doc: pkg, Fset: fset, ImportPath: pkg.ImportPath}, model.Options{
+		IncludePrivate:      cfg.IncludePrivate,
+		IncludeUndocumented: cfg.IncludeUndocumented,
+		HideDeprecated:      cfg.HideDeprecated,
+	})
+	if cfg.Fset == nil {
+		dropExamples(m)
 	}
-	b.WriteString("}")
-	return b.String(), fields
+	return WriteMarkdownTemplate(m, out, tmpl, cfg)
 }
 
-// renderJSONBlock returns a markdown code block for struct JSON tags.
+// defaultTemplates parses the built-in Markdown templates once.
+var defaultTemplates = sync.OnceValues(func() (*template.Template, error) {
+	return LoadTemplates("")
+})
+
+// dropExamples removes the examples of a package model, whose code cannot be
+// printed without the file set the package was parsed with.
 //
 // Parameters:
-//   - fields: Slice of StructFieldInfo to extract json tags from
-//
-// Returns:
-//   - string: A markdown-formatted code block with JSON field names
-func renderJSONBlock(fields []StructFieldInfo) string {
-	var b strings.Builder
-	var tags []string
-	for _, f := range fields {
-		if f.JSONTag != "" {
-			tags = append(tags, fmt.Sprintf(`  "%s",`, f.JSONTag))
-		}
+//   - m: The package model
+func dropExamples(m *model.Package) {
+	m.Examples = nil
+	for i := range m.Funcs {
+		m.Funcs[i].Examples = nil
 	}
-	if len(tags) == 0 {
-		return ""
-	}
-	b.WriteString("#### JSON\n\n```json\n{\n")
-	for _, tag := range tags {
-		b.WriteString(tag + "\n")
+	for i := range m.Types {
+		t := &m.Types[i]
+		t.Examples = nil
+		for j := range t.Funcs {
+			t.Funcs[j].Examples = nil
+		}
+		for j := range t.Methods {
+			t.Methods[j].Examples = nil
+		}
 	}
-	b.WriteString("}\n```")
-	return b.String()
 }
 
-// renderDynamoBlock returns a markdown code block for struct DynamoDB tags.
-//
-// Parameters:
-//   - fields: Slice of StructFieldInfo to extract DynamoDB mappings from
+// WriteMarkdown is a convenience alias that includes all symbols.
 //
-// Returns:
-//   - string: A markdown-formatted table of DynamoDB field mappings
-func renderDynamoBlock(fields []StructFieldInfo) string {
-	var b strings.Builder
-	var tags []string
-	for _, f := range fields {
-		if f.DynamoTag != "" {
-			tags = append(tags, fmt.Sprintf("%-25s %s", f.DynamoTag, f.DynamoType))
-		}
-	}
-	if len(tags) == 0 {
-		return ""
-	}
-	b.WriteString("#### DynamoDB\n\n```sql\n")
-	for _, line := range tags {
-		b.WriteString(line + "\n")
-	}
-	b.WriteString("```\n")
-	return b.String()
+// Deprecated: use WriteMarkdownWithOptions with explicit flags.
+func WriteMarkdown(pkg *doc.Package, out io.Writer) error {
+	return WriteMarkdownWithOptions(pkg, out, true, true)
 }
 
 // mapGoTypeToDynamoType converts a Go type to an approximate DynamoDB type.
@@ -314,97 +174,6 @@ func mapGoTypeToDynamoType(goType string) string {
 	}
 }
 
-// formatFuncDecl formats an AST FuncDecl into a Go-style function signature string.
-//
-// Parameters:
-//   - decl: The AST function declaration
-//
-// Returns:
-//   - string: A rendered Go-style function signature
-func formatFuncDecl(decl *ast.FuncDecl) string {
-	var buf strings.Builder
-	buf.WriteString("func ")
-	if decl.Recv != nil {
-		buf.WriteString("(")
-		for _, f := range decl.Recv.List {
-			buf.WriteString(fieldListToString(f))
-		}
-		buf.WriteString(") ")
-	}
-	buf.WriteString(decl.Name.Name)
-	buf.WriteString("(")
-	if decl.Type.Params != nil {
-		for i, p := range decl.Type.Params.List {
-			if i > 0 {
-				buf.WriteString(", ")
-			}
-			buf.WriteString(fieldListToString(p))
-		}
-	}
-	buf.WriteString(")")
-	if decl.Type.Results != nil && len(decl.Type.Results.List) > 0 {
-		buf.WriteString(" ")
-		if len(decl.Type.Results.List) > 1 {
-			buf.WriteString("(")
-		}
-		for i, r := range decl.Type.Results.List {
-			if i > 0 {
-				buf.WriteString(", ")
-			}
-			buf.WriteString(fieldListToString(r))
-		}
-		if len(decl.Type.Results.List) > 1 {
-			buf.WriteString(")")
-		}
-	}
-	return buf.String()
-}
-
-// fieldListToString renders a list of field names and types.
-//
-// Parameters:
-//   - f: The AST field node to render
-//
-// Returns:
-//   - string: A comma-separated list of field declarations
-func fieldListToString(f *ast.Field) string {
-	var buf strings.Builder
-	for i, n := range f.Names {
-		if i > 0 {
-			buf.WriteString(", ")
-		}
-		buf.WriteString(n.Name + " ")
-	}
-	buf.WriteString(exprToString(f.Type))
-	return buf.String()
-}
-
-// exprToString converts an AST expression into its Go code string form.
-//
-// Parameters:
-//   - expr: The AST expression to convert
-//
-// Returns:
-//   - string: A human-readable Go representation
-func exprToString(expr ast.Expr) string {
-	switch e := expr.(type) {
-	case *ast.Ident:
-		return e.Name
-	case *ast.StarExpr:
-		return "*" + exprToString(e.X)
-	case *ast.SelectorExpr:
-		return exprToString(e.X) + "." + e.Sel.Name
-	case *ast.ArrayType:
-		return "[]" + exprToString(e.Elt)
-	case *ast.MapType:
-		return "map[" + exprToString(e.Key) + "]" + exprToString(e.Value)
-	case *ast.InterfaceType:
-		return "interface{}"
-	default:
-		return fmt.Sprintf("%T", expr)
-	}
-}
-
 // formatDocComment converts a GoDoc comment into markdown by trimming slashes and joining lines.
 //
 // Parameters:
@@ -421,40 +190,3 @@ func formatDocComment(doc string) string {
 	}
 	return strings.TrimSpace(b.String())
 }
-
-// formatReceiverName extracts the receiver type name from a method declaration.
-//
-// Parameters:
-//   - fn: The function declaration with a receiver
-//
-// Returns:
-//   - string: The type name of the receiver
-func formatReceiverName(fn *ast.FuncDecl) string {
-	if fn.Recv == nil || len(fn.Recv.List) == 0 {
-		return ""
-	}
-	switch r := fn.Recv.List[0].Type.(type) {
-	case *ast.StarExpr:
-		if ident, ok := r.X.(*ast.Ident); ok {
-			return ident.Name
-		}
-	case *ast.Ident:
-		return r.Name
-	}
-	return exprToString(fn.Recv.List[0].Type)
-}
-
-// isExported checks if a symbol name is exported (starts with an uppercase letter).
-//
-// Parameters:
-//   - name: The identifier name to check
-//
-// Returns:
-//   - bool: True if exported, false otherwise
-func isExported(name string) bool {
-	if name == "" {
-		return false
-	}
-	r := rune(name[0])
-	return strings.ToUpper(string(r)) == string(r)
-}
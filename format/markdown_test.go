@@ -6,8 +6,14 @@ import (
 	"go/doc"
 	"go/parser"
 	"go/token"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/thinktide/godocmd/errdoc"
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/model"
+	"github.com/thinktide/godocmd/parse"
 )
 
 func TestWriteMarkdown_StructWithTags(t *testing.T) {
@@ -51,6 +57,54 @@ func parseGoDocPackage(name string, code string) *doc.Package {
 	}, "./", 0)
 }
 
+// parseModel is a test helper that parses a single-file package and builds
+// its model with every symbol included.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - name: The package name, also used as the file name
+//   - code: The Go source of the file
+//
+// Returns:
+//   - *model.Package: The package model
+func parseModel(t *testing.T, name, code string) *model.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name+".go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg, err := doc.NewFromFiles(fset, []*ast.File{file}, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &parse.Package{Doc: docPkg, Fset: fset, Files: []*ast.File{file}}
+	return model.Build(pkg, model.Options{IncludePrivate: true, IncludeUndocumented: true})
+}
+
+// renderModel is a test helper that renders a package model through the
+// built-in templates.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - m: The package model
+//   - cfg: The rendering configuration
+//
+// Returns:
+//   - string: The Markdown output
+func renderModel(t *testing.T, m *model.Package, cfg Config) string {
+	t.Helper()
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteMarkdownTemplate(m, &buf, tmpl, cfg); err != nil {
+		t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+	}
+	return buf.String()
+}
+
 func assertContains(t *testing.T, haystack, needle, message string) {
 	t.Helper()
 	if !strings.Contains(haystack, needle) {
@@ -116,3 +170,331 @@ type Beta struct{}
 	assertContains(t, out, "## Alpha", "expected private function to be included")
 	assertContains(t, out, "## Beta", "expected private type to be included")
 }
+
+func TestWriteMarkdown_ExamplesAndFailures(t *testing.T) {
+	const src = `
+package testpkg
+
+// Hello returns a greeting.
+func Hello() string { return "hello" }
+`
+	const test = `
+package testpkg
+
+import "fmt"
+
+func ExampleHello() {
+	fmt.Println(Hello())
+	// Output: hi
+}
+`
+
+	fset := token.NewFileSet()
+	srcFile, err := parser.ParseFile(fset, "testpkg.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFile, err := parser.ParseFile(fset, "testpkg_test.go", test, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg, err := doc.NewFromFiles(fset, []*ast.File{srcFile, testFile}, "./testpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = WriteMarkdownWithConfig(docPkg, &buf, Config{
+		Fset:            fset,
+		ExampleFailures: map[string]string{"ExampleHello": "got:\nhello\nwant:\nhi"},
+	})
+	if err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+
+	out := buf.String()
+	assertContains(t, out, "#### Example", "missing example heading")
+	assertContains(t, out, "fmt.Println(Hello())", "missing example code")
+	assertNotContains(t, out, "// Output: hi", "output comment should be rendered separately")
+	assertContains(t, out, "```text\nhi\n```", "missing expected output block")
+	assertContains(t, out, "> [!CAUTION]", "missing verification failure callout")
+}
+
+func TestWriteMarkdown_Deprecation(t *testing.T) {
+	const input = `
+package testpkg
+
+// Old does things.
+//
+// Deprecated: use New instead.
+func Old() {}
+
+// New does things.
+func New() {}
+
+// Load reads the file; the old API is now
+// Deprecated: see New for details.
+func Load() {}
+
+// Config holds settings.
+type Config struct {
+	// Deprecated: use Timeout.
+	Wait int
+	Timeout int
+}
+
+// Bounds.
+const (
+	// Deprecated: use Max.
+	Limit = 1
+	Max = 2
+)
+`
+
+	docPkg := parseGoDocPackage("testpkg", input)
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out := buf.String()
+	assertContains(t, out, "## ~~Old~~", "expected deprecated heading to be struck through")
+	assertContains(t, out, "> **Deprecated:** use New instead.", "missing function deprecation callout")
+	assertContains(t, out, "> **Deprecated:** `Wait` — use Timeout.", "missing field deprecation callout")
+	assertContains(t, out, "> **Deprecated:** `Limit` — use Max.", "missing constant deprecation callout")
+	assertContains(t, out, "## Load", "expected mid-paragraph Deprecated: not to strike through the heading")
+	assertNotContains(t, out, "> **Deprecated:** see New", "expected mid-paragraph Deprecated: not to become a callout")
+
+	buf.Reset()
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{HideDeprecated: true}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out = buf.String()
+	assertNotContains(t, out, "Old", "expected deprecated function to be hidden")
+	assertNotContains(t, out, "Wait", "expected deprecated field to be hidden")
+	assertNotContains(t, out, "Limit", "expected deprecated constant to be hidden")
+	assertContains(t, out, "## New", "expected current function to remain")
+}
+
+func TestWriteMarkdown_Notes(t *testing.T) {
+	const input = `
+package testpkg
+
+// Run runs.
+func Run() {}
+
+// BUG(alice): Run ignores its context.
+
+// TODO(bob): support retries.
+`
+
+	docPkg := parseGoDocPackage("testpkg", input)
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out := buf.String()
+	assertContains(t, out, "## Known issues", "missing BUG notes section")
+	assertContains(t, out, "- **alice**: Run ignores its context.", "missing BUG note")
+	assertNotContains(t, out, "support retries", "TODO notes should not render by default")
+
+	buf.Reset()
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{NoteMarkers: []string{"TODO"}}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out = buf.String()
+	assertContains(t, out, "## TODO", "missing TODO notes section")
+	assertNotContains(t, out, "Known issues", "BUG notes should not render when not configured")
+}
+
+func TestWriteMarkdown_NoteLinksRelativeToOutputDir(t *testing.T) {
+	const input = `
+package models
+
+// User is a user.
+type User struct{}
+
+// BUG(alice): User has no name.
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join("models", "user.go"), input, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg := doc.New(&ast.Package{Name: "models", Files: map[string]*ast.File{"user.go": file}}, "./", 0)
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{Fset: fset}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	assertContains(t, buf.String(), "([user.go:7](models/user.go#L7))", "expected link relative to the working directory")
+
+	// As written by --out-dir docs: docs/models/README.md.
+	buf.Reset()
+	cfg := Config{Fset: fset, OutputDir: filepath.Join("docs", "models")}
+	if err := WriteMarkdownWithConfig(docPkg, &buf, cfg); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	assertContains(t, buf.String(), "([user.go:7](../../models/user.go#L7))", "expected link relative to the output directory")
+}
+
+func TestWriteMarkdown_PackageOverview(t *testing.T) {
+	const input = `
+// Package testpkg manages widgets.
+//
+// It is the widget layer.
+package testpkg
+
+// Run runs.
+func Run() {}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "widgets.go", input, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/testpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownWithConfig(docPkg, &buf, Config{}); err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out := buf.String()
+	assertContains(t, out, "<strong>📦 testpkg</strong> — Package testpkg manages widgets.</summary>", "missing synopsis in summary")
+	assertContains(t, out, "import \"example.com/testpkg\"", "missing import snippet")
+	assertContains(t, out, "It is the widget layer.", "missing package doc")
+	assertContains(t, out, "**Files:** `widgets.go`", "missing file list")
+}
+
+func TestWriteMarkdown_AnchorsAndDocLinks(t *testing.T) {
+	const input = `
+package store
+
+import "example.com/models"
+
+// Save stores u. See [Load], [models.User] and [io.Writer].
+func Save(u models.User) {}
+
+// Load loads.
+func Load() {}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "store.go", input, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = WriteMarkdownWithConfig(docPkg, &buf, Config{
+		PackageURL: func(importPath string) (string, bool) {
+			return "", importPath == "example.com/models"
+		},
+	})
+	if err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out := buf.String()
+	assertContains(t, out, `<a id="example-com-store"></a>`, "missing package anchor")
+	assertContains(t, out, `## Save <a id="example-com-store.Save"></a>`, "missing symbol anchor")
+	assertContains(t, out, "[Load](#example-com-store.Load)", "missing same-package doc link")
+	assertContains(t, out, "[models.User](#example-com-models.User)", "missing cross-package doc link")
+	assertContains(t, out, "[io.Writer](https://pkg.go.dev/io#Writer)", "missing standard library doc link")
+}
+
+func TestWriteMarkdown_PlatformBadges(t *testing.T) {
+	const input = `
+package testpkg
+
+// Handle is an OS handle.
+type Handle struct{}
+
+// Fd returns the descriptor.
+func (h *Handle) Fd() int { return 0 }
+
+// Open opens.
+func Open() {}
+
+// Signal numbers.
+const (
+	SIGHUP = 1
+)
+`
+
+	m := parseModel(t, "testpkg", input)
+	m.Types[0].Methods[0].Platforms = []string{"linux/amd64"}
+	m.Consts[0].Platforms = []string{"linux/amd64", "darwin/arm64"}
+
+	out := renderModel(t, m, Config{})
+	assertContains(t, out, "Fd <a id=\"testpkg.Handle.Fd\"></a>\n\n🖥️ **Platforms:** `linux/amd64`\n\n", "missing method badge")
+	assertContains(t, out, "🖥️ **Platforms:** `linux/amd64`, `darwin/arm64`\n\n```go\nconst SIGHUP", "missing constant badge")
+	if n := strings.Count(out, "Platforms:"); n != 2 {
+		t.Errorf("expected 2 badges, got %d:\n%s", n, out)
+	}
+}
+
+func TestWriteModuleHeading(t *testing.T) {
+	var buf bytes.Buffer
+	WriteModuleHeading(&buf, "example.com/api", "1.22")
+	if got, want := buf.String(), "# Module `example.com/api`\n\n**Go version:** 1.22\n\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	WriteModuleHeading(&buf, "example.com/web", "")
+	if got, want := buf.String(), "# Module `example.com/web`\n\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteMarkdown_Errors(t *testing.T) {
+	const input = `
+package store
+
+// Get loads a value.
+func Get() {}
+`
+	m := parseModel(t, "store", input)
+	m.Errors = []errdoc.Error{
+		{Name: "ErrNotFound", Kind: errdoc.KindSentinel, Message: "not found", Doc: "ErrNotFound is returned\nfor missing keys.", WrappedBy: []string{"Get"}},
+		{Name: "ValidationError", Kind: errdoc.KindType, Message: "invalid %s", Pointer: true},
+	}
+
+	out := renderModel(t, m, Config{})
+	assertContains(t, out, "## Errors\n\n| Error | Match with | Message | Description |", "missing errors section")
+	assertContains(t, out, "| `ErrNotFound` | `errors.Is(err, store.ErrNotFound)` | `not found` | ErrNotFound is returned for missing keys. Wrapped by `Get`. |", "missing sentinel row")
+	assertContains(t, out, "| `ValidationError` | `errors.As` with a `*store.ValidationError` | `invalid %s` |  |", "missing error type row")
+}
+
+func TestWriteMarkdown_InterfacesAndImplementations(t *testing.T) {
+	const input = `
+package store
+
+// Store loads values.
+type Store interface {
+	// Get returns the value of key.
+	Get(key string) (value string, err error)
+	io.Closer
+}
+
+// Memory is an in-memory store.
+type Memory struct{}
+`
+	m := parseModel(t, "store", input)
+	m.Types[0].Implements = []implements.Ref{{Name: "Store", Pointer: true}, {Name: "error"}}
+	m.Types[1].ImplementedBy = []implements.Ref{{Name: "Memory", Pointer: true}, {Name: "disk.Store"}}
+
+	out := renderModel(t, m, Config{})
+	assertContains(t, out, "type Store interface {\n\t// Get returns the value of key.\n\tGet(key string) (value string, err error)\n\tio.Closer\n}", "interface should list its method set")
+	assertContains(t, out, "**Implements:** `Store` (as `*Memory`), `error`\n\n", "missing implements line")
+	assertContains(t, out, "**Implemented by:** `*Memory`, `disk.Store`\n\n", "missing implemented by line")
+}
@@ -0,0 +1,20 @@
+package format
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteModuleHeading writes the heading that introduces the packages of a
+// module when a document covers several modules.
+//
+// Parameters:
+//   - out: The writer to output the markdown to
+//   - path: The module path
+//   - goVersion: The version from the module's go directive, omitted when empty
+func WriteModuleHeading(out io.Writer, path, goVersion string) {
+	fmt.Fprintf(out, "# Module `%s`\n\n", path)
+	if goVersion != "" {
+		fmt.Fprintf(out, "**Go version:** %s\n\n", goVersion)
+	}
+}
@@ -0,0 +1,38 @@
+package format
+
+import "path/filepath"
+
+// DefaultNoteMarkers lists the note markers rendered when Config.NoteMarkers is empty.
+var DefaultNoteMarkers = []string{"BUG"}
+
+// noteTitles maps well-known note markers to their section headings. Other
+// markers are shown under their own name.
+var noteTitles = map[string]string{
+	"BUG": "Known issues",
+}
+
+// sourcePath returns the path of a source file relative to the directory of
+// the Markdown file being written (see Config.OutputDir), for use as a link target.
+//
+// Parameters:
+//   - filename: The path of the source file, absolute or relative to the working directory
+//   - cfg: The rendering configuration supplying the output directory
+//
+// Returns:
+//   - string: The slash-separated relative path, or the path unchanged if it
+//     cannot be made relative
+func sourcePath(filename string, cfg Config) string {
+	file, err := filepath.Abs(filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	dir, err := filepath.Abs(cfg.OutputDir)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
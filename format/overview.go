@@ -0,0 +1,18 @@
+package format
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isImportPath reports whether a package's import path is a real import path
+// rather than the filesystem directory it was loaded from.
+//
+// Parameters:
+//   - importPath: The import path recorded on the documentation package
+//
+// Returns:
+//   - bool: True if the path can be used in an import statement
+func isImportPath(importPath string) bool {
+	return importPath != "" && !strings.HasPrefix(importPath, ".") && !filepath.IsAbs(importPath)
+}
@@ -0,0 +1,127 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// Styles of the block a TagRenderer writes.
+const (
+	// TagStyleJSON lists the tag names as the keys of a JSON object.
+	TagStyleJSON = "json"
+
+	// TagStyleDynamoDB lists the tag names with the DynamoDB attribute type
+	// inferred from each field's Go type.
+	TagStyleDynamoDB = "dynamodb"
+
+	// TagStyleList lists one tag name per line.
+	TagStyleList = "list"
+)
+
+// TagRenderer describes a block listing the names a struct tag gives the
+// fields of a struct, rendered after the struct's documentation.
+type TagRenderer struct {
+	// Tag is the struct tag key, e.g. "json" or "bson".
+	Tag string
+
+	// Title is the block heading. Defaults to Tag.
+	Title string
+
+	// Style is TagStyleJSON, TagStyleDynamoDB or TagStyleList (the default).
+	Style string
+}
+
+// DefaultTagRenderers are the tag blocks rendered when Config.TagRenderers is nil.
+var DefaultTagRenderers = []TagRenderer{
+	{Tag: "json", Title: "JSON", Style: TagStyleJSON},
+	{Tag: "dynamodbav", Title: "DynamoDB", Style: TagStyleDynamoDB},
+}
+
+// tagRenderers returns the configured tag renderers, or the defaults.
+//
+// Parameters:
+//   - cfg: The rendering configuration
+//
+// Returns:
+//   - []TagRenderer: The renderers to apply to each struct, in order
+func tagRenderers(cfg Config) []TagRenderer {
+	if cfg.TagRenderers == nil {
+		return DefaultTagRenderers
+	}
+	return cfg.TagRenderers
+}
+
+// taggedField is a struct field named by a struct tag.
+type taggedField struct {
+	Name string // the name from the tag, without options such as ",omitempty"
+	Type string // the Go type of the field
+}
+
+// renderTagBlock renders the fields named by a renderer's tag as a Markdown block.
+//
+// Parameters:
+//   - r: The tag renderer
+//   - fields: The fields carrying the tag
+//
+// Returns:
+//   - string: The block including its heading, or an empty string if no field has the tag
+func renderTagBlock(r TagRenderer, fields []taggedField) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	title := r.Title
+	if title == "" {
+		title = r.Tag
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#### %s\n\n", title)
+	switch r.Style {
+	case TagStyleJSON:
+		b.WriteString("```json\n{\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "  %q,\n", f.Name)
+		}
+		b.WriteString("}\n```\n")
+	case TagStyleDynamoDB:
+		b.WriteString("```sql\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "%-25s %s\n", f.Name, mapGoTypeToDynamoType(f.Type))
+		}
+		b.WriteString("```\n\n")
+	default:
+		b.WriteString("```text\n")
+		for _, f := range fields {
+			b.WriteString(f.Name + "\n")
+		}
+		b.WriteString("```\n\n")
+	}
+	return b.String()
+}
+
+// modelTagBlocks renders every configured tag block for the fields of a model
+// struct, as used by the template renderer.
+//
+// Parameters:
+//   - fields: The struct fields
+//   - cfg: The rendering configuration
+//
+// Returns:
+//   - []string: The non-empty tag blocks in renderer order
+func modelTagBlocks(fields []model.Field, cfg Config) []string {
+	var blocks []string
+	for _, r := range tagRenderers(cfg) {
+		var tagged []taggedField
+		for _, f := range fields {
+			if name := tagName(f, r.Tag); name != "" && !f.Embedded {
+				tagged = append(tagged, taggedField{Name: name, Type: f.Type})
+			}
+		}
+		if block := renderTagBlock(r, tagged); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,53 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown_TagRenderers(t *testing.T) {
+	const input = `
+package testpkg
+
+// Account is stored in MongoDB.
+type Account struct {
+	ID    string ` + "`json:\"id\" bson:\"_id\"`" + `
+	Email string ` + "`bson:\"email,omitempty\" dynamodbav:\"email\"`" + `
+}
+`
+	renderers := []TagRenderer{{Tag: "bson", Title: "MongoDB"}}
+
+	var buf bytes.Buffer
+	err := WriteMarkdownWithConfig(parseGoDocPackage("testpkg", input), &buf, Config{TagRenderers: renderers})
+	if err != nil {
+		t.Fatalf("WriteMarkdownWithConfig failed: %v", err)
+	}
+	out := buf.String()
+	assertContains(t, out, "#### MongoDB\n\n```text\n_id\nemail\n```\n", "missing bson block")
+	assertNotContains(t, out, "#### JSON", "default json block should be replaced")
+	assertNotContains(t, out, "#### DynamoDB", "default dynamodb block should be replaced")
+
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	pkg := templateFixture()
+	pkg.Types[0].Fields[0].Tags["bson"] = "_id"
+
+	buf.Reset()
+	if err := WriteMarkdownTemplate(pkg, &buf, tmpl, Config{TagRenderers: renderers}); err != nil {
+		t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+	}
+	out = buf.String()
+	assertContains(t, out, "#### MongoDB\n\n```text\n_id\n```\n", "missing bson block in template output")
+	assertNotContains(t, out, "#### JSON", "default json block should be replaced in template output")
+
+	buf.Reset()
+	if err := WriteMarkdownTemplate(pkg, &buf, tmpl, Config{TagRenderers: []TagRenderer{}}); err != nil {
+		t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "####") {
+		t.Errorf("expected no tag blocks with an empty renderer list\n%s", buf.String())
+	}
+}
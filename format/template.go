@@ -0,0 +1,370 @@
+package format
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+// builtinTemplates holds the default Markdown templates. Each file defines the
+// template named after it without the .tmpl extension.
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateEntry is the name of the template executed for each package.
+const TemplateEntry = "package"
+
+// LoadTemplates parses the built-in Markdown templates and then the *.tmpl files
+// in dir, which replace the built-in template of the same name or add new
+// partials. Templates are named after their file without the extension, e.g.
+// "package", "type", "func", "field", "value", "errors", "example" and
+// "deprecation".
+//
+// Parameters:
+//   - dir: The directory containing user templates, or an empty string for the built-ins only
+//
+// Returns:
+//   - *template.Template: The template set whose "package" template renders a package
+//   - error: An error if a template cannot be read or parsed
+func LoadTemplates(dir string) (*template.Template, error) {
+	root := template.New("markdown").Funcs(templateFuncs(nil, nil))
+
+	builtins, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range builtins {
+		text, err := builtinTemplates.ReadFile(path.Join("templates", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := parseTemplate(root, entry.Name(), string(text)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return root, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.tmpl files found in %s", dir)
+	}
+	for _, file := range files {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseTemplate(root, filepath.Base(file), string(text)); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// parseTemplate adds a template file to the set under its name without extension.
+//
+// Parameters:
+//   - root: The template set
+//   - file: The file name, e.g. "type.tmpl"
+//   - text: The template source
+//
+// Returns:
+//   - error: An error if the template cannot be parsed
+func parseTemplate(root *template.Template, file, text string) error {
+	name := strings.TrimSuffix(file, ".tmpl")
+	if _, err := root.New(name).Parse(text); err != nil {
+		return fmt.Errorf("parsing template %s: %w", file, err)
+	}
+	return nil
+}
+
+// WriteMarkdownTemplate renders a package model as Markdown by executing the
+// "package" template of a set returned by LoadTemplates.
+//
+// Parameters:
+//   - pkg: The package model to render
+//   - out: The writer to output the markdown to
+//   - tmpl: The template set
+//   - cfg: The rendering configuration supplying note markers, package URLs and example failures
+//
+// Returns:
+//   - error: An error if the template fails to execute
+func WriteMarkdownTemplate(pkg *model.Package, out io.Writer, tmpl *template.Template, cfg Config) error {
+	if len(pkg.Funcs)+len(pkg.Types)+len(pkg.Consts) == 0 {
+		return nil
+	}
+	cfg.links = newModelDocLinker(pkg, cfg)
+
+	t, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	t.Funcs(templateFuncs(pkg, &cfg))
+	if err := t.ExecuteTemplate(out, TemplateEntry, pkg); err != nil {
+		return fmt.Errorf("rendering %s: %w", modelKey(pkg), err)
+	}
+	return nil
+}
+
+// templateField is the data passed to the "field" template: a struct field
+// with its name and type padded to line up with the other fields.
+type templateField struct {
+	model.Field
+	NameColumn string
+	TypeColumn string
+}
+
+// templateNotes is a group of notes sharing a marker, as returned by the "notes" function.
+type templateNotes struct {
+	Marker string
+	Title  string
+	Notes  []model.Note
+}
+
+// templateFuncs returns the functions available to Markdown templates, bound to
+// the package being rendered. LoadTemplates registers them with a nil package
+// so that templates can be parsed before any package is known.
+//
+// Parameters:
+//   - pkg: The package being rendered, or nil while parsing
+//   - cfg: The rendering configuration, or nil while parsing
+//
+// Returns:
+//   - template.FuncMap: The template functions
+func templateFuncs(pkg *model.Package, cfg *Config) template.FuncMap {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	key := ""
+	if pkg != nil {
+		key = modelKey(pkg)
+	}
+
+	return template.FuncMap{
+		"anchor": func(symbol string) string {
+			return anchorTag(Anchor(key, symbol))
+		},
+		"doc": func(text string) string {
+			return cfg.links.linkify(formatDocComment(text))
+		},
+		"heading": func(name string, deprecated bool) string {
+			if deprecated {
+				return "~~" + name + "~~"
+			}
+			return name
+		},
+		"importable": isImportPath,
+		"columns":    templateColumns,
+		"deprecatedFields": func(fields []model.Field) []model.Field {
+			var out []model.Field
+			for _, f := range fields {
+				if f.Deprecated {
+					out = append(out, f)
+				}
+			}
+			return out
+		},
+		"deprecatedSpecs": func(specs []model.ValueSpec) []model.ValueSpec {
+			var out []model.ValueSpec
+			for _, s := range specs {
+				if s.Deprecated {
+					out = append(out, s)
+				}
+			}
+			return out
+		},
+		"tagged": func(fields []model.Field, key string) []model.Field {
+			var out []model.Field
+			for _, f := range fields {
+				if !f.Embedded && tagName(f, key) != "" {
+					out = append(out, f)
+				}
+			}
+			return out
+		},
+		"spec": templateSpec,
+		"comment": func(text, indent string) string {
+			var b strings.Builder
+			for _, line := range strings.Split(text, "\n") {
+				b.WriteString(indent + strings.TrimRight("// "+line, " ") + "\n")
+			}
+			return b.String()
+		},
+		"tagName": tagName,
+		"tagBlocks": func(fields []model.Field) []string {
+			return modelTagBlocks(fields, *cfg)
+		},
+		"dynamoType": mapGoTypeToDynamoType,
+		"notes": func(p *model.Package) []templateNotes {
+			return templateNoteGroups(p, cfg.NoteMarkers)
+		},
+		"failed": func(name string) bool {
+			_, ok := cfg.ExampleFailures["Example"+name]
+			return ok
+		},
+		"failure": func(name string) string {
+			return cfg.ExampleFailures["Example"+name]
+		},
+		"quote": func(text string) string {
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				lines[i] = "> " + line
+			}
+			return strings.Join(lines, "\n")
+		},
+		"oneLine": func(text string) string {
+			return strings.Join(strings.Fields(text), " ")
+		},
+		"source": func(file string) string {
+			return sourcePath(filepath.FromSlash(file), *cfg)
+		},
+		"errorRow":        errorRow,
+		"implementations": modelImplementations,
+		"base":            path.Base,
+		"join":            strings.Join,
+		"trimRight":       strings.TrimRight,
+	}
+}
+
+// templateColumns pads the names and types of the named fields of a struct so
+// that the "field" template renders them aligned. Embedded fields are skipped.
+//
+// Parameters:
+//   - fields: The struct fields
+//
+// Returns:
+//   - []templateField: The fields with padded name and type columns
+func templateColumns(fields []model.Field) []templateField {
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		if f.Embedded {
+			continue
+		}
+		nameWidth = max(nameWidth, len(f.Name))
+		typeWidth = max(typeWidth, len(f.Type))
+	}
+
+	var out []templateField
+	for _, f := range fields {
+		if f.Embedded {
+			continue
+		}
+		out = append(out, templateField{
+			Field:      f,
+			NameColumn: fmt.Sprintf("%-*s", nameWidth, f.Name),
+			TypeColumn: fmt.Sprintf("%-*s", typeWidth, f.Type),
+		})
+	}
+	return out
+}
+
+// templateSpec renders a const or var spec without its keyword, as it would
+// appear inside a declaration group.
+//
+// Parameters:
+//   - s: The value spec
+//
+// Returns:
+//   - string: The spec with its names, type, values and first line comment
+func templateSpec(s model.ValueSpec) string {
+	line := strings.Join(s.Names, ", ")
+	if s.Type != "" {
+		line += " " + s.Type
+	}
+	if len(s.Values) > 0 {
+		line += " = " + strings.Join(s.Values, ", ")
+	}
+	if comment, _, _ := strings.Cut(s.Comment, "\n"); comment != "" {
+		line += " // " + comment
+	}
+	return line
+}
+
+// tagName returns the name part of a struct tag value, e.g. "id" for `json:"id,omitempty"`.
+//
+// Parameters:
+//   - f: The struct field
+//   - key: The tag key, e.g. "json"
+//
+// Returns:
+//   - string: The name part of the tag, or an empty string if the tag is absent
+func tagName(f model.Field, key string) string {
+	name, _, _ := strings.Cut(f.Tags[key], ",")
+	return name
+}
+
+// templateNoteGroups groups a package's notes by the configured markers, in marker order.
+//
+// Parameters:
+//   - p: The package model
+//   - markers: The note markers to include, or nil for DefaultNoteMarkers
+//
+// Returns:
+//   - []templateNotes: One group per marker that has notes
+func templateNoteGroups(p *model.Package, markers []string) []templateNotes {
+	if len(markers) == 0 {
+		markers = DefaultNoteMarkers
+	}
+	var out []templateNotes
+	for _, marker := range markers {
+		group := templateNotes{Marker: marker, Title: marker}
+		if title, ok := noteTitles[marker]; ok {
+			group.Title = title
+		}
+		for _, n := range p.Notes {
+			if n.Marker == marker {
+				group.Notes = append(group.Notes, n)
+			}
+		}
+		if len(group.Notes) > 0 {
+			out = append(out, group)
+		}
+	}
+	return out
+}
+
+// newModelDocLinker indexes the symbols and imports of a package model for
+// resolving its doc links.
+//
+// Parameters:
+//   - pkg: The package model whose comments will be linked
+//   - cfg: The rendering configuration supplying PackageURL
+//
+// Returns:
+//   - *docLinker: The linker for the package
+func newModelDocLinker(pkg *model.Package, cfg Config) *docLinker {
+	l := &docLinker{
+		importPath: modelKey(pkg),
+		symbols:    map[string]bool{},
+		imports:    map[string]string{},
+		packageURL: cfg.PackageURL,
+	}
+	for _, f := range pkg.Funcs {
+		l.symbols[f.Name] = true
+	}
+	for _, t := range pkg.Types {
+		l.symbols[t.Name] = true
+		for _, f := range t.Funcs {
+			l.symbols[f.Name] = true
+		}
+		for _, m := range t.Methods {
+			l.symbols[t.Name+"."+m.Name] = true
+		}
+	}
+	for _, imp := range pkg.Imports {
+		l.imports[importName(imp)] = imp
+	}
+	return l
+}
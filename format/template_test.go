@@ -0,0 +1,161 @@
+package format
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/model"
+	"github.com/thinktide/godocmd/parse"
+	"github.com/thinktide/godocmd/textdiff"
+)
+
+func templateFixture() *model.Package {
+	return &model.Package{
+		Name:       "models",
+		ImportPath: "example.com/app/models",
+		Synopsis:   "Package models defines the data types.",
+		Doc:        "Package models defines the data types.",
+		Files:      []string{"user.go"},
+		Types: []model.Type{{
+			Name: "User",
+			Kind: "struct",
+			Doc:  "User is an account. See [User.Save].",
+			Fields: []model.Field{
+				{Name: "ID", Type: "string", Comment: "primary key", Tags: model.ParseTag(`json:"id" dynamodbav:"pk"`)},
+				{Name: "Nick", Type: "string", Deprecated: true, Deprecation: "use Name."},
+			},
+			Methods: []model.Func{{
+				Name: "Save",
+				Recv: "User",
+				Decl: "func (u *User) Save() error",
+				Doc:  "Save persists the user.",
+			}},
+		}},
+	}
+}
+
+func TestWriteMarkdownTemplate_Builtin(t *testing.T) {
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownTemplate(templateFixture(), &buf, tmpl, Config{}); err != nil {
+		t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<summary><strong>📦 models</strong> — Package models defines the data types.</summary>",
+		"import \"example.com/app/models\"",
+		"## User <a id=\"example-com-app-models.User\"></a>",
+		"type User struct {\n    ID   string // primary key\n    Nick string\n}",
+		"[User.Save](#example-com-app-models.User.Save)",
+		"> **Deprecated:** `Nick` — use Name.",
+		"```json\n{\n  \"id\",\n}\n```",
+		"pk                        String",
+		"## <small><em>User.</em></small>Save <a id=\"example-com-app-models.User.Save\"></a>",
+		"</details>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdownTemplate_Override(t *testing.T) {
+	dir := t.TempDir()
+	override := "{{ range .Fields }}- `{{ .Name }}` {{ .Type }}\n{{ end }}"
+	if err := os.WriteFile(filepath.Join(dir, "type.tmpl"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownTemplate(templateFixture(), &buf, tmpl, Config{}); err != nil {
+		t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "- `ID` string\n- `Nick` string\n") {
+		t.Errorf("expected overridden type template output\n%s", out)
+	}
+	if strings.Contains(out, "type User struct") {
+		t.Errorf("expected built-in type template to be replaced\n%s", out)
+	}
+	if !strings.Contains(out, "📦 models") {
+		t.Errorf("expected built-in package template to be kept\n%s", out)
+	}
+}
+
+func TestLoadTemplates_Errors(t *testing.T) {
+	if _, err := LoadTemplates(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without templates")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "func.tmpl"), []byte("{{ .Name "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "func.tmpl") {
+		t.Errorf("expected a parse error naming func.tmpl, got %v", err)
+	}
+}
+
+func TestWriteMarkdownTemplate_Golden(t *testing.T) {
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	var all []*parse.Package
+	for _, dir := range []string{"models", "store"} {
+		pkgs, err := parse.LoadAll(filepath.Join("testdata", "golden", dir), parse.BuildOptions{})
+		if err != nil {
+			t.Fatalf("LoadAll(%s) failed: %v", dir, err)
+		}
+		all = append(all, pkgs[0])
+	}
+
+	// Each variant is compared against testdata/golden/<package>.<variant>.md.
+	variants := []struct {
+		name string
+		opts model.Options
+	}{
+		{"default", model.Options{}},
+		{"undocumented", model.Options{IncludeUndocumented: true}},
+		{"private", model.Options{IncludePrivate: true, IncludeUndocumented: true}},
+		{"hide-deprecated", model.Options{IncludeUndocumented: true, HideDeprecated: true}},
+	}
+	for _, v := range variants {
+		v.opts.Implementations = implements.Compute(all, v.opts.IncludePrivate)
+		for _, pkg := range all {
+			cfg := Config{
+				Fset:            pkg.Fset,
+				ExampleFailures: map[string]string{"ExampleUser_Save_twice": "got:\n<nil>\nwant:\nnil"},
+			}
+			var got bytes.Buffer
+			if err := WriteMarkdownTemplate(model.Build(pkg, v.opts), &got, tmpl, cfg); err != nil {
+				t.Fatalf("WriteMarkdownTemplate failed: %v", err)
+			}
+
+			file := filepath.Join("testdata", "golden", pkg.Doc.Name+"."+v.name+".md")
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := textdiff.Unified(file, "template", string(want), got.String()); diff != "" {
+				t.Errorf("%s: built-in templates differ from the golden output\n%s", file, diff)
+			}
+		}
+	}
+}
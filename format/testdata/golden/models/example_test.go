@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"fmt"
+
+	"example.com/golden/models"
+)
+
+func ExampleNewUser() {
+	u := models.NewUser("ann")
+	fmt.Println(u.Name)
+	// Output: ann
+}
+
+// Saving a user.
+func ExampleUser_Save() {
+	u := models.NewUser("bob")
+	fmt.Println(u.Save())
+	// Output: <nil>
+}
+
+func ExampleUser_Save_twice() {
+	u := models.NewUser("bob")
+	u.Save()
+	fmt.Println(u.Save())
+	// Output: <nil>
+}
+
+func Example() {
+	fmt.Println("models")
+	// Output: models
+}
@@ -0,0 +1,113 @@
+// Package models defines the data types of the application.
+//
+// Every model can be stored with [store.Store].
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Role is the access level of a user.
+type Role int
+
+// The roles, from least to most privileged.
+const (
+	// Guest can only read.
+	Guest  Role = iota
+	Member      // Member can write.
+	Admin       // Admin can do anything.
+	root        // root is reserved.
+)
+
+// MaxNameLength is the longest accepted user name.
+const MaxNameLength = 64
+
+// Default settings.
+var (
+	DefaultRole = Member
+	timeout     = time.Second
+)
+
+// ErrNotFound is returned when a user does not exist.
+var ErrNotFound = errors.New("models: user not found")
+
+// ValidationError reports an invalid field.
+type ValidationError struct {
+	Field string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string { return "invalid " + e.Field }
+
+// Version is a semantic version.
+type Version struct {
+	Major, Minor int // the release numbers
+	Patch        int
+	label        string
+}
+
+// User is an account. See [User.Save] and [Role].
+type User struct {
+	// ID is the primary key.
+	ID   string `json:"id" dynamodbav:"pk"`
+	Name string `json:"name,omitempty" dynamodbav:"name"` // display name
+	Age  int    `json:"age" dynamodbav:"age"`
+	Role
+	Tags []string `json:"tags"`
+
+	// Deprecated: use Name.
+	Nick     string `json:"nick"`
+	password string
+	Created  time.Time
+}
+
+// NewUser creates a user with the default role.
+func NewUser(name string) *User {
+	return &User{Name: name, Role: DefaultRole}
+}
+
+// Parse parses a user from "name:age".
+//
+// Deprecated: use [NewUser].
+func Parse(s string) (*User, error) {
+	return nil, fmt.Errorf("parse %q: %w", s, ErrNotFound)
+}
+
+// Save persists the user.
+func (u *User) Save() error { return nil }
+
+// validate checks the fields.
+func (u *User) validate() error { return nil }
+
+// Undocumented has no doc.
+func (u User) String() string { return u.Name }
+
+func helper() {}
+
+// Greeter greets.
+type Greeter interface {
+	// Greet returns a greeting.
+	Greet(name string) string
+	fmt.Stringer
+}
+
+// Pair holds two values.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Map converts values.
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+// Old is an old type.
+//
+// Deprecated: use User.
+type Old struct{}
+
+// Handler handles events.
+type Handler func(event string) error
+
+// BUG(alice): Save ignores the context.
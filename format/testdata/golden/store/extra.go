@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"io"
+)
+
+// Reader is an alias.
+type Reader = io.Reader
+
+// Codec encodes values.
+type Codec interface {
+	// Encode writes v.
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) (n int, err error)
+}
+
+// Events is a channel of events.
+type Events <-chan string
+
+// Matrix is a fixed grid.
+type Matrix [3][3]float64
+
+// Options configures a Store.
+type Options struct {
+	Timeout, Retries int /* attempts */
+	*Store
+	io.Closer
+	Hook func(ctx context.Context, key string) (bool, error) `json:"-"`
+	Meta map[string][]byte                                   `json:"meta,omitempty"`
+}
+
+// Open opens a store.
+func Open(ctx context.Context,
+	name string,
+	opts ...Options) (s *Store, err error) {
+	return nil, nil
+}
+
+// Put stores a value.
+func (s *Store) Put(a, b string, n int) {}
+
+// Limits.
+var (
+	// MaxKeys bounds the store.
+	MaxKeys = 100
+	minKeys = 1
+)
+
+// TODO(bob): add TTLs.
+
+// List is a generic list.
+type List[T any] []T
+
+// Len returns the length.
+func (l List[T]) Len() int { return len(l) }
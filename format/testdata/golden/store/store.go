@@ -0,0 +1,24 @@
+// Package store persists models.
+package store
+
+import "example.com/golden/models"
+
+// Store saves users.
+type Store struct {
+	users map[string]*models.User
+}
+
+// New returns an empty store.
+func New() *Store { return &Store{} }
+
+// Get returns a user or models.ErrNotFound.
+func (s *Store) Get(id string) (*models.User, error) { return nil, models.ErrNotFound }
+
+// Kind of store.
+type Kind string
+
+// Kinds.
+const (
+	Memory Kind = "memory"
+	Disk   Kind = "disk"
+)
@@ -2,7 +2,11 @@ module github.com/thinktide/godocmd
 
 go 1.23
 
-require github.com/urfave/cli/v2 v2.27.6
+require (
+	github.com/bmatcuk/doublestar/v4 v4.10.0
+	github.com/urfave/cli/v2 v2.27.6
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
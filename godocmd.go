@@ -1,17 +1,130 @@
 package godocmd
 
 import (
+	"bytes"
 	"fmt"
+	"go/doc"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 
+	"github.com/thinktide/godocmd/apidiff"
+	"github.com/thinktide/godocmd/coverage"
+	"github.com/thinktide/godocmd/discover"
 	"github.com/thinktide/godocmd/enums"
+	"github.com/thinktide/godocmd/envvars"
 	"github.com/thinktide/godocmd/format"
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/lint"
+	"github.com/thinktide/godocmd/model"
+	"github.com/thinktide/godocmd/modules"
 	"github.com/thinktide/godocmd/parse"
+	"github.com/thinktide/godocmd/semver"
+	"github.com/thinktide/godocmd/vcs"
+	"github.com/thinktide/godocmd/verify"
 )
 
+// Options controls how GenerateMarkdownWithOptions discovers packages and renders them.
+// The zero value documents only the root directory's exported, documented symbols.
+type Options struct {
+	// Recursive scans all subdirectories of the root for Go packages.
+	Recursive bool
+
+	// IncludePrivate includes non-exported (private) symbols.
+	IncludePrivate bool
+
+	// IncludeUndocumented includes symbols that lack GoDoc comments.
+	IncludeUndocumented bool
+
+	// Verbose logs each step to stderr.
+	Verbose bool
+
+	// VerifyExamples runs example tests and flags examples whose output does not match.
+	VerifyExamples bool
+
+	// HideDeprecated omits symbols marked with a "Deprecated:" paragraph.
+	HideDeprecated bool
+
+	// NoteMarkers lists the note markers (e.g. "BUG", "TODO") rendered in each
+	// package's notes section. Defaults to format.DefaultNoteMarkers when empty.
+	NoteMarkers []string
+
+	// TemplateDir is a directory of text/template files that replace the built-in
+	// Markdown templates of the same name (see format.LoadTemplates). When empty,
+	// Markdown is rendered with the built-in templates.
+	TemplateDir string
+
+	// TagRenderers lists the struct tag blocks rendered after each struct in
+	// Markdown. Defaults to format.DefaultTagRenderers when nil.
+	TagRenderers []format.TagRenderer
+
+	// OutputDir is the directory of the Markdown file GenerateMarkdownWithOptions
+	// writes to. Links to source files, such as those of notes, are relative to
+	// it. Defaults to the working directory.
+	OutputDir string
+
+	// Include restricts recursive discovery to package directories matching at
+	// least one of these patterns (see discover.Match and PatternDir). All
+	// packages are included when it is empty.
+	Include []string
+
+	// Exclude skips directories matching any of these patterns, along with
+	// everything below them.
+	Exclude []string
+
+	// ExcludeInternal skips internal packages when scanning recursively.
+	ExcludeInternal bool
+
+	// NoDefaultExcludes scans the directories in discover.DefaultExclude, such
+	// as vendor and testdata, which are skipped by default.
+	NoDefaultExcludes bool
+
+	// NoGitignore scans directories ignored by .gitignore files, which are
+	// skipped by default.
+	NoGitignore bool
+
+	// GOOS, GOARCH and BuildTags select the files of each package by their build
+	// constraints. GOOS and GOARCH default to the current platform, or to the
+	// GOOS and GOARCH environment variables.
+	GOOS      string
+	GOARCH    string
+	BuildTags []string
+
+	// Platforms lists further "goos/goarch" platforms whose symbols are documented
+	// as well, with a badge on each symbol that is not declared on every platform.
+	Platforms []string
+
+	// AllPackages documents every package of a directory, such as a main
+	// package next to a library, instead of only its primary package (see
+	// parse.LoadAll).
+	AllPackages bool
+
+	// SkipMain skips main packages. A directory whose primary package is main
+	// falls back to its next package when AllPackages is not set.
+	SkipMain bool
+
+	// ExternalTests also documents external test packages (package foo_test)
+	// as packages of their own. Their examples are always attached to the
+	// package they test.
+	ExternalTests bool
+
+	// Module restricts generation to the packages of the module with this path,
+	// e.g. one module of a go.work workspace or a nested module.
+	Module string
+
+	// Packages overrides the visibility options of the packages matching each
+	// pattern. When several match, later entries take precedence; a
+	// configuration file lists its patterns from the least to the most specific.
+	Packages []PackageOptions
+
+	// PatternDir is the directory Include, Exclude and Packages patterns are
+	// relative to. Defaults to the scanned root directory.
+	PatternDir string
+}
+
 // GenerateMarkdown recursively walks the provided directory and writes
 // markdown documentation for each Go package to the given writer.
 //
@@ -26,98 +139,1049 @@ import (
 // Returns:
 //   - error: Any error encountered during parsing or output
 func GenerateMarkdown(rootDir string, out io.Writer, flags ...enums.MarkdownFlag) error {
-	cfg := struct {
-		recursive           bool
-		includePrivate      bool
-		includeUndocumented bool
-		verbose             bool
-	}{}
+	return GenerateMarkdownWithOptions(rootDir, out, OptionsFromFlags(flags...))
+}
 
+// OptionsFromFlags converts enums.MarkdownFlag values into the equivalent Options.
+//
+// Parameters:
+//   - flags: One or more enums.MarkdownFlag values
+//
+// Returns:
+//   - Options: The options with each flag's setting enabled
+func OptionsFromFlags(flags ...enums.MarkdownFlag) Options {
+	var opts Options
 	for _, flag := range flags {
 		switch flag {
 		case enums.Recursive:
-			cfg.recursive = true
+			opts.Recursive = true
 		case enums.IncludePrivate:
-			cfg.includePrivate = true
+			opts.IncludePrivate = true
 		case enums.IncludeUndocumented:
-			cfg.includeUndocumented = true
+			opts.IncludeUndocumented = true
 		case enums.Verbose:
-			cfg.verbose = true
+			opts.Verbose = true
+		case enums.VerifyExamples:
+			opts.VerifyExamples = true
+		case enums.HideDeprecated:
+			opts.HideDeprecated = true
 		}
 	}
+	return opts
+}
 
-	dirs := []string{rootDir}
-	if cfg.recursive {
-		var err error
-		dirs, err = collectGoPackageDirs(rootDir)
+// GenerateMarkdownWithOptions walks the provided directory and writes markdown
+// documentation for each Go package to the given writer, as described by opts.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - out: The writer to output markdown to (e.g., os.Stdout or a file)
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - error: Any error encountered during parsing or output
+func GenerateMarkdownWithOptions(rootDir string, out io.Writer, opts Options) error {
+	dirs, err := packageDirs(rootDir, opts)
+	if err != nil {
+		return err
+	}
+
+	// Doc links to any scanned package resolve to its section of this document.
+	documented := map[string]bool{}
+	for _, dir := range dirs {
+		if importPath, err := modules.ImportPath(dir); err == nil {
+			documented[importPath] = true
+		}
+	}
+	packageURL := func(importPath string) (string, bool) {
+		return "", documented[importPath]
+	}
+
+	groups, err := modules.GroupDirs(dirs)
+	if err != nil {
+		return err
+	}
+	r, err := newMarkdownRenderer(rootDir, dirs, opts)
+	if err != nil {
+		return err
+	}
+	defer r.close()
+	for _, g := range groups {
+		// Packages are introduced by their module once the document spans several.
+		var section bytes.Buffer
+		for _, dir := range g.Dirs {
+			var buf bytes.Buffer
+			pkgs, err := r.render(dir, opts.OutputDir, &buf, packageURL)
+			if err != nil {
+				return err
+			}
+			if len(pkgs) == 0 {
+				continue
+			}
+			fmt.Fprintf(&section, "<!-- %s -->\n\n", dir)
+			buf.WriteTo(&section)
+		}
+		if section.Len() == 0 {
+			continue
+		}
+		if len(groups) > 1 && g.Module != nil {
+			format.WriteModuleHeading(out, g.Module.Path, g.Module.GoVersion)
+		}
+		if _, err := section.WriteTo(out); err != nil {
+			return err
+		}
+	}
+	return r.err()
+}
+
+// InjectMarkdown generates markdown documentation as GenerateMarkdownWithOptions
+// does and writes it into an existing Markdown file, replacing only the content
+// between its format.InjectStart and format.InjectEnd markers. The file is left
+// untouched when its generated section is already up to date.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - file: The Markdown file containing the markers, e.g. a package's README.md
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - error: Any error encountered during parsing, or if the file has no valid markers
+func InjectMarkdown(rootDir, file string, opts Options) error {
+	existing, updated, err := injectedMarkdown(rootDir, file, opts)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, existing) {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "✅ %s is up to date\n", file)
+		}
+		return nil
+	}
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "📝 Updating %s\n", file)
+	}
+	return os.WriteFile(file, updated, 0644)
+}
+
+// injectedMarkdown renders the content InjectMarkdown writes to a file in memory.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - file: The Markdown file containing the markers
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - []byte: The current content of the file
+//   - []byte: The content with the generated section replaced
+//   - error: Any error encountered during parsing, or if the file has no valid markers
+func injectedMarkdown(rootDir, file string, opts Options) ([]byte, []byte, error) {
+	existing, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var generated bytes.Buffer
+	opts.OutputDir = filepath.Dir(file)
+	if err := GenerateMarkdownWithOptions(rootDir, &generated, opts); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := format.Inject(string(existing), generated.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return existing, []byte(updated), nil
+}
+
+// GenerateMarkdownFiles walks the provided directory and writes one markdown
+// file per Go package to outDir, mirroring the package directory tree (e.g.
+// "models" is written to "<outDir>/models/README.md"). An index linking every
+// package is written to "<outDir>/README.md", followed by the documentation of
+// the root package if rootDir is one. Doc links between packages become
+// relative links between their files.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - outDir: The directory to write the markdown files to
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - error: Any error encountered during parsing or output
+func GenerateMarkdownFiles(rootDir, outDir string, opts Options) error {
+	files, err := markdownFiles(rootDir, outDir, opts)
+	if files == nil {
+		return err
+	}
+	for _, file := range sortedKeys(files) {
+		path := filepath.Join(outDir, file)
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "📝 Writing %s\n", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[file], 0644); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// markdownFiles renders the files written by GenerateMarkdownFiles in memory.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - outDir: The directory the files will be written to
+//   - opts: The discovery and rendering options to apply
+//
+// Returns:
+//   - map[string][]byte: The content of each file by its path relative to the
+//     output directory, or nil if rendering failed
+//   - error: Any error encountered; when only examples failed verification the
+//     files are returned as well
+func markdownFiles(rootDir, outDir string, opts Options) (map[string][]byte, error) {
+	dirs, err := packageDirs(rootDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each package's file, relative to the output directory, by import path.
+	files := map[string]string{}
+	for _, dir := range dirs {
+		importPath, err := modules.ImportPath(dir)
 		if err != nil {
-			return fmt.Errorf("collecting package dirs: %w", err)
+			continue
+		}
+		files[importPath] = markdownFileFor(rootDir, dir)
+	}
+
+	r, err := newMarkdownRenderer(rootDir, dirs, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	groups, err := modules.GroupDirs(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{}
+	var index, root bytes.Buffer
+	fmt.Fprintf(&index, "# API Documentation\n\n")
+	listed := false
+	for _, g := range groups {
+		// The index lists the packages under their module once it spans several.
+		var entries bytes.Buffer
+		for _, dir := range g.Dirs {
+			file := markdownFileFor(rootDir, dir)
+			packageURL := func(importPath string) (string, bool) {
+				target, ok := files[importPath]
+				if !ok {
+					return "", false
+				}
+				rel, err := filepath.Rel(filepath.Dir(file), target)
+				if err != nil {
+					return "", false
+				}
+				return filepath.ToSlash(rel), true
+			}
+
+			var buf bytes.Buffer
+			pkgs, err := r.render(dir, filepath.Join(outDir, filepath.Dir(file)), &buf, packageURL)
+			if err != nil {
+				return nil, err
+			}
+			if len(pkgs) == 0 || buf.Len() == 0 {
+				continue
+			}
+
+			for _, pkg := range pkgs {
+				line := fmt.Sprintf("- [%s](%s)", packageTitle(pkg), filepath.ToSlash(file))
+				if synopsis := pkg.Doc.Synopsis(pkg.Doc.Doc); synopsis != "" {
+					line += " — " + synopsis
+				}
+				fmt.Fprintln(&entries, line)
+			}
+
+			if file == markdownIndexFile {
+				fmt.Fprintf(&root, "\n<!-- %s -->\n\n", dir)
+				buf.WriteTo(&root)
+				continue
+			}
+			out[file] = append([]byte(fmt.Sprintf("<!-- %s -->\n\n", dir)), buf.Bytes()...)
+		}
+		if entries.Len() == 0 {
+			continue
 		}
-		if cfg.verbose {
-			fmt.Fprintf(os.Stderr, "🔍 Found %d Go package directories\n", len(dirs))
+		if len(groups) > 1 && g.Module != nil {
+			if listed {
+				index.WriteString("\n")
+			}
+			fmt.Fprintf(&index, "## Module `%s`\n\n", g.Module.Path)
+			if g.Module.GoVersion != "" {
+				fmt.Fprintf(&index, "**Go version:** %s\n\n", g.Module.GoVersion)
+			}
+		}
+		entries.WriteTo(&index)
+		listed = true
+	}
+
+	root.WriteTo(&index)
+	out[markdownIndexFile] = index.Bytes()
+	return out, r.err()
+}
+
+// sortedKeys returns the keys of a file map in sorted order.
+//
+// Parameters:
+//   - files: The file contents by path
+//
+// Returns:
+//   - []string: The sorted paths
+func sortedKeys(files map[string][]byte) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// markdownIndexFile is the name of the per-package files written by
+// GenerateMarkdownFiles and of the index at the top of the output directory.
+const markdownIndexFile = "README.md"
+
+// markdownFileFor returns the file, relative to the output directory, that
+// GenerateMarkdownFiles writes a package directory's documentation to.
+//
+// Parameters:
+//   - rootDir: The base directory being scanned
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The relative path of the markdown file
+func markdownFileFor(rootDir, dir string) string {
+	rel, err := filepath.Rel(rootDir, dir)
+	if err == nil && rel == "." {
+		return markdownIndexFile
+	}
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// Workspace modules outside rootDir are laid out by import path.
+		importPath, err := modules.ImportPath(dir)
+		if err != nil {
+			return markdownIndexFile
 		}
+		return filepath.Join(filepath.FromSlash(importPath), markdownIndexFile)
 	}
+	return filepath.Join(rel, markdownIndexFile)
+}
 
+// packageTitle returns the name a package is listed under in an index: its
+// import path when known, otherwise its name.
+//
+// Parameters:
+//   - pkg: The loaded package
+//
+// Returns:
+//   - string: The import path or package name
+func packageTitle(pkg *parse.Package) string {
+	if pkg.ImportPath != "" {
+		return pkg.ImportPath
+	}
+	return pkg.Doc.Name
+}
+
+// markdownRenderer renders the packages of a single markdown run, sharing the
+// parsed templates and counting failed examples across packages.
+type markdownRenderer struct {
+	rootDir        string
+	opts           Options
+	tmpl           *template.Template
+	failedExamples int
+
+	// loaded holds the packages of each directory, loaded up front so that
+	// implementations can be related across all of them.
+	loaded map[string]loadResult
+
+	// matrix relates the types of all loaded packages to their interfaces.
+	matrix *implements.Matrix
+
+	// workspaces holds the isolated module copies examples are verified in,
+	// keyed by the directory of the original module.
+	workspaces map[string]*verify.Workspace
+}
+
+// loadResult is the outcome of loading the packages of a directory.
+type loadResult struct {
+	pkgs []*parse.Package
+	err  error
+}
+
+// newMarkdownRenderer prepares a renderer for the given options, loading the
+// Markdown templates, including those of Options.TemplateDir, and the packages
+// of every directory.
+//
+// Parameters:
+//   - rootDir: The scanned root directory, against which package overrides are matched
+//   - dirs: The package directories that will be rendered
+//   - opts: The rendering options
+//
+// Returns:
+//   - *markdownRenderer: The renderer
+//   - error: An error if the templates cannot be loaded
+func newMarkdownRenderer(rootDir string, dirs []string, opts Options) (*markdownRenderer, error) {
+	r := &markdownRenderer{rootDir: rootDir, opts: opts, loaded: map[string]loadResult{}}
+	tmpl, err := format.LoadTemplates(opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	r.tmpl = tmpl
+
+	var all []*parse.Package
 	for _, dir := range dirs {
-		if cfg.verbose {
+		pkgOpts := opts.forPackage(rootDir, dir)
+		if pkgOpts.Verbose {
 			fmt.Fprintf(os.Stderr, "📦 Parsing package: %s\n", dir)
 		}
+		pkgs, err := loadPackages(dir, pkgOpts)
+		r.loaded[dir] = loadResult{pkgs: pkgs, err: err}
+		all = append(all, pkgs...)
+	}
+	r.matrix = implements.Compute(all, opts.IncludePrivate)
+	return r, nil
+}
 
-		docPkg, err := parse.LoadPackage(dir)
+// render writes the markdown documentation of the packages in dir selected by
+// the options (see loadPackages). Directories whose packages cannot be loaded
+// and packages without exported symbols are skipped with a warning.
+//
+// Parameters:
+//   - dir: The package directory
+//   - outputDir: The directory of the markdown file, against which source links are made relative
+//   - out: The writer to output the markdown to
+//   - packageURL: Resolves the location of other documented packages for doc links
+//
+// Returns:
+//   - []*parse.Package: The rendered packages, empty if all were skipped
+//   - error: An error if examples could not be verified
+func (r *markdownRenderer) render(dir, outputDir string, out io.Writer, packageURL func(string) (string, bool)) ([]*parse.Package, error) {
+	opts := r.opts.forPackage(r.rootDir, dir)
+	opts.OutputDir = outputDir
+	loaded, ok := r.loaded[dir]
+	if !ok {
+		loaded.pkgs, loaded.err = loadPackages(dir, opts)
+	}
+	pkgs, err := loaded.pkgs, loaded.err
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", dir, err)
+		return nil, nil
+	}
+
+	var rendered []*parse.Package
+	for _, pkg := range pkgs {
+		var buf bytes.Buffer
+		ok, err := r.renderPackage(pkg, opts, &buf, packageURL)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if len(rendered) > 0 {
+			fmt.Fprintln(out)
+		}
+		buf.WriteTo(out)
+		rendered = append(rendered, pkg)
+	}
+	return rendered, nil
+}
+
+// renderPackage writes the markdown documentation of a loaded package, or its
+// command reference when it is a main package with a detected command line
+// interface.
+//
+// Parameters:
+//   - pkg: The loaded package
+//   - opts: The options for the package's directory
+//   - out: The writer to output the markdown to
+//   - packageURL: Resolves the location of other documented packages for doc links
+//
+// Returns:
+//   - bool: False if the package has no exported symbols and was skipped
+//   - error: An error if its examples could not be verified
+func (r *markdownRenderer) renderPackage(pkg *parse.Package, opts Options, out io.Writer, packageURL func(string) (string, bool)) (bool, error) {
+	dir := pkg.Dir
+	docPkg := pkg.Doc
+
+	if pkg.Command != nil {
+		// Main packages are documented by their command line interface.
+		cfg := format.Config{PackageURL: packageURL, Fset: pkg.Fset}
+		if err := format.WriteCommandMarkdown(docPkg, pkg.Command, out, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to write markdown for %s: %v\n", dir, err)
+		}
+		return true, nil
+	}
+
+	if len(docPkg.Types)+len(docPkg.Funcs) == 0 {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping %s (package %s): no exported symbols\n", dir, docPkg.Name)
+		}
+		return false, nil
+	}
+
+	modelOpts := model.Options{
+		IncludePrivate:      opts.IncludePrivate,
+		IncludeUndocumented: opts.IncludeUndocumented,
+		HideDeprecated:      opts.HideDeprecated,
+		Implementations:     r.matrix,
+	}
+	mdCfg := format.Config{
+		IncludePrivate:      opts.IncludePrivate,
+		IncludeUndocumented: opts.IncludeUndocumented,
+		HideDeprecated:      opts.HideDeprecated,
+		NoteMarkers:         opts.NoteMarkers,
+		TagRenderers:        opts.TagRenderers,
+		PackageURL:          packageURL,
+		OutputDir:           opts.OutputDir,
+		Fset:                pkg.Fset,
+	}
+
+	if opts.VerifyExamples && hasExamples(docPkg) {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "🧪 Verifying examples: %s\n", dir)
+		}
+		failures, err := r.verifyExamples(dir)
+		if err != nil {
+			return false, fmt.Errorf("verifying examples in %s: %w", dir, err)
+		}
+		names := make([]string, 0, len(failures))
+		for name := range failures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "❌ %s: %s output does not match\n", dir, name)
+		}
+		r.failedExamples += len(failures)
+		mdCfg.ExampleFailures = failures
+	}
+
+	if err := format.WriteMarkdownTemplate(model.Build(pkg, modelOpts), out, r.tmpl, mdCfg); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to write markdown for %s: %v\n", dir, err)
+	}
+	return true, nil
+}
+
+// verifyExamples runs the examples of the package in dir in an isolated copy of
+// its module, created on first use and shared by the module's packages.
+//
+// Parameters:
+//   - dir: The package directory
+//
+// Returns:
+//   - map[string]string: The failure report of each failing example
+//   - error: Any error encountered copying the module or running the examples
+func (r *markdownRenderer) verifyExamples(dir string) (map[string]string, error) {
+	mod, err := modules.Find(dir)
+	if err != nil {
+		return nil, err
+	}
+	ws, ok := r.workspaces[mod.Dir]
+	if !ok {
+		if ws, err = verify.NewWorkspace(dir); err != nil {
+			return nil, err
+		}
+		if r.workspaces == nil {
+			r.workspaces = map[string]*verify.Workspace{}
+		}
+		r.workspaces[mod.Dir] = ws
+	}
+	return ws.Examples(dir)
+}
+
+// close removes the workspaces created to verify examples.
+func (r *markdownRenderer) close() {
+	for _, ws := range r.workspaces {
+		ws.Close()
+	}
+}
+
+// err reports the examples that failed verification across all rendered packages.
+//
+// Returns:
+//   - error: An error if any example failed verification
+func (r *markdownRenderer) err() error {
+	if r.failedExamples > 0 {
+		return fmt.Errorf("%d example(s) failed verification", r.failedExamples)
+	}
+	return nil
+}
+
+// GenerateHTML walks the provided directory and writes a static HTML
+// documentation site for its Go packages to outDir.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - outDir: The directory to write the site to
+//   - opts: The discovery and visibility options to apply
+//
+// Returns:
+//   - error: Any error encountered during parsing or output
+func GenerateHTML(rootDir, outDir string, opts Options) error {
+	pkgs, err := loadModels(rootDir, opts)
+	if err != nil {
+		return err
+	}
+	return format.WriteHTMLSite(pkgs, outDir)
+}
+
+// GenerateJSON walks the provided directory and writes the documentation model
+// of its Go packages to the given writer as versioned JSON.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - out: The writer to output JSON to
+//   - opts: The discovery and visibility options to apply
+//
+// Returns:
+//   - error: Any error encountered during parsing or output
+func GenerateJSON(rootDir string, out io.Writer, opts Options) error {
+	pkgs, err := loadModels(rootDir, opts)
+	if err != nil {
+		return err
+	}
+	return format.WriteJSON(pkgs, out)
+}
+
+// Coverage walks the provided directory and measures how much of each Go
+// package's exported API is documented. Options.IncludePrivate,
+// Options.IncludeUndocumented, Options.HideDeprecated and Options.Packages are
+// ignored, so that deprecated symbols still count towards the coverage.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - coverage.Report: The per-package and total documentation coverage
+//   - error: Any error encountered while discovering packages
+func Coverage(rootDir string, opts Options) (coverage.Report, error) {
+	opts.IncludePrivate = false
+	opts.IncludeUndocumented = true
+	opts.HideDeprecated = false
+	opts.Packages = nil
+	pkgs, err := loadModels(rootDir, opts)
+	if err != nil {
+		return coverage.Report{}, err
+	}
+	return coverage.Measure(pkgs), nil
+}
+
+// Lint walks the provided directory and checks the doc comments of each Go
+// package's exported symbols against the rules of the lint package.
+// Options.IncludePrivate, Options.IncludeUndocumented, Options.HideDeprecated
+// and Options.Packages are ignored.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []lint.Issue: The rule violations
+//   - error: Any error encountered while discovering packages
+func Lint(rootDir string, opts Options) ([]lint.Issue, error) {
+	opts.IncludePrivate = false
+	opts.IncludeUndocumented = false
+	opts.HideDeprecated = false
+	opts.Packages = nil
+	pkgs, err := loadModels(rootDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Check(pkgs), nil
+}
+
+// EnvVars walks the provided directory and collects the environment variables
+// read by the configuration structs of its Go packages, including unexported
+// structs and undocumented fields. Options.IncludePrivate,
+// Options.IncludeUndocumented and Options.Packages are ignored.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []envvars.Var: The environment variables, sorted by name
+//   - error: Any error encountered while discovering packages
+func EnvVars(rootDir string, opts Options) ([]envvars.Var, error) {
+	opts.IncludePrivate = true
+	opts.IncludeUndocumented = true
+	opts.Packages = nil
+	pkgs, err := loadModels(rootDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return envvars.Collect(pkgs), nil
+}
+
+// Diff compares the exported API of the Go packages under rootDir with the same
+// directory at a git revision, which is checked out into a temporary worktree.
+// Options.IncludePrivate, Options.IncludeUndocumented, Options.HideDeprecated
+// and Options.Packages are ignored.
+//
+// Parameters:
+//   - rootDir: The base directory to scan, inside a git repository
+//   - baseRef: The revision to compare against, e.g. "v1.2.0" or "main"
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - apidiff.Report: The changes from baseRef to the working tree
+//   - error: Any error encountered checking out the revision or loading packages
+func Diff(rootDir, baseRef string, opts Options) (apidiff.Report, error) {
+	oldPkgs, err := loadAPIAt(rootDir, baseRef, opts)
+	if err != nil {
+		return apidiff.Report{}, err
+	}
+	newPkgs, err := loadAPI(rootDir, opts)
+	if err != nil {
+		return apidiff.Report{}, err
+	}
+	return apidiff.Compare(oldPkgs, newPkgs), nil
+}
+
+// Changelog compares the exported API of the Go packages under rootDir at each
+// semantic version tag of the repository with the previous tag, and the working
+// tree with the latest tag. Options.IncludePrivate, Options.IncludeUndocumented,
+// Options.HideDeprecated and Options.Packages are ignored.
+//
+// Parameters:
+//   - rootDir: The base directory to scan, inside a git repository
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []apidiff.Release: The releases, newest first, preceded by unreleased changes if there are any
+//   - error: Any error encountered listing tags, checking out revisions or loading packages
+func Changelog(rootDir string, opts Options) ([]apidiff.Release, error) {
+	tags, err := vcs.Tags(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []apidiff.Release
+	var prev []*model.Package
+	for i, tag := range tags {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "🔍 Loading API at %s\n", tag)
+		}
+		pkgs, err := loadAPIAt(rootDir, tag, opts)
+		if err != nil {
+			return nil, err
+		}
+		date, err := vcs.Date(rootDir, tag)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, apidiff.Release{
+			Version: tag,
+			Date:    date,
+			Initial: i == 0,
+			Report:  apidiff.Compare(prev, pkgs),
+		})
+		prev = pkgs
+	}
+
+	current, err := loadAPI(rootDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if report := apidiff.Compare(prev, current); len(report.Changes) > 0 {
+		releases = append(releases, apidiff.Release{Version: apidiff.Unreleased, Report: report})
+	}
+
+	// Newest first.
+	for i, j := 0, len(releases)-1; i < j; i, j = i+1, j-1 {
+		releases[i], releases[j] = releases[j], releases[i]
+	}
+	return releases, nil
+}
+
+// RecommendVersion computes the minimum version bump the API changes made since
+// the latest semver release tag require, comparing the tag with the working tree
+// as Diff does. Pre-release tags are only used when no release tag exists.
+//
+// Parameters:
+//   - rootDir: The base directory to scan, inside a git repository and a Go module
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - semver.Recommendation: The required bump and its justification
+//   - error: Any error encountered listing tags, finding the module or loading packages
+func RecommendVersion(rootDir string, opts Options) (semver.Recommendation, error) {
+	tags, err := vcs.Tags(rootDir)
+	if err != nil {
+		return semver.Recommendation{}, err
+	}
+	if len(tags) == 0 {
+		return semver.Recommendation{}, fmt.Errorf("no semver tags found")
+	}
+	latest := tags[len(tags)-1]
+	for i := len(tags) - 1; i >= 0; i-- {
+		if v, _ := semver.Parse(tags[i]); v.Prerelease == "" {
+			latest = tags[i]
+			break
+		}
+	}
+
+	mod, err := modules.Find(rootDir)
+	if err != nil {
+		return semver.Recommendation{}, err
+	}
+	report, err := Diff(rootDir, latest, opts)
+	if err != nil {
+		return semver.Recommendation{}, err
+	}
+	current, _ := semver.Parse(latest)
+	return semver.Recommend(current, report, mod.Path), nil
+}
+
+// loadAPIAt loads the exported API under rootDir at a git revision.
+// Options.PatternDir is mapped onto the worktree so Include and Exclude
+// patterns select the same directories at the revision.
+//
+// Parameters:
+//   - rootDir: The base directory to scan, inside a git repository
+//   - ref: The revision to check out into a temporary worktree
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []*model.Package: The documentation models at the revision
+//   - error: Any error encountered checking out the revision or loading packages
+func loadAPIAt(rootDir, ref string, opts Options) ([]*model.Package, error) {
+	dir, cleanup, err := vcs.Worktree(rootDir, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if opts.PatternDir != "" {
+		if opts.PatternDir, err = vcs.WorktreePath(rootDir, dir, opts.PatternDir); err != nil {
+			return nil, err
+		}
+	}
+	return loadAPI(dir, opts)
+}
+
+// loadAPI loads the models of every exported symbol under rootDir, documented
+// or not, for comparing APIs.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []*model.Package: The documentation models
+//   - error: Any error encountered while discovering packages
+func loadAPI(rootDir string, opts Options) ([]*model.Package, error) {
+	if _, err := os.Stat(rootDir); err != nil {
+		// The directory does not exist at this revision.
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	opts.IncludePrivate = false
+	opts.IncludeUndocumented = true
+	opts.HideDeprecated = false
+	opts.Packages = nil
+	return loadModels(rootDir, opts)
+}
+
+// loadModels loads every package under rootDir and converts those with visible
+// symbols into documentation models.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery and visibility options to apply
+//
+// Returns:
+//   - []*model.Package: The documentation models, in directory order
+//   - error: Any error encountered while discovering packages
+func loadModels(rootDir string, opts Options) ([]*model.Package, error) {
+	dirs, err := packageDirs(rootDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	type loaded struct {
+		pkg  *parse.Package
+		opts Options
+	}
+	var all []loaded
+	var parsed []*parse.Package
+	for _, dir := range dirs {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "📦 Parsing package: %s\n", dir)
+		}
+		pkgOpts := opts.forPackage(rootDir, dir)
+		pkgs, err := loadPackages(dir, pkgOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", dir, err)
 			continue
 		}
+		for _, pkg := range pkgs {
+			all = append(all, loaded{pkg, pkgOpts})
+		}
+		parsed = append(parsed, pkgs...)
+	}
+	matrix := implements.Compute(parsed, opts.IncludePrivate)
 
-		if len(docPkg.Types)+len(docPkg.Funcs) == 0 {
-			if cfg.verbose {
-				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: no exported symbols\n", dir)
+	var pkgs []*model.Package
+	for _, l := range all {
+		m := model.Build(l.pkg, model.Options{
+			IncludePrivate:      l.opts.IncludePrivate,
+			IncludeUndocumented: l.opts.IncludeUndocumented,
+			HideDeprecated:      l.opts.HideDeprecated,
+			Implementations:     matrix,
+		})
+		if len(m.Funcs)+len(m.Types)+len(m.Consts)+len(m.Vars) == 0 {
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s (package %s): no exported symbols\n", l.pkg.Dir, m.Name)
 			}
 			continue
 		}
+		pkgs = append(pkgs, m)
+	}
+	return pkgs, nil
+}
 
-		fmt.Fprintf(out, "<!-- %s -->\n\n", dir)
-		if err := format.WriteMarkdownWithOptions(docPkg, out, cfg.includePrivate, cfg.includeUndocumented); err != nil {
-			fmt.Fprintf(os.Stderr, "⚠️  Failed to write markdown for %s: %v\n", dir, err)
-			continue
+// packageDirs returns the package directories to document: rootDir itself, or
+// every Go package directory below it and in the modules of its go.work file
+// when scanning recursively, restricted to Options.Module when set.
+//
+// Parameters:
+//   - rootDir: The base directory to scan
+//   - opts: The discovery options to apply
+//
+// Returns:
+//   - []string: The package directories
+//   - error: Any error encountered while walking the directory tree
+func packageDirs(rootDir string, opts Options) ([]string, error) {
+	dirs := []string{rootDir}
+	if opts.Recursive {
+		roots, err := scanRoots(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		dirs = nil
+		for _, root := range roots {
+			found, err := discover.Dirs(root, discover.Options{
+				Include:           opts.Include,
+				Exclude:           opts.Exclude,
+				ExcludeInternal:   opts.ExcludeInternal,
+				NoDefaultExcludes: opts.NoDefaultExcludes,
+				NoGitignore:       opts.NoGitignore,
+				PatternDir:        opts.PatternDir,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("collecting package dirs: %w", err)
+			}
+			dirs = append(dirs, found...)
 		}
 	}
 
-	return nil
+	if opts.Module != "" {
+		var inModule []string
+		for _, dir := range dirs {
+			if mod, err := modules.Find(dir); err == nil && mod.Path == opts.Module {
+				inModule = append(inModule, dir)
+			}
+		}
+		if len(inModule) == 0 {
+			return nil, fmt.Errorf("no packages found in module %s", opts.Module)
+		}
+		dirs = inModule
+	}
+
+	if opts.Verbose && opts.Recursive {
+		fmt.Fprintf(os.Stderr, "🔍 Found %d Go package directories\n", len(dirs))
+	}
+	return dirs, nil
 }
 
-// collectGoPackageDirs walks the file tree and collects valid Go package directories.
+// scanRoots returns the directories a recursive scan of rootDir walks: rootDir
+// itself and, when it holds a go.work file, the workspace modules outside it.
 //
 // Parameters:
-//   - root: The root directory to start scanning from
+//   - rootDir: The base directory to scan
 //
 // Returns:
-//   - []string: List of package paths that contain buildable Go source files
-//   - error: Any filesystem error encountered
-func collectGoPackageDirs(root string) ([]string, error) {
-	var dirs []string
-	seen := map[string]bool{}
+//   - []string: The directories to walk
+//   - error: Any error encountered reading the workspace
+func scanRoots(rootDir string) ([]string, error) {
+	roots := []string{rootDir}
+	if _, err := os.Stat(filepath.Join(rootDir, "go.work")); err != nil {
+		return roots, nil
+	}
+	ws, err := modules.ReadWorkspace(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, mod := range ws.Modules {
+		absDir, err := filepath.Abs(mod.Dir)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(absRoot, absDir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		roots = append(roots, mod.Dir)
+	}
+	return roots, nil
+}
+
+// buildOptions returns the build constraint settings packages are loaded with.
+//
+// Returns:
+//   - parse.BuildOptions: The platforms and build tags
+func (o Options) buildOptions() parse.BuildOptions {
+	return parse.BuildOptions{GOOS: o.GOOS, GOARCH: o.GOARCH, Tags: o.BuildTags, Platforms: o.Platforms}
+}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
+// hasExamples reports whether any example is attached to the package or its symbols.
+//
+// Parameters:
+//   - pkg: The documentation package to inspect
+//
+// Returns:
+//   - bool: True if the package has at least one example
+func hasExamples(pkg *doc.Package) bool {
+	if len(pkg.Examples) > 0 {
+		return true
+	}
+	for _, f := range pkg.Funcs {
+		if len(f.Examples) > 0 {
+			return true
 		}
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
+	}
+	for _, t := range pkg.Types {
+		if len(t.Examples) > 0 {
+			return true
 		}
-		dir := filepath.Dir(path)
-		if seen[dir] {
-			return nil
+		for _, f := range t.Funcs {
+			if len(f.Examples) > 0 {
+				return true
+			}
 		}
-		trimmed := strings.TrimPrefix(dir, "./")
-		if trimmed == "vendor" || trimmed == "docs" || strings.HasPrefix(trimmed, ".") ||
-			strings.HasPrefix(trimmed, "terraform") || strings.Contains(trimmed, "/.github") {
-			return nil
+		for _, m := range t.Methods {
+			if len(m.Examples) > 0 {
+				return true
+			}
 		}
-		seen[dir] = true
-		dirs = append(dirs, dir)
-		return nil
-	})
-
-	return dirs, err
+	}
+	return false
 }
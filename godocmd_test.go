@@ -0,0 +1,242 @@
+package godocmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thinktide/godocmd/apidiff"
+	"github.com/thinktide/godocmd/format"
+)
+
+// readFile is a test helper that returns the content of a file.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - path: The file to read
+//
+// Returns:
+//   - string: The file content
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGenerateMarkdownFiles(t *testing.T) {
+	base := t.TempDir()
+	writeTree(t, base, map[string]string{
+		"shop/go.mod":          "module example.com/shop\n\ngo 1.21\n",
+		"shop/go.work":         "go 1.21\n\nuse (\n\t.\n\t../lib\n)\n",
+		"shop/models/user.go":  checkModule["models/user.go"],
+		"shop/store/store.go":  "// Package store persists [models.User] values.\npackage store\n\nimport \"example.com/shop/models\"\n\n// Save stores a [models.User].\nfunc Save(u *models.User) error { return nil }\n",
+		"lib/go.mod":           "module example.com/lib\n\ngo 1.21\n",
+		"lib/util/util.go":     "// Package util has helpers.\npackage util\n\n// Trim trims.\nfunc Trim(s string) string { return s }\n",
+		"shop/internal/x/x.go": "package x\n",
+	})
+	root := filepath.Join(base, "shop")
+	outDir := filepath.Join(root, "docs")
+	if err := GenerateMarkdownFiles(root, outDir, Options{Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	err := filepath.WalkDir(outDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(outDir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "example.com/lib/util/README.md", "models/README.md", "store/README.md"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("expected files %v, got %v", want, files)
+	}
+
+	index := readFile(t, filepath.Join(outDir, "README.md"))
+	for _, entry := range []string{
+		"## Module `example.com/lib`",
+		"- [example.com/lib/util](example.com/lib/util/README.md) — Package util has helpers.\n",
+		"## Module `example.com/shop`",
+		"- [example.com/shop/models](models/README.md) — Package models holds the data types.\n",
+		"- [example.com/shop/store](store/README.md) — Package store persists models.User values.\n",
+	} {
+		if !strings.Contains(index, entry) {
+			t.Errorf("expected %q in the index:\n%s", entry, index)
+		}
+	}
+
+	store := readFile(t, filepath.Join(outDir, "store", "README.md"))
+	if link := "Save stores a [models.User](../models/README.md#example-com-shop-models.User)."; !strings.Contains(store, link) {
+		t.Errorf("expected the relative link %q in store/README.md:\n%s", link, store)
+	}
+	if models := readFile(t, filepath.Join(outDir, "models", "README.md")); !strings.Contains(models, "User is a customer.") {
+		t.Errorf("expected the models documentation in models/README.md:\n%s", models)
+	}
+}
+
+func TestInjectMarkdown(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":               checkModule["go.mod"],
+		"store/store.go":       "// Package store persists models.\npackage store\n\n// BUG(ada): Save never fails.\n\n// Save stores a value.\nfunc Save(v any) error { return nil }\n",
+		"docs/guide/README.md": "# Guide\n\nIntro.\n\n" + format.InjectStart + "\nold\n" + format.InjectEnd + "\n\nOutro.\n",
+	})
+	file := filepath.Join(root, "docs", "guide", "README.md")
+	opts := Options{Recursive: true}
+	if err := InjectMarkdown(root, file, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readFile(t, file)
+	if !strings.HasPrefix(got, "# Guide\n\nIntro.\n\n"+format.InjectStart+"\n") || !strings.HasSuffix(got, format.InjectEnd+"\n\nOutro.\n") {
+		t.Errorf("expected the text around the markers to be kept:\n%s", got)
+	}
+	if strings.Contains(got, "\nold\n") || !strings.Contains(got, "Save stores a value.") {
+		t.Errorf("expected the marked section to be regenerated:\n%s", got)
+	}
+	if link := "([store.go:4](../../store/store.go#L4))"; !strings.Contains(got, link) {
+		t.Errorf("expected the note link %q relative to the injected file:\n%s", link, got)
+	}
+
+	// An up-to-date file is not rewritten.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(file, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := InjectMarkdown(root, file, opts); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("expected an up-to-date file to be left untouched, modified at %v", info.ModTime())
+	}
+
+	plain := filepath.Join(root, "docs", "PLAIN.md")
+	writeTree(t, root, map[string]string{"docs/PLAIN.md": "# Plain\n"})
+	if err := InjectMarkdown(root, plain, opts); !errors.Is(err, format.ErrNoMarkers) {
+		t.Errorf("expected ErrNoMarkers for a file without markers, got %v", err)
+	}
+	if readFile(t, plain) != "# Plain\n" {
+		t.Error("expected a file without markers to be left unchanged")
+	}
+}
+
+// gitRepo is a test helper that initializes a git repository in a new
+// temporary directory and returns it together with a function running git in it.
+//
+// Parameters:
+//   - t: The test to skip without git and to fail on errors
+//
+// Returns:
+//   - string: The repository directory
+//   - func(args ...string): Runs a git command in the repository
+func gitRepo(t *testing.T) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	return root, git
+}
+
+func TestDiff_Patterns(t *testing.T) {
+	root, git := gitRepo(t)
+	writeTree(t, root, map[string]string{
+		"go.mod":     "module example.com/diff\n\ngo 1.21\n",
+		"api/api.go": "package api\n\n// A does a.\nfunc A() {}\n",
+		"gen/gen.go": "package gen\n\n// G is generated.\nfunc G() {}\n",
+	})
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "base")
+	writeTree(t, root, map[string]string{
+		"api/b.go": "package api\n\n// B does b.\nfunc B() {}\n",
+		"gen/h.go": "package gen\n\n// H is generated.\nfunc H() {}\n",
+	})
+
+	// The patterns are relative to the checkout, as those of a configuration file are.
+	opts := Options{Recursive: true, Include: []string{"api", "gen"}, Exclude: []string{"gen"}, PatternDir: root}
+	report, err := Diff(root, "HEAD", opts)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Symbol != "B" || report.Changes[0].Kind != apidiff.Added {
+		t.Errorf("expected only B to be added, got %+v", report.Changes)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	root, git := gitRepo(t)
+	writeTree(t, root, map[string]string{
+		"go.mod":     "module example.com/lib\n\ngo 1.21\n",
+		"lib/lib.go": "// Package lib is a library.\npackage lib\n\n// A does a.\nfunc A() {}\n",
+	})
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "first")
+	git("tag", "v0.9.0")
+	writeTree(t, root, map[string]string{"lib/b.go": "package lib\n\n// B does b.\nfunc B() {}\n"})
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "second")
+	// v0.10.0 sorts before v0.9.0 as a string but follows it as a version.
+	git("tag", "v0.10.0")
+	writeTree(t, root, map[string]string{"lib/c.go": "package lib\n\n// C does c.\nfunc C() {}\n"})
+
+	releases, err := Changelog(root, Options{Recursive: true})
+	if err != nil {
+		t.Fatalf("Changelog: %v", err)
+	}
+	var versions []string
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	if got := strings.Join(versions, ","); got != "Unreleased,v0.10.0,v0.9.0" {
+		t.Fatalf("expected the releases newest first, got %s", got)
+	}
+
+	added := func(r apidiff.Release) string {
+		var symbols []string
+		for _, c := range r.Report.Changes {
+			if c.Kind == apidiff.Added {
+				symbols = append(symbols, c.Package+"."+c.Symbol)
+			}
+		}
+		return strings.Join(symbols, ",")
+	}
+	if r := releases[0]; r.Initial || r.Date != "" || added(r) != "example.com/lib/lib.C" || len(r.Report.Changes) != 1 {
+		t.Errorf("expected the unreleased working tree change C, got %+v", r)
+	}
+	if r := releases[1]; r.Initial || r.Date == "" || added(r) != "example.com/lib/lib.B" || len(r.Report.Changes) != 1 {
+		t.Errorf("expected v0.10.0 to add B, got %+v", r)
+	}
+	if r := releases[2]; !r.Initial || r.Date == "" {
+		t.Errorf("expected v0.9.0 to be the initial release, got %+v", r)
+	}
+
+	var out strings.Builder
+	apidiff.WriteChangelog(releases, &out)
+	if got := out.String(); !strings.Contains(got, "Initial release.") || strings.Index(got, "Unreleased") > strings.Index(got, "v0.10.0") {
+		t.Errorf("unexpected changelog:\n%s", got)
+	}
+}
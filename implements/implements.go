@@ -0,0 +1,319 @@
+// Package implements computes which concrete types of a set of packages
+// implement which of their interfaces. The packages are type-checked with
+// go/types from their syntax trees; standard library imports are checked from
+// source and other imports are replaced by empty packages, so interfaces
+// embedding interfaces of those packages are left out.
+package implements
+
+import (
+	"go/importer"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+
+	"github.com/thinktide/godocmd/parse"
+)
+
+// Ref names a type related to a documented type.
+type Ref struct {
+	// Name is the type name, qualified with its package name when it is
+	// declared in another package, e.g. "Store", "disk.Store" or "error".
+	Name string `json:"name"`
+
+	// Pointer is set when only the pointer to the concrete type implements
+	// the interface, because some of its methods have pointer receivers.
+	Pointer bool `json:"pointer,omitempty"`
+}
+
+// Matrix holds the implementation relations between the types of a set of
+// packages, and the type-checked packages themselves.
+type Matrix struct {
+	implements    map[typeKey][]relation
+	implementedBy map[typeKey][]relation
+	packages      map[string]*types.Package
+}
+
+// typeKey identifies a named type by the key of its package and its name.
+type typeKey struct {
+	pkg  string
+	name string
+}
+
+// relation is one side of an implementation relation.
+type relation struct {
+	pkg     *types.Package // nil for the predeclared error interface
+	name    string
+	pointer bool
+}
+
+// Compute type-checks the packages and relates each named concrete type to
+// every interface of the packages, and to the predeclared error interface,
+// that it or its pointer implements. Empty interfaces, constraint interfaces
+// and generic types are skipped.
+//
+// Parameters:
+//   - pkgs: The loaded packages
+//   - includePrivate: Whether unexported types and interfaces are related
+//
+// Returns:
+//   - *Matrix: The relations
+func Compute(pkgs []*parse.Package, includePrivate bool) *Matrix {
+	c := &checker{
+		byPath:  map[string]*parse.Package{},
+		checked: map[string]*types.Package{},
+		std:     importer.ForCompiler(token.NewFileSet(), "source", nil),
+	}
+	for _, p := range pkgs {
+		if p.ImportPath != "" && !p.ExternalTest {
+			c.byPath[p.ImportPath] = p
+		}
+	}
+
+	m := &Matrix{
+		implements:    map[typeKey][]relation{},
+		implementedBy: map[typeKey][]relation{},
+		packages:      map[string]*types.Package{},
+	}
+	var named []*types.TypeName
+	keys := map[*types.Package]string{}
+	for _, p := range pkgs {
+		if p.ExternalTest || len(p.Files) == 0 {
+			continue
+		}
+		key := packageKey(p)
+		tp := c.check(key, p)
+		if tp == nil {
+			continue
+		}
+		keys[tp] = key
+		m.packages[key] = tp
+		scope := tp.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || (!includePrivate && !tn.Exported()) {
+				continue
+			}
+			if n, ok := tn.Type().(*types.Named); ok && n.TypeParams().Len() == 0 {
+				named = append(named, tn)
+			}
+		}
+	}
+
+	var ifaces, concrete []*types.TypeName
+	for _, tn := range named {
+		if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+			if usable(iface) {
+				ifaces = append(ifaces, tn)
+			}
+			continue
+		}
+		if _, ok := tn.Type().Underlying().(*types.Pointer); !ok {
+			concrete = append(concrete, tn)
+		}
+	}
+	ifaces = append(ifaces, types.Universe.Lookup("error").(*types.TypeName))
+
+	for _, t := range concrete {
+		for _, i := range ifaces {
+			iface := i.Type().Underlying().(*types.Interface)
+			pointer := false
+			if !types.Implements(t.Type(), iface) {
+				if !types.Implements(types.NewPointer(t.Type()), iface) {
+					continue
+				}
+				pointer = true
+			}
+			tk := typeKey{keys[t.Pkg()], t.Name()}
+			m.implements[tk] = append(m.implements[tk], relation{pkg: i.Pkg(), name: i.Name(), pointer: pointer})
+			if i.Pkg() != nil {
+				ik := typeKey{keys[i.Pkg()], i.Name()}
+				m.implementedBy[ik] = append(m.implementedBy[ik], relation{pkg: t.Pkg(), name: t.Name(), pointer: pointer})
+			}
+		}
+	}
+	return m
+}
+
+// Implements returns the interfaces a concrete type implements.
+//
+// Parameters:
+//   - pkg: The package declaring the type
+//   - name: The type name
+//
+// Returns:
+//   - []Ref: The interfaces, qualified relative to pkg; Pointer is set when only *name implements one
+func (m *Matrix) Implements(pkg *parse.Package, name string) []Ref {
+	if m == nil {
+		return nil
+	}
+	return refs(m.implements[typeKey{packageKey(pkg), name}], pkg.Doc.Name)
+}
+
+// ImplementedBy returns the concrete types implementing an interface.
+//
+// Parameters:
+//   - pkg: The package declaring the interface
+//   - name: The interface name
+//
+// Returns:
+//   - []Ref: The types, qualified relative to pkg; Pointer is set when only the pointer type implements it
+func (m *Matrix) ImplementedBy(pkg *parse.Package, name string) []Ref {
+	if m == nil {
+		return nil
+	}
+	return refs(m.implementedBy[typeKey{packageKey(pkg), name}], pkg.Doc.Name)
+}
+
+// Lookup returns the object a package declares at package scope, such as a
+// constant or variable, as type-checked by Compute.
+//
+// Parameters:
+//   - pkg: The package declaring the object
+//   - name: The object name
+//
+// Returns:
+//   - types.Object: The object, or nil if the package was not checked or does not declare name
+func (m *Matrix) Lookup(pkg *parse.Package, name string) types.Object {
+	if m == nil {
+		return nil
+	}
+	tp, ok := m.packages[packageKey(pkg)]
+	if !ok || tp == nil {
+		return nil
+	}
+	return tp.Scope().Lookup(name)
+}
+
+// refs converts relations to references qualified relative to a package.
+//
+// Parameters:
+//   - rels: The relations
+//   - from: The name of the package the references are shown in
+//
+// Returns:
+//   - []Ref: The references
+func refs(rels []relation, from string) []Ref {
+	var out []Ref
+	for _, r := range rels {
+		name := r.name
+		if r.pkg != nil && r.pkg.Name() != from {
+			name = r.pkg.Name() + "." + name
+		}
+		out = append(out, Ref{Name: name, Pointer: r.pointer})
+	}
+	return out
+}
+
+// usable reports whether an interface is worth relating types to: it has
+// methods, is not a constraint, and embeds no interface that could not be
+// resolved.
+//
+// Parameters:
+//   - iface: The interface
+//
+// Returns:
+//   - bool: True if the interface should be related
+func usable(iface *types.Interface) bool {
+	if iface.NumMethods() == 0 || !iface.IsMethodSet() {
+		return false
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		if !types.IsInterface(iface.EmbeddedType(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// packageKey identifies a loaded package: its import path, or its directory
+// and name outside a module.
+//
+// Parameters:
+//   - p: The package
+//
+// Returns:
+//   - string: The key
+func packageKey(p *parse.Package) string {
+	if p.ImportPath != "" {
+		return p.ImportPath
+	}
+	return p.Dir + ":" + p.Doc.Name
+}
+
+// checker type-checks the loaded packages, resolving their imports of each
+// other from the loaded syntax trees.
+type checker struct {
+	byPath  map[string]*parse.Package
+	checked map[string]*types.Package
+	std     types.Importer
+}
+
+// check type-checks a loaded package once, ignoring type errors.
+//
+// Parameters:
+//   - key: The package key, used as its path
+//   - p: The package
+//
+// Returns:
+//   - *types.Package: The checked package, possibly incomplete
+func (c *checker) check(key string, p *parse.Package) *types.Package {
+	if tp, ok := c.checked[key]; ok {
+		return tp
+	}
+	conf := types.Config{
+		Importer:         c,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {},
+	}
+	tp, _ := conf.Check(key, p.Fset, p.Files, nil)
+	c.checked[key] = tp
+	return tp
+}
+
+// Import implements types.Importer.
+//
+// Parameters:
+//   - importPath: The import path
+//
+// Returns:
+//   - *types.Package: The loaded, standard library or placeholder package
+//   - error: Always nil; unresolved imports yield an empty package
+func (c *checker) Import(importPath string) (*types.Package, error) {
+	if p, ok := c.byPath[importPath]; ok {
+		return c.check(importPath, p), nil
+	}
+	if tp, ok := c.checked[importPath]; ok {
+		return tp, nil
+	}
+	var tp *types.Package
+	if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") {
+		tp, _ = c.std.Import(importPath)
+	}
+	if tp == nil {
+		tp = types.NewPackage(importPath, placeholderName(importPath))
+		tp.MarkComplete()
+	}
+	c.checked[importPath] = tp
+	return tp, nil
+}
+
+// placeholderName guesses the package name of an unresolved import from its
+// last path element, skipping major version suffixes such as "/v2".
+//
+// Parameters:
+//   - importPath: The import path
+//
+// Returns:
+//   - string: The probable package name
+func placeholderName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.IndexAny(base, ".-"); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
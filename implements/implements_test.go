@@ -0,0 +1,123 @@
+package implements
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thinktide/godocmd/parse"
+)
+
+// writeFiles creates the given files below root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+import (
+	"io"
+
+	"example.com/ext"
+)
+
+// Store loads values.
+type Store interface {
+	Get(key string) (string, error)
+}
+
+// ReadStore is a Store that can also be read.
+type ReadStore interface {
+	io.Reader
+	Store
+}
+
+// Remote embeds an interface of an unresolved package.
+type Remote interface {
+	ext.Client
+	Get(key string) (string, error)
+}
+
+// Any is satisfied by everything.
+type Any interface{}
+
+// Number is a constraint.
+type Number interface{ ~int | ~float64 }
+
+// Memory is an in-memory store.
+type Memory map[string]string
+
+func (m Memory) Get(key string) (string, error) { return m[key], nil }
+
+// NotFound reports a missing key.
+type NotFound struct{ Key string }
+
+func (e *NotFound) Error() string { return e.Key }
+
+type hidden struct{}
+
+func (hidden) Get(string) (string, error) { return "", nil }
+`,
+		"disk/disk.go": `package disk
+
+// Store reads files.
+type Store struct{}
+
+func (s *Store) Get(key string) (string, error) { return "", nil }
+
+func (s *Store) Read(p []byte) (int, error) { return 0, nil }
+`,
+	})
+
+	var pkgs []*parse.Package
+	for _, dir := range []string{"store", "disk"} {
+		p, err := parse.Load(filepath.Join(root, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, p)
+	}
+	store, disk := pkgs[0], pkgs[1]
+
+	m := Compute(pkgs, false)
+	check := func(what string, got []Ref, want ...Ref) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s: got %+v, want %+v", what, got, want)
+			return
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %+v, want %+v", what, got, want)
+				return
+			}
+		}
+	}
+
+	check("Memory implements", m.Implements(store, "Memory"), Ref{Name: "Store"})
+	check("NotFound implements", m.Implements(store, "NotFound"), Ref{Name: "error", Pointer: true})
+	check("disk.Store implements", m.Implements(disk, "Store"),
+		Ref{Name: "store.ReadStore", Pointer: true}, Ref{Name: "store.Store", Pointer: true})
+	check("Store implemented by", m.ImplementedBy(store, "Store"),
+		Ref{Name: "Memory"}, Ref{Name: "disk.Store", Pointer: true})
+	check("ReadStore implemented by", m.ImplementedBy(store, "ReadStore"), Ref{Name: "disk.Store", Pointer: true})
+	check("Remote implemented by", m.ImplementedBy(store, "Remote"))
+	check("Any implemented by", m.ImplementedBy(store, "Any"))
+	check("hidden implements", m.Implements(store, "hidden"))
+
+	m = Compute(pkgs, true)
+	check("hidden implements (private)", m.Implements(store, "hidden"), Ref{Name: "Store"})
+}
@@ -0,0 +1,413 @@
+// Package lint checks the doc comments of exported symbols against the
+// conventions godocmd relies on when rendering documentation.
+package lint
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thinktide/godocmd/model"
+	"github.com/thinktide/godocmd/parse"
+)
+
+// Rule identifiers reported in Issue.Rule.
+const (
+	RuleNamePrefix   = "name-prefix"
+	RulePeriod       = "period"
+	RuleParameters   = "parameters"
+	RuleDeprecated   = "deprecated-replacement"
+	RuleStaleDocLink = "stale-doc-link"
+)
+
+// Rules describes each rule, in the order they are documented.
+var Rules = []struct {
+	ID          string
+	Description string
+}{
+	{RuleNamePrefix, "Doc comments of exported symbols start with the symbol name"},
+	{RulePeriod, "Doc comments end with a period"},
+	{RuleParameters, "Entries of a Parameters: section match the function's parameter names"},
+	{RuleDeprecated, "Deprecated: notices name a replacement"},
+	{RuleStaleDocLink, "Doc links refer to symbols that exist"},
+}
+
+// Issue is a single rule violation.
+type Issue struct {
+	Rule    string
+	Message string
+	Symbol  string
+	Pos     model.Position
+}
+
+// String formats the issue as "file:line:col: rule: message".
+//
+// Returns:
+//   - string: The formatted issue
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", i.Pos.File, i.Pos.Line, i.Pos.Column, i.Rule, i.Message)
+}
+
+var (
+	// paramEntryPattern matches an entry of a Parameters: section, e.g. "  - name: ...".
+	paramEntryPattern = regexp.MustCompile(`^\s*-\s*([A-Za-z_][A-Za-z0-9_]*)\s*:`)
+
+	// sectionPattern matches a section heading such as "Returns:".
+	sectionPattern = regexp.MustCompile(`^[A-Z][A-Za-z ]*:$`)
+
+	// replacementPattern matches wording that points to a replacement.
+	replacementPattern = regexp.MustCompile(`(?i)\b(use|instead|replaced|superseded|see)\b|\[[^\]]+\]|` + "`")
+)
+
+// Check lints the doc comments of the exported symbols of package models.
+// Undocumented symbols are not reported; see the coverage package.
+//
+// Parameters:
+//   - pkgs: The package models to check
+//
+// Returns:
+//   - []Issue: The violations sorted by file, line and column
+func Check(pkgs []*model.Package) []Issue {
+	var issues []Issue
+	for _, pkg := range pkgs {
+		c := newChecker(pkg)
+		c.check()
+		issues = append(issues, c.issues...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Pos, issues[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return issues
+}
+
+// checker lints the symbols of a single package.
+type checker struct {
+	pkg     *model.Package
+	symbols map[string]bool
+	imports map[string]bool
+	issues  []Issue
+}
+
+// newChecker indexes the symbols and imports of a package for resolving doc links.
+//
+// Parameters:
+//   - pkg: The package model
+//
+// Returns:
+//   - *checker: The checker for the package
+func newChecker(pkg *model.Package) *checker {
+	c := &checker{pkg: pkg, symbols: map[string]bool{}, imports: map[string]bool{}}
+	addValues := func(values []model.Value) {
+		for _, v := range values {
+			for _, s := range v.Specs {
+				for _, n := range s.Names {
+					c.symbols[n] = true
+				}
+			}
+		}
+	}
+	addValues(pkg.Consts)
+	addValues(pkg.Vars)
+	for _, f := range pkg.Funcs {
+		c.symbols[f.Name] = true
+	}
+	for _, t := range pkg.Types {
+		c.symbols[t.Name] = true
+		addValues(t.Consts)
+		addValues(t.Vars)
+		for _, f := range t.Funcs {
+			c.symbols[f.Name] = true
+		}
+		for _, f := range t.Fields {
+			c.symbols[t.Name+"."+f.Name] = true
+		}
+		for _, m := range t.Methods {
+			c.symbols[t.Name+"."+m.Name] = true
+		}
+	}
+	for _, imp := range pkg.Imports {
+		c.imports[imp] = true
+		c.imports[imp[strings.LastIndex(imp, "/")+1:]] = true
+	}
+	return c
+}
+
+// check runs every rule against the package's exported symbols.
+func (c *checker) check() {
+	for _, v := range c.pkg.Consts {
+		c.value(v)
+	}
+	for _, v := range c.pkg.Vars {
+		c.value(v)
+	}
+	for _, f := range c.pkg.Funcs {
+		c.function(f)
+	}
+	for _, t := range c.pkg.Types {
+		if !model.IsExported(t.Name) {
+			continue
+		}
+		c.symbol(t.Name, t.Name, t.Doc, t.Deprecated, t.Deprecation, t.Pos)
+		for _, f := range t.Fields {
+			if model.IsExported(f.Name) && f.Doc != "" {
+				c.links(t.Name+"."+f.Name, f.Doc, f.Pos)
+			}
+		}
+		for _, v := range t.Consts {
+			c.value(v)
+		}
+		for _, v := range t.Vars {
+			c.value(v)
+		}
+		for _, f := range t.Funcs {
+			c.function(f)
+		}
+		for _, m := range t.Methods {
+			c.function(m)
+		}
+	}
+}
+
+// function checks a function or method, including its Parameters: section.
+//
+// Parameters:
+//   - f: The function model
+func (c *checker) function(f model.Func) {
+	if !model.IsExported(f.Name) {
+		return
+	}
+	symbol := f.Name
+	if f.Recv != "" {
+		symbol = f.Recv + "." + f.Name
+	}
+	c.symbol(symbol, f.Name, f.Doc, f.Deprecated, f.Deprecation, f.Pos)
+	if f.Doc != "" {
+		c.parameters(symbol, f)
+	}
+}
+
+// value checks a const or var group. A group doc only has to start with a
+// name when the group declares a single name.
+//
+// Parameters:
+//   - v: The value model
+func (c *checker) value(v model.Value) {
+	var exported []string
+	for _, s := range v.Specs {
+		for _, n := range s.Names {
+			if model.IsExported(n) {
+				exported = append(exported, n)
+			}
+		}
+	}
+	if len(exported) == 0 {
+		return
+	}
+	name := ""
+	if len(exported) == 1 {
+		name = exported[0]
+	}
+	c.symbol(strings.Join(exported, ", "), name, v.Doc, v.Deprecated, v.Deprecation, v.Pos)
+	for _, s := range v.Specs {
+		if s.Deprecated {
+			c.deprecation(strings.Join(s.Names, ", "), s.Deprecation, s.Pos)
+		}
+	}
+}
+
+// symbol applies the rules shared by every kind of symbol.
+//
+// Parameters:
+//   - symbol: The qualified symbol name used in messages
+//   - name: The name the comment must start with, or empty to skip the check
+//   - doc: The doc comment without its deprecation paragraph
+//   - deprecated: Whether the symbol is deprecated
+//   - notice: The text following "Deprecated:"
+//   - pos: The position of the symbol
+func (c *checker) symbol(symbol, name, doc string, deprecated bool, notice string, pos model.Position) {
+	if deprecated {
+		c.deprecation(symbol, notice, pos)
+	}
+	if doc == "" {
+		return
+	}
+
+	if name != "" && !startsWithName(doc, name) {
+		c.report(RuleNamePrefix, symbol, pos, "comment on %s should start with %q", symbol, name+" ")
+	}
+	if !endsWithPeriod(doc) {
+		c.report(RulePeriod, symbol, pos, "comment on %s should end with a period", symbol)
+	}
+	c.links(symbol, doc, pos)
+}
+
+// deprecation checks that a deprecation notice names a replacement.
+//
+// Parameters:
+//   - symbol: The deprecated symbol
+//   - notice: The text following "Deprecated:"
+//   - pos: The position of the symbol
+func (c *checker) deprecation(symbol, notice string, pos model.Position) {
+	if !replacementPattern.MatchString(notice) {
+		c.report(RuleDeprecated, symbol, pos, "deprecation notice of %s should name a replacement", symbol)
+	}
+}
+
+// parameters checks that a Parameters: section lists exactly the function's parameters.
+//
+// Parameters:
+//   - symbol: The qualified function name
+//   - f: The function model
+func (c *checker) parameters(symbol string, f model.Func) {
+	documented, ok := parameterSection(f.Doc)
+	if !ok {
+		return
+	}
+	params := map[string]bool{}
+	for _, p := range f.Params {
+		params[p] = true
+	}
+	listed := map[string]bool{}
+	for _, name := range documented {
+		listed[name] = true
+		if !params[name] {
+			c.report(RuleParameters, symbol, f.Pos, "%s documents parameter %q which does not exist", symbol, name)
+		}
+	}
+	for _, p := range f.Params {
+		if p != "_" && !listed[p] {
+			c.report(RuleParameters, symbol, f.Pos, "%s does not document parameter %q", symbol, p)
+		}
+	}
+}
+
+// links reports doc links to symbols of the package that do not exist.
+//
+// Parameters:
+//   - symbol: The symbol whose comment is checked
+//   - doc: The comment text
+//   - pos: The position of the symbol
+func (c *checker) links(symbol, doc string, pos model.Position) {
+	for _, link := range parse.DocLinks(doc) {
+		target := link.Target
+		if strings.Contains(target, "/") || c.symbols[target] || c.imports[target] {
+			continue
+		}
+		first, _, qualified := strings.Cut(target, ".")
+		switch {
+		case !qualified && !model.IsExported(target):
+			// A lower-case target such as [fmt] names a package.
+			continue
+		case qualified && !c.symbols[first]:
+			// Links into other packages cannot be checked here.
+			continue
+		}
+		c.report(RuleStaleDocLink, symbol, pos, "doc link [%s] in comment on %s does not resolve", target, symbol)
+	}
+}
+
+// report records an issue.
+//
+// Parameters:
+//   - rule: The rule identifier
+//   - symbol: The symbol the issue belongs to
+//   - pos: The position of the symbol
+//   - format: The message format
+//   - args: The message arguments
+func (c *checker) report(rule, symbol string, pos model.Position, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Rule: rule, Symbol: symbol, Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// startsWithName reports whether a comment starts with a name, optionally
+// preceded by an article as in "A User is ...".
+//
+// Parameters:
+//   - doc: The comment text
+//   - name: The symbol name
+//
+// Returns:
+//   - bool: True if the comment starts with the name
+func startsWithName(doc, name string) bool {
+	for _, article := range []string{"", "A ", "An ", "The "} {
+		rest, ok := strings.CutPrefix(doc, article+name)
+		if ok && (rest == "" || !isIdentRune(rest[0])) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdentRune reports whether a byte can continue a Go identifier.
+//
+// Parameters:
+//   - b: The byte following the name
+//
+// Returns:
+//   - bool: True for letters, digits and underscores
+func isIdentRune(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// endsWithPeriod reports whether the prose of a comment ends with a period.
+// Comments ending in an indented block, such as a list or code, are accepted.
+//
+// Parameters:
+//   - doc: The comment text
+//
+// Returns:
+//   - bool: True if the comment ends with a period or an indented block
+func endsWithPeriod(doc string) bool {
+	lines := strings.Split(strings.TrimRight(doc, " \t\n"), "\n")
+	last := lines[len(lines)-1]
+	if strings.HasPrefix(last, " ") || strings.HasPrefix(last, "\t") {
+		return true
+	}
+	return strings.HasSuffix(last, ".")
+}
+
+// parameterSection extracts the names listed in a comment's Parameters: section.
+//
+// Parameters:
+//   - doc: The comment text
+//
+// Returns:
+//   - []string: The listed parameter names
+//   - bool: True if the comment has a Parameters: section
+func parameterSection(doc string) ([]string, bool) {
+	var names []string
+	found := false
+	for _, line := range strings.Split(doc, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !found {
+			found = trimmed == "Parameters:"
+			continue
+		}
+		if sectionPattern.MatchString(trimmed) {
+			break
+		}
+		if m := paramEntryPattern.FindStringSubmatch(line); m != nil && strings.HasPrefix(line, " ") {
+			names = append(names, m[1])
+		}
+	}
+	return names, found
+}
+
+// WriteText writes issues one per line as "file:line:col: rule: message".
+//
+// Parameters:
+//   - issues: The issues to write
+//   - out: The writer to output the issues to
+func WriteText(issues []Issue, out io.Writer) {
+	for _, issue := range issues {
+		fmt.Fprintln(out, issue.String())
+	}
+}
@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/model"
+)
+
+func lintFixture() *model.Package {
+	pos := func(line int) model.Position {
+		return model.Position{File: "models/user.go", Line: line, Column: 1}
+	}
+	return &model.Package{
+		Name:    "models",
+		Imports: []string{"io"},
+		Funcs: []model.Func{
+			{Name: "Open", Doc: "Open opens the store. See [io.Reader] and [fmt].", Pos: pos(3)},
+			{Name: "Close", Doc: "closes the store.", Pos: pos(5)},
+			{
+				Name:   "Save",
+				Doc:    "Save writes u. See [User.Store].\n\nParameters:\n  - user: The user to save\n  - w: The destination\n\nReturns:\n  - error: Any write error\n",
+				Params: []string{"u", "w"},
+				Pos:    pos(7),
+			},
+		},
+		Types: []model.Type{{
+			Name:        "User",
+			Kind:        "struct",
+			Doc:         "A User is an account",
+			Deprecated:  true,
+			Deprecation: "no longer supported.",
+			Pos:         pos(12),
+			Methods: []model.Func{
+				{Name: "Name", Recv: "User", Doc: "Name returns the [User.Missing] name.", Pos: pos(20)},
+				{Name: "Load", Recv: "User", Doc: "Load reads the user.", Deprecated: true, Deprecation: "Use [User.Name] instead.", Pos: pos(22)},
+			},
+		}},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	issues := Check([]*model.Package{lintFixture()})
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`models/user.go:5:1: name-prefix: comment on Close should start with "Close "`,
+		`models/user.go:7:1: stale-doc-link: doc link [User.Store] in comment on Save does not resolve`,
+		`models/user.go:7:1: parameters: Save documents parameter "user" which does not exist`,
+		`models/user.go:7:1: parameters: Save does not document parameter "u"`,
+		`models/user.go:12:1: deprecated-replacement: deprecation notice of User should name a replacement`,
+		`models/user.go:12:1: period: comment on User should end with a period`,
+		`models/user.go:20:1: stale-doc-link: doc link [User.Missing] in comment on User.Name does not resolve`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	issues := Check([]*model.Package{lintFixture()})
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(issues, &buf); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log:\n%s", buf.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) {
+		t.Errorf("expected %d rules, got %d", len(Rules), len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != len(issues) {
+		t.Fatalf("expected %d results, got %d", len(issues), len(run.Results))
+	}
+	loc := run.Results[0].Locations[0].PhysicalLocation
+	if run.Results[0].RuleID != RuleNamePrefix || loc.ArtifactLocation.URI != "models/user.go" || loc.Region.StartLine != 5 {
+		t.Errorf("unexpected first result %+v", run.Results[0])
+	}
+}
@@ -0,0 +1,96 @@
+package lint
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SARIF 2.1.0 log structures, limited to the properties godocmd reports.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+// WriteSARIF writes issues as a SARIF 2.1.0 log, the format accepted by GitHub
+// code scanning and most CI annotation tools.
+//
+// Parameters:
+//   - issues: The issues to write
+//   - out: The writer to output the log to
+//
+// Returns:
+//   - error: Any error encountered while encoding the log
+func WriteSARIF(issues []Issue, out io.Writer) error {
+	driver := sarifDriver{
+		Name:           "godocmd",
+		InformationURI: "https://github.com/thinktide/godocmd",
+	}
+	for _, r := range Rules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: r.ID, ShortDescription: sarifMessage{Text: r.Description}})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		results = append(results, sarifResult{
+			RuleID:  issue.Rule,
+			Level:   "warning",
+			Message: sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: issue.Pos.File},
+					Region:           sarifRegion{StartLine: issue.Pos.Line, StartColumn: issue.Pos.Column},
+				},
+			}},
+		})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
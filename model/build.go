@@ -0,0 +1,635 @@
+package model
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/thinktide/godocmd/errdoc"
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/parse"
+)
+
+// Options controls which symbols Build includes in the model.
+type Options struct {
+	// IncludePrivate includes non-exported (private) symbols and fields.
+	IncludePrivate bool
+
+	// IncludeUndocumented includes symbols that lack GoDoc comments.
+	IncludeUndocumented bool
+
+	// HideDeprecated omits symbols, fields and constants marked "Deprecated:".
+	HideDeprecated bool
+
+	// Implementations relates the package's types to the interfaces they
+	// implement and resolves ValueSpec.Types and Constants. Types list no
+	// implementations, and value specs no resolved types, when it is nil.
+	Implementations *implements.Matrix
+}
+
+// builder carries the state shared while converting one package.
+type builder struct {
+	fset *token.FileSet
+	opts Options
+	pkg  *parse.Package
+}
+
+// Build converts a loaded package into its documentation model, applying the
+// same visibility rules as the markdown renderer.
+//
+// Parameters:
+//   - pkg: The loaded package
+//   - opts: The visibility filters to apply
+//
+// Returns:
+//   - *Package: The documentation model of the package
+func Build(pkg *parse.Package, opts Options) *Package {
+	b := &builder{fset: pkg.Fset, opts: opts, pkg: pkg}
+	d := pkg.Doc
+
+	out := &Package{
+		Name:       d.Name,
+		ImportPath: pkg.ImportPath,
+		Dir:        pkg.Dir,
+		Doc:        strings.TrimSpace(d.Doc),
+		Synopsis:   d.Synopsis(d.Doc),
+		Imports:    d.Imports,
+		Consts:     b.values(d.Consts),
+		Vars:       b.values(d.Vars),
+		Funcs:      b.funcs(d.Funcs),
+		Examples:   b.examples(d.Examples),
+		Errors:     VisibleErrors(pkg.Errors, opts),
+		Command:    pkg.Command,
+	}
+	if pkg.Module != nil {
+		out.Module = pkg.Module.Path
+		out.GoVersion = pkg.Module.GoVersion
+	}
+	for _, f := range d.Filenames {
+		out.Files = append(out.Files, filepath.Base(f))
+	}
+
+	for _, t := range d.Types {
+		if !b.visible(t.Name, t.Doc) {
+			continue
+		}
+		out.Types = append(out.Types, b.typ(t))
+	}
+
+	markers := make([]string, 0, len(d.Notes))
+	for marker := range d.Notes {
+		markers = append(markers, marker)
+	}
+	sort.Strings(markers)
+	for _, marker := range markers {
+		for _, n := range d.Notes[marker] {
+			out.Notes = append(out.Notes, Note{
+				Marker: marker,
+				UID:    n.UID,
+				Body:   strings.TrimSpace(n.Body),
+				Pos:    b.position(n.Pos),
+			})
+		}
+	}
+
+	return out
+}
+
+// visible reports whether a symbol passes the configured filters.
+//
+// Parameters:
+//   - name: The symbol name
+//   - docText: The symbol's doc comment
+//
+// Returns:
+//   - bool: True if the symbol should be part of the model
+func (b *builder) visible(name, docText string) bool {
+	if !b.opts.IncludePrivate && !IsExported(name) {
+		return false
+	}
+	if !b.opts.IncludeUndocumented && strings.TrimSpace(docText) == "" {
+		return false
+	}
+	if _, _, deprecated := parse.SplitDeprecation(docText); deprecated && b.opts.HideDeprecated {
+		return false
+	}
+	return true
+}
+
+// VisibleErrors returns the catalogued errors that are visible under opts,
+// listing only the visible functions wrapping them. Undocumented errors are
+// kept, as their message still tells callers what to match.
+//
+// Parameters:
+//   - errs: The errors of the package
+//   - opts: The visibility options
+//
+// Returns:
+//   - []errdoc.Error: The visible errors
+func VisibleErrors(errs []errdoc.Error, opts Options) []errdoc.Error {
+	var out []errdoc.Error
+	for _, e := range errs {
+		if !opts.IncludePrivate && !IsExported(e.Name) {
+			continue
+		}
+		if _, _, deprecated := parse.SplitDeprecation(e.Doc); deprecated && opts.HideDeprecated {
+			continue
+		}
+		var wrappedBy []string
+		for _, fn := range e.WrappedBy {
+			if opts.IncludePrivate || exportedPath(fn) {
+				wrappedBy = append(wrappedBy, fn)
+			}
+		}
+		e.WrappedBy = wrappedBy
+		out = append(out, e)
+	}
+	return out
+}
+
+// exportedPath reports whether every element of a dotted name such as
+// "Store.Load" is exported.
+//
+// Parameters:
+//   - name: The name
+//
+// Returns:
+//   - bool: True if all elements are exported
+func exportedPath(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if !IsExported(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// funcs converts the visible functions or methods of a list.
+//
+// Parameters:
+//   - funcs: The documented functions
+//
+// Returns:
+//   - []Func: The visible functions in source order of go/doc
+func (b *builder) funcs(funcs []*doc.Func) []Func {
+	var out []Func
+	for _, f := range funcs {
+		if !b.visible(f.Name, f.Doc) {
+			continue
+		}
+		body, notice, deprecated := parse.SplitDeprecation(f.Doc)
+		fn := Func{
+			Name:        f.Name,
+			Decl:        b.funcDecl(f.Decl),
+			Doc:         body,
+			Deprecated:  deprecated,
+			Deprecation: notice,
+			Pos:         b.position(f.Decl.Pos()),
+			Examples:    b.examples(f.Examples),
+		}
+		if f.Recv != "" {
+			fn.Recv = strings.TrimPrefix(f.Recv, "*")
+			if i := strings.Index(fn.Recv, "["); i >= 0 {
+				fn.Recv = fn.Recv[:i]
+			}
+			fn.Platforms = b.pkg.OnlyOn(fn.Recv + "." + f.Name)
+		} else {
+			fn.Platforms = b.pkg.OnlyOn(f.Name)
+		}
+		if f.Decl.Type.Params != nil {
+			for _, p := range f.Decl.Type.Params.List {
+				for _, n := range p.Names {
+					fn.Params = append(fn.Params, n.Name)
+				}
+			}
+		}
+		out = append(out, fn)
+	}
+	return out
+}
+
+// typ converts a documented type and its associated declarations.
+//
+// Parameters:
+//   - t: The documented type
+//
+// Returns:
+//   - Type: The type model
+func (b *builder) typ(t *doc.Type) Type {
+	body, notice, deprecated := parse.SplitDeprecation(t.Doc)
+	out := Type{
+		Name:        t.Name,
+		Kind:        "other",
+		Doc:         body,
+		Deprecated:  deprecated,
+		Deprecation: notice,
+		Consts:      b.values(t.Consts),
+		Vars:        b.values(t.Vars),
+		Funcs:       b.funcs(t.Funcs),
+		Methods:     b.funcs(t.Methods),
+		Examples:    b.examples(t.Examples),
+		Platforms:   b.pkg.OnlyOn(t.Name),
+	}
+	out.Implements = b.opts.Implementations.Implements(b.pkg, t.Name)
+	out.ImplementedBy = b.opts.Implementations.ImplementedBy(b.pkg, t.Name)
+
+	for _, spec := range t.Decl.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != t.Name {
+			continue
+		}
+		out.Pos = b.position(ts.Pos())
+		spec := *ts
+		spec.Doc, spec.Comment = nil, nil
+		out.Decl = b.print(&ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{&spec}})
+
+		switch typ := ts.Type.(type) {
+		case *ast.StructType:
+			out.Kind = "struct"
+			out.Fields = b.fields(typ.Fields)
+		case *ast.InterfaceType:
+			out.Kind = "interface"
+			out.Fields = b.fields(typ.Methods)
+		default:
+			if ts.Assign.IsValid() {
+				out.Kind = "alias"
+			}
+		}
+	}
+	return out
+}
+
+// fields converts the visible entries of a struct field list or interface method list.
+//
+// Parameters:
+//   - list: The AST field list
+//
+// Returns:
+//   - []Field: The visible fields, one per declared name
+func (b *builder) fields(list *ast.FieldList) []Field {
+	if list == nil {
+		return nil
+	}
+	var out []Field
+	for _, f := range list.List {
+		docText := parse.JoinComments(f.Doc, f.Comment)
+		_, notice, deprecated := parse.SplitDeprecation(docText)
+		if deprecated && b.opts.HideDeprecated {
+			continue
+		}
+
+		field := Field{
+			Type:        b.print(f.Type),
+			Doc:         strings.TrimSpace(f.Doc.Text()),
+			Comment:     strings.TrimSpace(f.Comment.Text()),
+			Deprecated:  deprecated,
+			Deprecation: notice,
+			Pos:         b.position(f.Pos()),
+		}
+		if f.Tag != nil {
+			field.Tag = strings.Trim(f.Tag.Value, "`")
+			field.Tags = ParseTag(field.Tag)
+		}
+
+		if len(f.Names) == 0 {
+			field.Embedded = true
+			field.Name = embeddedName(f.Type)
+			if b.opts.IncludePrivate || IsExported(field.Name) {
+				out = append(out, field)
+			}
+			continue
+		}
+		for _, n := range f.Names {
+			if !b.opts.IncludePrivate && !IsExported(n.Name) {
+				continue
+			}
+			named := field
+			named.Name = n.Name
+			named.Pos = b.position(n.Pos())
+			out = append(out, named)
+		}
+	}
+	return out
+}
+
+// values converts the visible const or var groups of a list.
+//
+// Parameters:
+//   - values: The documented declaration groups
+//
+// Returns:
+//   - []Value: The visible groups with their visible specs
+func (b *builder) values(values []*doc.Value) []Value {
+	var out []Value
+	for _, v := range values {
+		if !b.opts.IncludeUndocumented && !parse.ValueDocumented(v) {
+			continue
+		}
+		body, notice, deprecated := parse.SplitDeprecation(v.Doc)
+		if deprecated && b.opts.HideDeprecated {
+			continue
+		}
+
+		val := Value{
+			Kind:        v.Decl.Tok.String(),
+			Doc:         body,
+			Deprecated:  deprecated,
+			Deprecation: notice,
+			Pos:         b.position(v.Decl.Pos()),
+		}
+		var specs []ast.Spec
+		var declared []string
+		for _, spec := range v.Decl.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, n := range vs.Names {
+				declared = append(declared, n.Name)
+			}
+			var names []string
+			for _, n := range vs.Names {
+				if b.opts.IncludePrivate || IsExported(n.Name) {
+					names = append(names, n.Name)
+				}
+			}
+			if len(names) == 0 {
+				continue
+			}
+			_, specNotice, specDeprecated := parse.SplitDeprecation(parse.JoinComments(vs.Doc, vs.Comment))
+			if specDeprecated && b.opts.HideDeprecated {
+				continue
+			}
+
+			s := ValueSpec{
+				Names:       names,
+				Doc:         strings.TrimSpace(vs.Doc.Text()),
+				Comment:     strings.TrimSpace(vs.Comment.Text()),
+				Deprecated:  specDeprecated,
+				Deprecation: specNotice,
+				Pos:         b.position(vs.Pos()),
+			}
+			if vs.Type != nil {
+				s.Type = b.print(vs.Type)
+			}
+			for _, x := range vs.Values {
+				s.Values = append(s.Values, b.print(x))
+			}
+			s.Types, s.Constants = b.checked(names, v.Decl.Tok == token.CONST)
+			val.Specs = append(val.Specs, s)
+
+			cp := *vs
+			cp.Doc, cp.Comment = nil, nil
+			specs = append(specs, &cp)
+		}
+		if len(val.Specs) == 0 {
+			continue
+		}
+
+		decl := &ast.GenDecl{Tok: v.Decl.Tok, Specs: specs}
+		if len(specs) > 1 {
+			decl.Lparen = v.Decl.Lparen
+		}
+		val.Decl = b.print(decl)
+		val.Platforms = b.pkg.OnlyOn(declared...)
+		out = append(out, val)
+	}
+	return out
+}
+
+// checked looks up the type-checked type and constant value of each name in
+// Options.Implementations, including those of iota repetitions and inferred
+// types that the declaration does not spell out.
+//
+// Parameters:
+//   - names: The declared names
+//   - isConst: Whether the names are constants
+//
+// Returns:
+//   - []string: The type of each name, empty where it could not be resolved; nil if none could
+//   - []string: The exact value of each constant, empty where unknown; nil for variables
+func (b *builder) checked(names []string, isConst bool) ([]string, []string) {
+	typs := make([]string, len(names))
+	var consts []string
+	if isConst {
+		consts = make([]string, len(names))
+	}
+	found := false
+	for i, name := range names {
+		obj := b.opts.Implementations.Lookup(b.pkg, name)
+		if obj == nil || obj.Type() == types.Typ[types.Invalid] {
+			continue
+		}
+		qualifier := func(p *types.Package) string {
+			if p == obj.Pkg() {
+				return ""
+			}
+			return p.Name()
+		}
+		typs[i] = types.TypeString(obj.Type(), qualifier)
+		if c, ok := obj.(*types.Const); ok && isConst && c.Val().Kind() != constant.Unknown {
+			consts[i] = c.Val().ExactString()
+		}
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return typs, consts
+}
+
+// examples converts examples, printing their code.
+//
+// Parameters:
+//   - examples: The examples attached to a symbol
+//
+// Returns:
+//   - []Example: The example models
+func (b *builder) examples(examples []*doc.Example) []Example {
+	var out []Example
+	for _, ex := range examples {
+		code, err := ExampleCode(b.fset, ex)
+		if err != nil {
+			continue
+		}
+		out = append(out, Example{
+			Name:   ex.Name,
+			Suffix: ex.Suffix,
+			Doc:    ex.Doc,
+			Code:   code,
+			Output: ex.Output,
+		})
+	}
+	return out
+}
+
+// funcDecl prints a function declaration without its body or doc comment.
+//
+// Parameters:
+//   - decl: The function declaration
+//
+// Returns:
+//   - string: The signature as Go source
+func (b *builder) funcDecl(decl *ast.FuncDecl) string {
+	cp := *decl
+	cp.Doc, cp.Body = nil, nil
+	return b.print(&cp)
+}
+
+// print renders an AST node as Go source.
+//
+// Parameters:
+//   - node: The node to print
+//
+// Returns:
+//   - string: The Go source of the node
+func (b *builder) print(node ast.Node) string {
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 4}
+	if err := cfg.Fprint(&buf, b.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// position resolves a token position into a Position.
+//
+// Parameters:
+//   - pos: The token position
+//
+// Returns:
+//   - Position: The file, line and column of the position
+func (b *builder) position(pos token.Pos) Position {
+	if b.fset == nil || !pos.IsValid() {
+		return Position{}
+	}
+	p := b.fset.Position(pos)
+	return Position{File: filepath.ToSlash(p.Filename), Line: p.Line, Column: p.Column}
+}
+
+// ExampleCode prints the body of an example function without its surrounding
+// braces and output comment.
+//
+// Parameters:
+//   - fset: The file set the example was parsed with
+//   - ex: The example to print
+//
+// Returns:
+//   - string: The example source code
+//   - error: Any error encountered while printing the example
+func ExampleCode(fset *token.FileSet, ex *doc.Example) (string, error) {
+	var buf bytes.Buffer
+	node := &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments}
+	if err := (&printer.Config{Mode: printer.UseSpaces, Tabwidth: 4}).Fprint(&buf, fset, node); err != nil {
+		return "", err
+	}
+
+	code := buf.String()
+	if _, ok := ex.Code.(*ast.BlockStmt); ok {
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "{"), "}")
+		var lines []string
+		for _, line := range strings.Split(strings.Trim(code, "\n"), "\n") {
+			lines = append(lines, strings.TrimPrefix(line, "    "))
+		}
+		code = strings.Join(lines, "\n")
+	}
+
+	// Drop the output comment, which is rendered separately.
+	var kept []string
+	for _, line := range strings.Split(code, "\n") {
+		trimmed := strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(trimmed, "// output:") || strings.HasPrefix(trimmed, "// unordered output:") {
+			break
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), nil
+}
+
+// IsExported reports whether a symbol name is exported (starts with an uppercase letter).
+//
+// Parameters:
+//   - name: The identifier name to check
+//
+// Returns:
+//   - bool: True if exported, false otherwise
+func IsExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// ParseTag splits a struct tag into its key/value pairs following the
+// conventional `key:"value" key2:"value2"` format of reflect.StructTag.
+//
+// Parameters:
+//   - tag: The struct tag without backquotes
+//
+// Returns:
+//   - map[string]string: The tag values keyed by tag name, or nil if the tag is empty
+func ParseTag(tag string) map[string]string {
+	var tags map[string]string
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " \t")
+		i := strings.Index(tag, ":\"")
+		if i <= 0 || strings.ContainsAny(tag[:i], " \t\"") {
+			break
+		}
+		key := tag[:i]
+		rest := tag[i+1:]
+
+		// Find the closing quote, skipping escaped characters.
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			break
+		}
+		value, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			break
+		}
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		tags[key] = value
+		tag = rest[j+1:]
+	}
+	return tags
+}
+
+// embeddedName returns the field name implied by an embedded type.
+//
+// Parameters:
+//   - expr: The embedded type expression
+//
+// Returns:
+//   - string: The type name without package qualifier, pointer or type arguments
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return ""
+}
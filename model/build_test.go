@@ -0,0 +1,154 @@
+package model
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/thinktide/godocmd/implements"
+	"github.com/thinktide/godocmd/parse"
+)
+
+// loadSource is a test helper that builds a *parse.Package from raw Go source code.
+//
+// Parameters:
+//   - t: The test to fail on parse errors
+//   - code: Go source string to parse
+//
+// Returns:
+//   - *parse.Package: The loaded package
+func loadSource(t *testing.T, code string) *parse.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testpkg.go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docPkg, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/testpkg", doc.AllDecls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &parse.Package{Dir: ".", ImportPath: "example.com/testpkg", Doc: docPkg, Fset: fset, Files: []*ast.File{file}}
+}
+
+func TestBuild_TypesFieldsAndMethods(t *testing.T) {
+	pkg := loadSource(t, `
+package testpkg
+
+// User is a user.
+type User struct {
+	// Name is the display name.
+	Name string `+"`json:\"name\"`"+`
+	age  int
+}
+
+// Save saves the user.
+//
+// Deprecated: use Store.
+func (u *User) Save(force bool) error { return nil }
+
+// Storer stores users.
+type Storer interface {
+	Store(u User) error
+}
+
+func helper() {}
+`)
+
+	m := Build(pkg, Options{})
+	if len(m.Funcs) != 0 {
+		t.Errorf("expected private func to be filtered, got %+v", m.Funcs)
+	}
+	if len(m.Types) != 2 {
+		t.Fatalf("expected 2 types, got %d", len(m.Types))
+	}
+
+	user := m.Types[1]
+	if user.Name != "User" || user.Kind != "struct" {
+		t.Fatalf("unexpected type %s (%s)", user.Name, user.Kind)
+	}
+	if len(user.Fields) != 1 || user.Fields[0].Tag != `json:"name"` || user.Fields[0].Doc != "Name is the display name." {
+		t.Errorf("unexpected fields %+v", user.Fields)
+	}
+	if len(user.Methods) != 1 {
+		t.Fatalf("expected 1 method, got %d", len(user.Methods))
+	}
+	save := user.Methods[0]
+	if save.Recv != "User" || save.Decl != "func (u *User) Save(force bool) error" {
+		t.Errorf("unexpected method %+v", save)
+	}
+	if !save.Deprecated || save.Deprecation != "use Store." || save.Doc != "Save saves the user." {
+		t.Errorf("unexpected deprecation %+v", save)
+	}
+	if len(save.Params) != 1 || save.Params[0] != "force" {
+		t.Errorf("unexpected params %v", save.Params)
+	}
+	if save.Pos.Line == 0 {
+		t.Error("expected method position to be set")
+	}
+
+	storer := m.Types[0]
+	if storer.Kind != "interface" || len(storer.Fields) != 1 || storer.Fields[0].Name != "Store" {
+		t.Errorf("unexpected interface %+v", storer)
+	}
+
+	m = Build(pkg, Options{HideDeprecated: true})
+	if len(m.Types[1].Methods) != 0 {
+		t.Error("expected deprecated method to be hidden")
+	}
+}
+
+func TestBuild_CheckedValues(t *testing.T) {
+	pkg := loadSource(t, `
+package testpkg
+
+import "time"
+
+// Level is a log level.
+type Level int
+
+// Levels.
+const (
+	Debug Level = iota
+	Info
+	Max = 10
+)
+
+// Timeout is inferred.
+var Timeout = 2 * time.Second
+`)
+
+	m := Build(pkg, Options{IncludeUndocumented: true, Implementations: implements.Compute([]*parse.Package{pkg}, false)})
+	if len(m.Types) != 1 || len(m.Types[0].Consts) != 1 || len(m.Vars) != 1 {
+		t.Fatalf("unexpected values %+v %+v", m.Types, m.Vars)
+	}
+	specs := m.Types[0].Consts[0].Specs
+	if len(specs) != 3 {
+		t.Fatalf("expected 3 const specs, got %+v", specs)
+	}
+	if info := specs[1]; info.Type != "" || len(info.Values) != 0 || info.Types[0] != "Level" || info.Constants[0] != "1" {
+		t.Errorf("expected the iota repetition Info to resolve to Level 1, got %+v", info)
+	}
+	if max := specs[2]; max.Types[0] != "untyped int" || max.Constants[0] != "10" {
+		t.Errorf("unexpected Max %+v", max)
+	}
+	if timeout := m.Vars[0].Specs[0]; timeout.Types[0] != "time.Duration" || timeout.Constants != nil {
+		t.Errorf("expected the inferred type time.Duration, got %+v", timeout)
+	}
+
+	if m := Build(pkg, Options{IncludeUndocumented: true}); m.Types[0].Consts[0].Specs[1].Types != nil {
+		t.Errorf("expected no resolved types without type information, got %+v", m.Types[0].Consts[0].Specs[1])
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tags := ParseTag(`json:"name,omitempty" env:"NAME" envDefault:"a \"b\""`)
+	if tags["json"] != "name,omitempty" || tags["env"] != "NAME" || tags["envDefault"] != `a "b"` {
+		t.Errorf("unexpected tags %v", tags)
+	}
+	if ParseTag("") != nil {
+		t.Error("expected nil tags for an empty tag")
+	}
+}
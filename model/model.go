@@ -0,0 +1,144 @@
+package model
+
+import (
+	"github.com/thinktide/godocmd/command"
+	"github.com/thinktide/godocmd/errdoc"
+	"github.com/thinktide/godocmd/implements"
+)
+
+// Version identifies the layout of the JSON encoding of Document. It is
+// incremented whenever a field is renamed or removed or its meaning changes;
+// adding fields does not change the version.
+const Version = 1
+
+// Document is the top-level JSON representation of a documentation run.
+type Document struct {
+	Version  int        `json:"version"`
+	Packages []*Package `json:"packages"`
+}
+
+// Package is the renderer-neutral documentation of a single Go package.
+type Package struct {
+	Name       string    `json:"name"`
+	ImportPath string    `json:"importPath,omitempty"`
+	Module     string    `json:"module,omitempty"`    // the path of the enclosing module
+	GoVersion  string    `json:"goVersion,omitempty"` // the go directive of the enclosing module
+	Dir        string    `json:"dir,omitempty"`
+	Doc        string    `json:"doc,omitempty"`
+	Synopsis   string    `json:"synopsis,omitempty"`
+	Files      []string  `json:"files,omitempty"`
+	Imports    []string  `json:"imports,omitempty"`
+	Consts     []Value   `json:"consts,omitempty"`
+	Vars       []Value   `json:"vars,omitempty"`
+	Funcs      []Func    `json:"funcs,omitempty"`
+	Types      []Type    `json:"types,omitempty"`
+	Notes      []Note    `json:"notes,omitempty"`
+	Examples   []Example `json:"examples,omitempty"`
+
+	// Errors lists the sentinel errors and error types callers can match.
+	Errors []errdoc.Error `json:"errors,omitempty"`
+
+	// Command describes the command line interface of a main package.
+	Command *command.Command `json:"command,omitempty"`
+}
+
+// Position identifies a location in a source file.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Func describes a function or method.
+type Func struct {
+	Name        string    `json:"name"`
+	Recv        string    `json:"recv,omitempty"` // receiver type name without pointer, empty for functions
+	Decl        string    `json:"decl,omitempty"` // the signature as Go source, e.g. "func (u *User) Save() error"
+	Params      []string  `json:"params,omitempty"`
+	Doc         string    `json:"doc,omitempty"` // the doc comment without its deprecation paragraph
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	Deprecation string    `json:"deprecation,omitempty"` // the text following "Deprecated:"
+	Pos         Position  `json:"pos"`
+	Examples    []Example `json:"examples,omitempty"`
+	Platforms   []string  `json:"platforms,omitempty"` // the "goos/goarch" platforms declaring it, if not all documented ones
+}
+
+// Type describes a named type together with its associated declarations.
+type Type struct {
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`           // "struct", "interface", "alias" or "other"
+	Decl        string    `json:"decl,omitempty"` // the type declaration as Go source
+	Doc         string    `json:"doc,omitempty"`
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	Deprecation string    `json:"deprecation,omitempty"`
+	Fields      []Field   `json:"fields,omitempty"` // struct fields or interface methods
+	Consts      []Value   `json:"consts,omitempty"`
+	Vars        []Value   `json:"vars,omitempty"`
+	Funcs       []Func    `json:"funcs,omitempty"` // functions returning the type, such as constructors
+	Methods     []Func    `json:"methods,omitempty"`
+	Pos         Position  `json:"pos"`
+	Examples    []Example `json:"examples,omitempty"`
+	Platforms   []string  `json:"platforms,omitempty"`
+
+	// Implements lists the interfaces a concrete type implements, and
+	// ImplementedBy the concrete types implementing an interface.
+	Implements    []implements.Ref `json:"implements,omitempty"`
+	ImplementedBy []implements.Ref `json:"implementedBy,omitempty"`
+}
+
+// Field describes a struct field or an interface method.
+type Field struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type,omitempty"`
+	Tag         string            `json:"tag,omitempty"`  // the raw struct tag without backquotes
+	Tags        map[string]string `json:"tags,omitempty"` // the struct tag as key/value pairs
+	Embedded    bool              `json:"embedded,omitempty"`
+	Doc         string            `json:"doc,omitempty"`
+	Comment     string            `json:"comment,omitempty"` // the trailing line comment
+	Deprecated  bool              `json:"deprecated,omitempty"`
+	Deprecation string            `json:"deprecation,omitempty"`
+	Pos         Position          `json:"pos"`
+}
+
+// Value describes a const or var declaration group.
+type Value struct {
+	Kind        string      `json:"kind"` // "const" or "var"
+	Decl        string      `json:"decl,omitempty"`
+	Doc         string      `json:"doc,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
+	Deprecation string      `json:"deprecation,omitempty"`
+	Specs       []ValueSpec `json:"specs,omitempty"`
+	Pos         Position    `json:"pos"`
+	Platforms   []string    `json:"platforms,omitempty"`
+}
+
+// ValueSpec describes one line of a const or var declaration group.
+type ValueSpec struct {
+	Names       []string `json:"names"`
+	Type        string   `json:"type,omitempty"`
+	Values      []string `json:"values,omitempty"`
+	Types       []string `json:"types,omitempty"`     // the type-checked type of each name, empty where unknown
+	Constants   []string `json:"constants,omitempty"` // the exact value of each constant name, empty where unknown
+	Doc         string   `json:"doc,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Deprecation string   `json:"deprecation,omitempty"`
+	Pos         Position `json:"pos"`
+}
+
+// Note describes a `MARKER(uid): body` note such as a BUG note.
+type Note struct {
+	Marker string   `json:"marker"`
+	UID    string   `json:"uid,omitempty"`
+	Body   string   `json:"body"`
+	Pos    Position `json:"pos"`
+}
+
+// Example describes an example function from a _test.go file.
+type Example struct {
+	Name   string `json:"name"` // the exemplified symbol including its suffix, e.g. "User_Save_json"
+	Suffix string `json:"suffix,omitempty"`
+	Doc    string `json:"doc,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Output string `json:"output,omitempty"`
+}
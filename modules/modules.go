@@ -0,0 +1,398 @@
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoModule is returned when no go.mod file encloses a directory.
+var ErrNoModule = errors.New("no go.mod found")
+
+// Module describes a Go module declared by a go.mod file.
+type Module struct {
+	// Dir is the directory containing the go.mod file.
+	Dir string
+
+	// Path is the module path from the module directive.
+	Path string
+
+	// GoVersion is the version from the go directive, if any.
+	GoVersion string
+}
+
+// Workspace describes a go.work file and the modules it uses.
+type Workspace struct {
+	// Dir is the directory containing the go.work file.
+	Dir string
+
+	// GoVersion is the version from the go directive, if any.
+	GoVersion string
+
+	// Modules lists the modules named by use directives.
+	Modules []Module
+}
+
+// ReadModule parses the go.mod file in dir.
+//
+// Parameters:
+//   - dir: The directory containing the go.mod file
+//
+// Returns:
+//   - *Module: The module declared by the file
+//   - error: Any error encountered reading or parsing the file
+func ReadModule(dir string) (*Module, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &Module{Dir: dir}
+	for _, d := range directives(data) {
+		switch d.verb {
+		case "module":
+			mod.Path = d.arg
+		case "go":
+			mod.GoVersion = d.arg
+		}
+	}
+	if mod.Path == "" {
+		return nil, fmt.Errorf("%s: missing module directive", filepath.Join(dir, "go.mod"))
+	}
+	return mod, nil
+}
+
+// ReadWorkspace parses the go.work file in dir and the go.mod file of every module it uses.
+//
+// Parameters:
+//   - dir: The directory containing the go.work file
+//
+// Returns:
+//   - *Workspace: The workspace and its modules
+//   - error: Any error encountered reading or parsing the files
+func ReadWorkspace(dir string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.work"))
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Dir: dir}
+	for _, d := range directives(data) {
+		switch d.verb {
+		case "go":
+			ws.GoVersion = d.arg
+		case "use":
+			modDir := filepath.FromSlash(d.arg)
+			if !filepath.IsAbs(modDir) {
+				modDir = filepath.Join(dir, modDir)
+			}
+			mod, err := ReadModule(modDir)
+			if err != nil {
+				return nil, fmt.Errorf("go.work: %w", err)
+			}
+			ws.Modules = append(ws.Modules, *mod)
+		}
+	}
+	return ws, nil
+}
+
+// Find returns the module containing dir. A module listed in an enclosing
+// go.work file takes precedence, unless a go.mod closer to dir declares a
+// nested module the workspace does not use; otherwise the nearest go.mod found
+// walking upward is used.
+//
+// Parameters:
+//   - dir: The package directory to resolve
+//
+// Returns:
+//   - *Module: The module containing the directory
+//   - error: ErrNoModule if no module encloses the directory, or any read error
+func Find(dir string) (*Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	_, mod, err := workspaceModule(abs)
+	if err != nil || mod != nil {
+		return mod, err
+	}
+
+	modDir, ok := findUp(abs, "go.mod")
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNoModule)
+	}
+	return ReadModule(modDir)
+}
+
+// FindWorkspace returns the go.work workspace that uses the module containing dir.
+//
+// Parameters:
+//   - dir: The package directory to resolve
+//
+// Returns:
+//   - *Workspace: The enclosing workspace, or nil if dir is not in one of its modules
+//   - error: Any error encountered reading the go.work file or its modules
+func FindWorkspace(dir string) (*Workspace, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, mod, err := workspaceModule(abs)
+	if err != nil || mod == nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// workspaceModule returns the enclosing go.work workspace and its module
+// containing dir. A go.mod between dir and that module's directory declares a
+// nested module the workspace does not use, so dir is then outside the workspace.
+//
+// Parameters:
+//   - dir: The absolute package directory to resolve
+//
+// Returns:
+//   - *Workspace: The enclosing workspace, or nil if dir is not in one of its modules
+//   - *Module: The workspace module containing dir, or nil
+//   - error: Any error encountered reading the go.work file or its modules
+func workspaceModule(dir string) (*Workspace, *Module, error) {
+	wsDir, ok := findUp(dir, "go.work")
+	if !ok {
+		return nil, nil, nil
+	}
+	ws, err := ReadWorkspace(wsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	mod := ws.Module(dir)
+	if mod == nil {
+		return nil, nil, nil
+	}
+	if modDir, ok := findUp(dir, "go.mod"); ok && len(modDir) > len(mod.Dir) {
+		return nil, nil, nil
+	}
+	return ws, mod, nil
+}
+
+// Module returns the workspace module whose directory most closely encloses dir.
+//
+// Parameters:
+//   - dir: The absolute directory to look up
+//
+// Returns:
+//   - *Module: The enclosing module, or nil if none of the workspace modules contain dir
+func (ws *Workspace) Module(dir string) *Module {
+	var best *Module
+	for i := range ws.Modules {
+		mod := &ws.Modules[i]
+		modDir, err := filepath.Abs(mod.Dir)
+		if err != nil || !within(modDir, dir) {
+			continue
+		}
+		if best == nil || len(modDir) > len(best.Dir) {
+			best = &Module{Dir: modDir, Path: mod.Path, GoVersion: mod.GoVersion}
+		}
+	}
+	return best
+}
+
+// ImportPath returns the import path of the package in dir, which must lie
+// inside the module's directory.
+//
+// Parameters:
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The full import path of the package
+//   - error: Any error if dir is not inside the module
+func (m *Module) ImportPath(dir string) (string, error) {
+	modDir, err := filepath.Abs(m.Dir)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(modDir, abs)
+	if err != nil || !within(modDir, abs) {
+		return "", fmt.Errorf("%s is not inside module %s", dir, m.Path)
+	}
+	if rel == "." {
+		return m.Path, nil
+	}
+	return path.Join(m.Path, filepath.ToSlash(rel)), nil
+}
+
+// ImportPath resolves the full import path of the package in dir from its enclosing module.
+//
+// Parameters:
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The full import path of the package
+//   - error: ErrNoModule if no module encloses the directory, or any read error
+func ImportPath(dir string) (string, error) {
+	mod, err := Find(dir)
+	if err != nil {
+		return "", err
+	}
+	return mod.ImportPath(dir)
+}
+
+// Group is a module together with the package directories it contains.
+type Group struct {
+	// Module is the enclosing module, or nil for directories outside any module.
+	Module *Module
+
+	// Dirs lists the package directories in their original order.
+	Dirs []string
+}
+
+// GroupDirs groups package directories by their enclosing module (see Find).
+// Directories outside any module come first, followed by the modules sorted by
+// path, so that the order does not depend on where nested modules live.
+//
+// Parameters:
+//   - dirs: The package directories
+//
+// Returns:
+//   - []Group: The directories of each module
+//   - error: Any error other than ErrNoModule encountered resolving a module
+func GroupDirs(dirs []string) ([]Group, error) {
+	var groups []Group
+	index := map[string]int{}
+	for _, dir := range dirs {
+		key := ""
+		mod, err := Find(dir)
+		if err != nil {
+			if !errors.Is(err, ErrNoModule) {
+				return nil, err
+			}
+		} else {
+			key = mod.Path
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Module: mod})
+		}
+		groups[i].Dirs = append(groups[i].Dirs, dir)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Module == nil) != (groups[j].Module == nil) {
+			return groups[i].Module == nil
+		}
+		return groups[i].Module != nil && groups[i].Module.Path < groups[j].Module.Path
+	})
+	return groups, nil
+}
+
+// findUp searches dir and its parents for a file with the given name.
+//
+// Parameters:
+//   - dir: The absolute directory to start from
+//   - name: The file name to look for
+//
+// Returns:
+//   - string: The directory containing the file
+//   - bool: True if the file was found
+func findUp(dir, name string) (string, bool) {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// within reports whether path is dir or one of its descendants.
+//
+// Parameters:
+//   - dir: The absolute parent directory
+//   - path: The absolute path to test
+//
+// Returns:
+//   - bool: True if path lies inside dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// directive is a single verb/argument pair from a go.mod or go.work file.
+type directive struct {
+	verb string
+	arg  string
+}
+
+// directives extracts the verb and first argument of each line in a go.mod or
+// go.work file, expanding parenthesised blocks such as `use ( ... )`.
+//
+// Parameters:
+//   - data: The file contents
+//
+// Returns:
+//   - []directive: The directives in file order
+func directives(data []byte) []directive {
+	var out []directive
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			out = append(out, directive{verb: block, arg: unquote(fields[0])})
+			continue
+		}
+
+		if len(fields) >= 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if len(fields) >= 2 {
+			out = append(out, directive{verb: fields[0], arg: unquote(fields[1])})
+		}
+	}
+	return out
+}
+
+// unquote removes Go string quotes from a go.mod token if present.
+//
+// Parameters:
+//   - s: The token to unquote
+//
+// Returns:
+//   - string: The unquoted token
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
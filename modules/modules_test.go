@@ -0,0 +1,119 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportPath_NestedModule(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root // main module\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "tools", "go.mod"), "module \"example.com/tools\"\n")
+
+	got, err := ImportPath(filepath.Join(root, "models", "user"))
+	if err != nil {
+		t.Fatalf("ImportPath failed: %v", err)
+	}
+	if got != "example.com/root/models/user" {
+		t.Errorf("expected example.com/root/models/user, got %s", got)
+	}
+
+	got, err = ImportPath(filepath.Join(root, "tools", "gen"))
+	if err != nil {
+		t.Fatalf("ImportPath failed: %v", err)
+	}
+	if got != "example.com/tools/gen" {
+		t.Errorf("expected nested module path example.com/tools/gen, got %s", got)
+	}
+}
+
+func TestFind_Workspace(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.work"), "go 1.22\n\nuse (\n\t./api\n\t./web // frontend\n)\n")
+	writeFile(t, filepath.Join(root, "api", "go.mod"), "module example.com/api\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(root, "web", "go.mod"), "module example.com/web\n")
+
+	mod, err := Find(filepath.Join(root, "api", "handlers"))
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if mod.Path != "example.com/api" || mod.GoVersion != "1.21" {
+		t.Errorf("unexpected module %+v", mod)
+	}
+
+	ws, err := ReadWorkspace(root)
+	if err != nil {
+		t.Fatalf("ReadWorkspace failed: %v", err)
+	}
+	if len(ws.Modules) != 2 || ws.GoVersion != "1.22" {
+		t.Errorf("unexpected workspace %+v", ws)
+	}
+}
+
+func TestFind_NestedModuleInWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.work"), "go 1.22\n\nuse ./api\n")
+	writeFile(t, filepath.Join(root, "api", "go.mod"), "module example.com/api\n")
+	writeFile(t, filepath.Join(root, "api", "tools", "go.mod"), "module example.com/tools\n\ngo 1.23\n")
+
+	// api/tools is a nested module the workspace does not use.
+	dir := filepath.Join(root, "api", "tools", "gen")
+	mod, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if mod.Path != "example.com/tools" || mod.GoVersion != "1.23" {
+		t.Errorf("expected the nested module example.com/tools, got %+v", mod)
+	}
+	if ws, err := FindWorkspace(dir); err != nil || ws != nil {
+		t.Errorf("expected no workspace for the nested module, got %+v, %v", ws, err)
+	}
+
+	mod, err = Find(filepath.Join(root, "api", "handlers"))
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if mod.Path != "example.com/api" {
+		t.Errorf("expected the workspace module example.com/api, got %+v", mod)
+	}
+}
+
+func TestGroupDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "tools", "go.mod"), "module example.com/aaa/tools\n\ngo 1.23\n")
+
+	dirs := []string{
+		filepath.Join(root, "api"),
+		filepath.Join(root, "tools", "gen"),
+		root,
+		filepath.Join(root, "tools"),
+	}
+	groups, err := GroupDirs(dirs)
+	if err != nil {
+		t.Fatalf("GroupDirs failed: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	if groups[0].Module.Path != "example.com/aaa/tools" || groups[0].Module.GoVersion != "1.23" {
+		t.Errorf("unexpected first module %+v", groups[0].Module)
+	}
+	if len(groups[0].Dirs) != 2 || groups[0].Dirs[0] != dirs[1] || groups[0].Dirs[1] != dirs[3] {
+		t.Errorf("unexpected tools dirs %v", groups[0].Dirs)
+	}
+	if groups[1].Module.Path != "example.com/root" || len(groups[1].Dirs) != 2 || groups[1].Dirs[1] != root {
+		t.Errorf("unexpected root group %+v", groups[1])
+	}
+}
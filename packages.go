@@ -0,0 +1,131 @@
+package godocmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/thinktide/godocmd/discover"
+	"github.com/thinktide/godocmd/parse"
+)
+
+// PackageOptions overrides the visibility options of the packages matching
+// Pattern. Nil fields keep the value from Options.
+type PackageOptions struct {
+	// Pattern selects package directories relative to Options.PatternDir (see
+	// discover.Match), e.g. "internal/legacy" or "./api/...".
+	Pattern string
+
+	IncludePrivate      *bool
+	IncludeUndocumented *bool
+	HideDeprecated      *bool
+	NoteMarkers         []string
+}
+
+// forPackage returns the options that apply to the package in dir, with the
+// overrides of every matching Options.Packages entry applied in order.
+//
+// Parameters:
+//   - rootDir: The scanned root directory, the default pattern directory
+//   - dir: The package directory
+//
+// Returns:
+//   - Options: The options for the package
+func (o Options) forPackage(rootDir, dir string) Options {
+	if len(o.Packages) == 0 {
+		return o
+	}
+	rel, ok := o.patternPath(rootDir, dir)
+	if !ok {
+		return o
+	}
+	for _, p := range o.Packages {
+		if !discover.Match(p.Pattern, rel) {
+			continue
+		}
+		if p.IncludePrivate != nil {
+			o.IncludePrivate = *p.IncludePrivate
+		}
+		if p.IncludeUndocumented != nil {
+			o.IncludeUndocumented = *p.IncludeUndocumented
+		}
+		if p.HideDeprecated != nil {
+			o.HideDeprecated = *p.HideDeprecated
+		}
+		if p.NoteMarkers != nil {
+			o.NoteMarkers = p.NoteMarkers
+		}
+	}
+	return o
+}
+
+// patternPath returns the slash-separated path of dir relative to
+// Options.PatternDir, or to rootDir when it is not set.
+//
+// Parameters:
+//   - rootDir: The scanned root directory
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The relative path, "." for the pattern directory itself
+//   - bool: False if dir is not inside the pattern directory
+func (o Options) patternPath(rootDir, dir string) (string, bool) {
+	base := o.PatternDir
+	if base == "" {
+		base = rootDir
+	}
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return "", false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absBase, absDir)
+	if err != nil || rel == ".." || (len(rel) > 2 && rel[:3] == ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// loadPackages loads the packages of dir and applies Options.SkipMain,
+// Options.AllPackages and Options.ExternalTests, logging the packages left out.
+//
+// Parameters:
+//   - dir: The package directory
+//   - opts: The options for the directory
+//
+// Returns:
+//   - []*parse.Package: The packages to document, the primary package first
+//   - error: An error if no package is left to document or the directory cannot be parsed
+func loadPackages(dir string, opts Options) ([]*parse.Package, error) {
+	all, err := parse.LoadAll(dir, opts.buildOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []*parse.Package
+	for _, pkg := range all {
+		name := pkg.Doc.Name
+		switch {
+		case pkg.ExternalTest:
+			if !opts.ExternalTests {
+				continue
+			}
+		case opts.SkipMain && name == "main":
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s (package main)\n", dir)
+			}
+			continue
+		case !opts.AllPackages && len(pkgs) > 0:
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping %s (package %s): not the directory's primary package %s\n", dir, name, pkgs[0].Doc.Name)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no package left to document in %s", dir)
+	}
+	return pkgs, nil
+}
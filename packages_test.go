@@ -0,0 +1,77 @@
+package godocmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// packageNames is a test helper that lists the names of loaded packages,
+// suffixing external test packages with " (test)".
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - dir: The package directory to load
+//   - opts: The package selection options
+//
+// Returns:
+//   - string: The comma-separated package names
+func packageNames(t *testing.T, dir string, opts Options) string {
+	t.Helper()
+	pkgs, err := loadPackages(dir, opts)
+	if err != nil {
+		t.Fatalf("loadPackages(%+v): %v", opts, err)
+	}
+	var names []string
+	for _, pkg := range pkgs {
+		name := pkg.Doc.Name
+		if pkg.ExternalTest {
+			name += " (test)"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestLoadPackages(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":            "module example.com/tools\n\ngo 1.21\n",
+		"tool/tool.go":      "// Package tool does things.\npackage tool\n\n// Run runs.\nfunc Run() {}\n",
+		"tool/main.go":      "package main\n\nfunc main() {}\n",
+		"cmd/app/main.go":   "// Command app runs the tool.\npackage main\n\nfunc main() {}\n",
+		"lib/lib.go":        "// Package lib is a library.\npackage lib\n\n// Hello greets.\nfunc Hello() string { return \"hi\" }\n",
+		"lib/lib_test.go":   "package lib_test\n\nimport \"testing\"\n\nfunc TestHello(t *testing.T) {}\n",
+		"lib/hello_test.go": "package lib\n\nimport \"testing\"\n\nfunc TestInternal(t *testing.T) {}\n",
+	})
+
+	tool := filepath.Join(root, "tool")
+	for _, tt := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "tool"},
+		{Options{AllPackages: true}, "tool,main"},
+		{Options{AllPackages: true, SkipMain: true}, "tool"},
+	} {
+		if got := packageNames(t, tool, tt.opts); got != tt.want {
+			t.Errorf("library and main package with %+v: got %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+
+	app := filepath.Join(root, "cmd", "app")
+	if got := packageNames(t, app, Options{}); got != "main" {
+		t.Errorf("main-only directory: got %q, want %q", got, "main")
+	}
+	if _, err := loadPackages(app, Options{SkipMain: true}); err == nil || !strings.Contains(err.Error(), "no package left to document in "+app) {
+		t.Errorf("expected no package to be left with SkipMain, got %v", err)
+	}
+
+	lib := filepath.Join(root, "lib")
+	if got := packageNames(t, lib, Options{}); got != "lib" {
+		t.Errorf("external test package without ExternalTests: got %q, want %q", got, "lib")
+	}
+	if got := packageNames(t, lib, Options{ExternalTests: true}); got != "lib,lib_test (test)" {
+		t.Errorf("external test package with ExternalTests: got %q, want %q", got, "lib,lib_test (test)")
+	}
+}
@@ -0,0 +1,132 @@
+package parse
+
+import (
+	"go/ast"
+	"go/doc"
+	"regexp"
+	"strings"
+)
+
+// deprecatedPrefix starts the paragraph that marks a symbol as deprecated, as
+// described in https://go.dev/wiki/Deprecated.
+const deprecatedPrefix = "Deprecated:"
+
+// docLinkPattern matches GoDoc links such as [Name], [Type.Method], [pkg.Name]
+// and [import/path.Name] as defined by go/doc/comment.
+var docLinkPattern = regexp.MustCompile(`\[\*?([A-Za-z_][A-Za-z0-9_./-]*)\]`)
+
+// DocLink is a doc link found in a comment by DocLinks.
+type DocLink struct {
+	// Start and End are the byte offsets of the link, including its brackets.
+	Start, End int
+
+	// Target is the linked name without brackets or leading star, e.g. "User.Save".
+	Target string
+}
+
+// DocLinks finds the doc links of a comment. Bracketed text followed by "(",
+// ":" or "[" is a Markdown link or link definition rather than a doc link and
+// is skipped.
+//
+// Parameters:
+//   - text: The comment text
+//
+// Returns:
+//   - []DocLink: The doc links in order of appearance
+func DocLinks(text string) []DocLink {
+	var links []DocLink
+	for _, m := range docLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		if end := m[1]; end < len(text) && strings.IndexByte("(:[", text[end]) >= 0 {
+			continue
+		}
+		links = append(links, DocLink{Start: m[0], End: m[1], Target: text[m[2]:m[3]]})
+	}
+	return links
+}
+
+// SplitDeprecation separates the "Deprecated:" paragraph from a GoDoc comment.
+//
+// The paragraph is recognised by a line starting with "Deprecated:" at the start
+// of the comment or after a blank line, and ends at the next blank line. A
+// wrapped sentence whose continuation line happens to begin with "Deprecated:"
+// is not a notice.
+//
+// Parameters:
+//   - doc: The raw GoDoc comment
+//
+// Returns:
+//   - string: The trimmed comment with the deprecation paragraph removed
+//   - string: The deprecation notice without its "Deprecated:" prefix
+//   - bool: True if the comment contains a deprecation notice
+func SplitDeprecation(doc string) (string, string, bool) {
+	lines := strings.Split(doc, "\n")
+	start := -1
+	paragraph := true
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if paragraph && strings.HasPrefix(trimmed, deprecatedPrefix) {
+			start = i
+			break
+		}
+		paragraph = trimmed == ""
+	}
+	if start < 0 {
+		return strings.TrimSpace(doc), "", false
+	}
+
+	end := start + 1
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+
+	notice := strings.TrimPrefix(strings.TrimSpace(lines[start]), deprecatedPrefix)
+	for _, line := range lines[start+1 : end] {
+		notice += " " + strings.TrimSpace(line)
+	}
+
+	// Drop the blank line that separated the notice from the preceding paragraph.
+	before := start
+	if before > 0 {
+		before--
+	}
+	rest := append(append([]string{}, lines[:before]...), lines[end:]...)
+	return strings.TrimSpace(strings.Join(rest, "\n")), strings.TrimSpace(notice), true
+}
+
+// JoinComments joins the text of several comment groups, such as the leading
+// doc comment and trailing line comment of a struct field or value spec.
+//
+// Parameters:
+//   - groups: The comment groups, any of which may be nil
+//
+// Returns:
+//   - string: The combined comment text
+func JoinComments(groups ...*ast.CommentGroup) string {
+	var parts []string
+	for _, g := range groups {
+		if text := strings.TrimSpace(g.Text()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ValueDocumented reports whether a const or var group has a doc comment on the
+// group or on any of its specs.
+//
+// Parameters:
+//   - v: The documented value group
+//
+// Returns:
+//   - bool: True if any part of the group is documented
+func ValueDocumented(v *doc.Value) bool {
+	if strings.TrimSpace(v.Doc) != "" {
+		return true
+	}
+	for _, spec := range v.Decl.Specs {
+		if vs, ok := spec.(*ast.ValueSpec); ok && JoinComments(vs.Doc, vs.Comment) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -1,38 +1,279 @@
 package parse
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/doc"
 	"go/parser"
 	"go/token"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/thinktide/godocmd/command"
+	"github.com/thinktide/godocmd/errdoc"
+	"github.com/thinktide/godocmd/modules"
 )
 
-// LoadPackage loads the Go package from the specified directory and returns its documentation.
+// ErrNoPackage is returned when a directory contains no Go package for the
+// selected platforms.
+var ErrNoPackage = errors.New("no Go package found")
+
+// Package bundles the documentation of a loaded Go package with the file set
+// its positions refer to, which renderers need to print example code.
+type Package struct {
+	Dir        string
+	ImportPath string          // empty outside a module and for all but a directory's primary package
+	Module     *modules.Module // the enclosing module, nil outside a module
+	Doc        *doc.Package
+	Fset       *token.FileSet
+
+	// Files holds the package's non-test source files, merged across the
+	// documented platforms. Function bodies are dropped by go/doc.
+	Files []*ast.File
+
+	// Command describes the command line interface of a main package,
+	// extracted before go/doc drops the function bodies it is read from. It is
+	// nil for other packages and when no supported framework is used.
+	Command *command.Command
+
+	// Errors catalogs the package's sentinel errors and error types, with the
+	// functions wrapping them, also extracted before function bodies are dropped.
+	Errors []errdoc.Error
+
+	// ExternalTest is set for the external test package (package foo_test) of
+	// a directory, whose examples are also attached to the primary package.
+	ExternalTest bool
+
+	// Platforms lists the documented "goos/goarch" platforms, the one selected
+	// by BuildOptions.GOOS and GOARCH first.
+	Platforms []string
+
+	// onlyOn holds the platforms declaring each symbol that some documented
+	// platform lacks.
+	onlyOn map[string][]string
+}
+
+// Load parses the primary Go package in the specified directory for the
+// current platform, as LoadWithOptions does with zero BuildOptions.
 //
 // Parameters:
 //   - dir: The path to the package directory to load
 //
 // Returns:
-//   - *doc.Package: The parsed documentation package
+//   - *Package: The parsed package together with its file set
 //   - error: Any error encountered while parsing the directory
-func LoadPackage(dir string) (*doc.Package, error) {
-	fileSet := token.NewFileSet()
+func Load(dir string) (*Package, error) {
+	return LoadWithOptions(dir, BuildOptions{})
+}
+
+// LoadWithOptions parses the primary Go package in the specified directory
+// (see LoadAll).
+//
+// Parameters:
+//   - dir: The path to the package directory to load
+//   - opts: The platforms and build tags selecting the files
+//
+// Returns:
+//   - *Package: The parsed package together with its file set
+//   - error: ErrNoPackage if the directory has no package, or any error encountered while parsing it
+func LoadWithOptions(dir string, opts BuildOptions) (*Package, error) {
+	pkgs, err := LoadAll(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pkgs[0], nil
+}
 
-	files, err := parser.ParseDir(fileSet, dir, func(fi os.FileInfo) bool {
-		// Skip test files
-		return strings.HasSuffix(fi.Name(), ".go") && !strings.HasSuffix(fi.Name(), "_test.go")
+// LoadAll parses every Go package in the specified directory. Only files whose
+// build constraints match one of the platforms of opts are parsed; symbols of
+// further platforms are merged into those of the first.
+//
+// The packages are ordered by preference. The primary package comes first: the
+// one named after the directory, otherwise the one with the most files, a
+// package other than main on a tie, and the first by name after that. It gets
+// the examples of the directory's _test.go files, and its import path and
+// module are resolved from the enclosing go.mod or go.work file. The other
+// packages, such as a stray generated file or a main package next to a
+// library, follow in the same order, and the external test package, if any,
+// comes last.
+//
+// Parameters:
+//   - dir: The path to the package directory to load
+//   - opts: The platforms and build tags selecting the files
+//
+// Returns:
+//   - []*Package: The packages, the primary package first
+//   - error: ErrNoPackage if the directory has no package, or any error encountered while parsing it
+func LoadAll(dir string, opts BuildOptions) ([]*Package, error) {
+	platforms, err := opts.platforms()
+	if err != nil {
+		return nil, err
+	}
+
+	fileSet := token.NewFileSet()
+	onPlatforms := map[string][]int{}
+	pkgs, err := parser.ParseDir(fileSet, dir, func(fi os.FileInfo) bool {
+		if !strings.HasSuffix(fi.Name(), ".go") {
+			return false
+		}
+		onPlatforms[fi.Name()] = matching(platforms, dir, fi.Name())
+		return len(onPlatforms[fi.Name()]) > 0
 	}, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, pkg := range files {
-		return doc.New(pkg, dir, doc.AllDecls), nil
+	fileName := func(f *ast.File) string {
+		return filepath.Base(fileSet.Position(f.Package).Filename)
+	}
+	// Split each package into its source files and in-package test files.
+	sources := map[string][]*ast.File{}
+	tests := map[string][]*ast.File{}
+	var names []string
+	for name, pkg := range pkgs {
+		for _, f := range sortedFiles(pkg) {
+			if strings.HasSuffix(fileName(f), "_test.go") {
+				if containsInt(onPlatforms[fileName(f)], 0) {
+					tests[name] = append(tests[name], f)
+				}
+			} else {
+				sources[name] = append(sources[name], f)
+			}
+		}
+		if len(sources[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNoPackage)
+	}
+	sortPackageNames(names, sources, dir)
+
+	var importPath string
+	mod, err := modules.Find(dir)
+	switch {
+	case err == nil:
+		if importPath, err = mod.ImportPath(dir); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, modules.ErrNoModule):
+		// Not part of a module: the import path stays empty.
+	default:
+		return nil, err
+	}
+
+	labels := make([]string, len(platforms))
+	for i, p := range platforms {
+		labels[i] = p.label
+	}
+
+	primary := names[0]
+	externalTests := tests[primary+"_test"]
+	var out []*Package
+	for _, name := range names {
+		files, onlyOn := mergePlatforms(fileSet, sources[name], platforms, onPlatforms)
+		pkg := &Package{Dir: dir, Module: mod, Fset: fileSet, Files: files, Platforms: labels, onlyOn: onlyOn}
+		pkg.Errors = errdoc.Catalog(files)
+		if name == "main" {
+			pkg.Command = command.Detect(programName(dir), files)
+		}
+		if name == primary {
+			// Test files only contribute examples.
+			files = append(append(files, tests[name]...), externalTests...)
+			pkg.ImportPath = importPath
+		}
+		if pkg.Doc, err = doc.NewFromFiles(fileSet, files, pkg.ImportPath, doc.AllDecls); err != nil {
+			return nil, err
+		}
+		out = append(out, pkg)
 	}
 
-	return nil, nil
+	if len(externalTests) > 0 {
+		// NewFromFiles would treat every file as a test file, so the external
+		// test package is documented from its AST, which must stay intact for
+		// the examples read from it above.
+		files := map[string]*ast.File{}
+		for _, f := range externalTests {
+			files[fileSet.Position(f.Package).Filename] = f
+		}
+		astPkg := &ast.Package{Name: primary + "_test", Files: files}
+		out = append(out, &Package{
+			Dir:          dir,
+			Module:       mod,
+			Doc:          doc.New(astPkg, "", doc.AllDecls|doc.PreserveAST),
+			Fset:         fileSet,
+			ExternalTest: true,
+			Platforms:    labels,
+		})
+	}
+	return out, nil
+}
+
+// sortPackageNames orders the names of the packages in a directory by
+// preference, as described by LoadAll.
+//
+// Parameters:
+//   - names: The package names, sorted in place
+//   - sources: The source files of each package
+//   - dir: The package directory
+func sortPackageNames(names []string, sources map[string][]*ast.File, dir string) {
+	dirName := dirPackageName(dir)
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if (a == dirName) != (b == dirName) {
+			return a == dirName
+		}
+		if len(sources[a]) != len(sources[b]) {
+			return len(sources[a]) > len(sources[b])
+		}
+		if (a == "main") != (b == "main") {
+			return b == "main"
+		}
+		return a < b
+	})
+}
+
+// dirPackageName returns the package name conventionally used for a directory:
+// its base name without a "go-" prefix or "-go" suffix and with dashes and dots
+// replaced by underscores, or the parent's for major version directories such
+// as "v2".
+//
+// Parameters:
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The conventional package name
+func dirPackageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	base := filepath.Base(abs)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = filepath.Base(filepath.Dir(abs))
+	}
+	base = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(base), "go-"), "-go")
+	return strings.NewReplacer("-", "_", ".", "_").Replace(base)
+}
+
+// LoadPackage loads the primary Go package from the specified directory and
+// returns its documentation.
+//
+// Parameters:
+//   - dir: The path to the package directory to load
+//
+// Returns:
+//   - *doc.Package: The parsed documentation package, never nil when err is nil
+//   - error: ErrNoPackage if the directory has no package, or any error encountered while parsing it
+func LoadPackage(dir string) (*doc.Package, error) {
+	pkg, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Doc, nil
 }
 
 // ParseDocPackageFromSource parses in-memory Go files and returns a *doc.Package.
@@ -62,3 +303,40 @@ func ParseDocPackageFromSource(name string, files map[string]string) (*doc.Packa
 	}
 	return pkg, nil
 }
+
+// sortedFiles returns the files of an AST package ordered by filename so that
+// documentation built from them does not depend on map iteration order.
+//
+// Parameters:
+//   - pkg: The AST package whose files to collect
+//
+// Returns:
+//   - []*ast.File: The package files sorted by filename
+func sortedFiles(pkg *ast.Package) []*ast.File {
+	filenames := make([]string, 0, len(pkg.Files))
+	for filename := range pkg.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	files := make([]*ast.File, 0, len(filenames))
+	for _, filename := range filenames {
+		files = append(files, pkg.Files[filename])
+	}
+	return files
+}
+
+// programName returns the name a main package in dir is built as: the base
+// name of the directory.
+//
+// Parameters:
+//   - dir: The package directory
+//
+// Returns:
+//   - string: The program name
+func programName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
@@ -1,10 +1,13 @@
 package parse
 
 import (
+	"errors"
 	"go/ast"
 	"go/doc"
 	"go/parser"
 	"go/token"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -68,3 +71,124 @@ func TestParseGoDocPackage_HandlesNoFuncs(t *testing.T) {
 		t.Errorf("expected type Foo to be parsed, got %+v", pkg.Types)
 	}
 }
+
+func TestSplitDeprecation(t *testing.T) {
+	body, notice, ok := SplitDeprecation("WriteMarkdown is an alias.\n\nDeprecated: use\nWriteMarkdownWithOptions.\n\nMore text.\n")
+	if !ok {
+		t.Fatal("expected deprecation to be detected")
+	}
+	if notice != "use WriteMarkdownWithOptions." {
+		t.Errorf("unexpected notice %q", notice)
+	}
+	if body != "WriteMarkdown is an alias.\n\nMore text." {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if _, notice, ok := SplitDeprecation("Deprecated: use New.\n"); !ok || notice != "use New." {
+		t.Errorf("expected leading notice to be detected, got %q, %v", notice, ok)
+	}
+
+	// A wrapped sentence continuing with "Deprecated:" does not start a paragraph.
+	const wrapped = "Load reads the file; the old API is now\nDeprecated: see Open for details."
+	body, _, ok = SplitDeprecation(wrapped)
+	if ok {
+		t.Error("expected mid-paragraph Deprecated: to be ignored")
+	}
+	if body != wrapped {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestDocLinks(t *testing.T) {
+	links := DocLinks("See [User.Save], [*Store] and [encoding/json.Marshal], not [a link](url), [ref]: def or [text][ref].")
+	var targets []string
+	for _, link := range links {
+		targets = append(targets, link.Target)
+	}
+	if got := strings.Join(targets, " "); got != "User.Save Store encoding/json.Marshal ref" {
+		t.Errorf("unexpected doc link targets %q", got)
+	}
+	if first := links[0]; first.Start != 4 || first.End != 15 {
+		t.Errorf("unexpected offsets %d-%d", first.Start, first.End)
+	}
+}
+
+func TestValueDocumented(t *testing.T) {
+	pkg := parseGoDocPackage("values", `package values
+
+// Documented is a documented group.
+const Documented = 1
+
+const (
+	// Spec has a spec comment.
+	Spec = 2
+)
+
+var Trailing = 3 // Trailing has a line comment.
+
+var Bare = 4
+`)
+	want := map[string]bool{"Documented": true, "Spec": true, "Trailing": true, "Bare": false}
+	for _, v := range append(pkg.Consts, pkg.Vars...) {
+		name := v.Names[0]
+		if got := ValueDocumented(v); got != want[name] {
+			t.Errorf("ValueDocumented(%s) = %v, want %v", name, got, want[name])
+		}
+	}
+	for _, v := range pkg.Consts {
+		spec := v.Decl.Specs[0].(*ast.ValueSpec)
+		if got := JoinComments(spec.Doc, spec.Comment); v.Names[0] == "Spec" && got != "Spec has a spec comment." {
+			t.Errorf("JoinComments() = %q", got)
+		}
+	}
+}
+
+func TestLoadAll_MultiplePackages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "go-store")
+	files := map[string]string{
+		"tool.go":          "package main\n\nfunc main() {}\n",
+		"tool_helpers.go":  "package main\n\nfunc help() {}\n",
+		"store.go":         "// Package store stores.\npackage store\n\n// Put puts.\nfunc Put() {}\n",
+		"store_test.go":    "package store\n\nfunc ExamplePut() {}\n",
+		"external_test.go": "package store_test\n\n// Helper is shared by tests.\nfunc Helper() {}\n\nfunc Example() {}\n",
+		"zz.go":            "package zz\n",
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		pkgs, err := LoadAll(dir, BuildOptions{})
+		if err != nil {
+			t.Fatalf("LoadAll: %v", err)
+		}
+		var names []string
+		for _, p := range pkgs {
+			names = append(names, p.Doc.Name)
+		}
+		if got := strings.Join(names, " "); got != "store main zz store_test" {
+			t.Fatalf("packages = %q", got)
+		}
+		primary := pkgs[0].Doc
+		if len(primary.Examples) != 1 || len(primary.Funcs) != 1 || len(primary.Funcs[0].Examples) != 1 {
+			t.Errorf("examples not attached to the primary package: %d package, %d Put", len(primary.Examples), len(primary.Funcs))
+		}
+		ext := pkgs[3]
+		if !ext.ExternalTest || len(ext.Doc.Funcs) != 2 || pkgs[1].ImportPath != "" {
+			t.Errorf("unexpected external test package %+v", ext)
+		}
+	}
+
+	empty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(empty, "x_test.go"), []byte("package x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPackage(empty); !errors.Is(err, ErrNoPackage) {
+		t.Errorf("expected ErrNoPackage, got %v", err)
+	}
+}
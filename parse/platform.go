@@ -0,0 +1,335 @@
+package parse
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// BuildOptions selects the files of a package by their build constraints:
+// file name suffixes such as _linux.go and //go:build lines.
+type BuildOptions struct {
+	// GOOS and GOARCH select the documented platform. They default to those of
+	// go/build.Default, which honor the GOOS and GOARCH environment variables.
+	GOOS   string
+	GOARCH string
+
+	// Tags lists additional build tags to satisfy, e.g. "integration".
+	Tags []string
+
+	// Platforms lists further "goos/goarch" or "goos" platforms whose symbols
+	// are documented as well. Symbols missing on some of the platforms are
+	// reported by Package.OnlyOn.
+	Platforms []string
+}
+
+// platform is a documented platform and the build context selecting its files.
+type platform struct {
+	label string
+	ctx   build.Context
+}
+
+// platforms returns the documented platform followed by BuildOptions.Platforms,
+// without duplicates.
+//
+// Returns:
+//   - []platform: The platforms, the documented one first
+//   - error: An error if a platform is not of the form "goos/goarch" or "goos"
+func (o BuildOptions) platforms() ([]platform, error) {
+	base := build.Default
+	if o.GOOS != "" {
+		base.GOOS = o.GOOS
+	}
+	if o.GOARCH != "" {
+		base.GOARCH = o.GOARCH
+	}
+	base.BuildTags = append([]string(nil), o.Tags...)
+	// Documentation should not depend on whether a C compiler is installed.
+	base.CgoEnabled = true
+
+	out := []platform{{label: base.GOOS + "/" + base.GOARCH, ctx: base}}
+	seen := map[string]bool{out[0].label: true}
+	for _, p := range o.Platforms {
+		goos, goarch, _ := strings.Cut(p, "/")
+		if goarch == "" {
+			goarch = base.GOARCH
+		}
+		if goos == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid platform %q: want goos/goarch", p)
+		}
+		ctx := base
+		ctx.GOOS, ctx.GOARCH = goos, goarch
+		label := goos + "/" + goarch
+		if !seen[label] {
+			seen[label] = true
+			out = append(out, platform{label: label, ctx: ctx})
+		}
+	}
+	return out, nil
+}
+
+// matching returns the indexes of the platforms a file is built on.
+//
+// Parameters:
+//   - platforms: The documented platforms
+//   - dir: The package directory
+//   - name: The file name
+//
+// Returns:
+//   - []int: The indexes into platforms, empty if no platform builds the file
+func matching(platforms []platform, dir, name string) []int {
+	var out []int
+	for i, p := range platforms {
+		if ok, err := p.ctx.MatchFile(dir, name); err == nil && ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// mergePlatforms combines the files of a package across platforms: all files
+// of the first platform, followed by the files of each further platform with
+// the declarations already made by earlier files removed, so that symbols
+// declared once per platform are documented once.
+//
+// Parameters:
+//   - fset: The file set the files were parsed into
+//   - files: The non-test files of the package, sorted by name
+//   - platforms: The documented platforms
+//   - onPlatforms: The platform indexes each file name is built on
+//
+// Returns:
+//   - []*ast.File: The files to document
+//   - map[string][]string: The platforms declaring each symbol that some platform lacks
+func mergePlatforms(fset *token.FileSet, files []*ast.File, platforms []platform, onPlatforms map[string][]int) ([]*ast.File, map[string][]string) {
+	fileName := func(f *ast.File) string {
+		return filepath.Base(fset.Position(f.Package).Filename)
+	}
+
+	declared := make([]map[string]bool, len(platforms))
+	for i := range declared {
+		declared[i] = map[string]bool{}
+	}
+	var symbols []string
+	known := map[string]bool{}
+	for _, f := range files {
+		for _, key := range declKeys(f.Decls) {
+			if !known[key] {
+				known[key] = true
+				symbols = append(symbols, key)
+			}
+			for _, i := range onPlatforms[fileName(f)] {
+				declared[i][key] = true
+			}
+		}
+	}
+
+	only := map[string][]string{}
+	for _, key := range symbols {
+		var labels []string
+		for i, p := range platforms {
+			if declared[i][key] {
+				labels = append(labels, p.label)
+			}
+		}
+		if len(labels) < len(platforms) {
+			only[key] = labels
+		}
+	}
+
+	var merged []*ast.File
+	included := map[*ast.File]bool{}
+	seen := map[string]bool{}
+	for i := range platforms {
+		for _, f := range files {
+			if included[f] || !containsInt(onPlatforms[fileName(f)], i) {
+				continue
+			}
+			if i > 0 {
+				f.Decls = pruneDecls(f.Decls, seen)
+			}
+			for _, key := range declKeys(f.Decls) {
+				seen[key] = true
+			}
+			included[f] = true
+			merged = append(merged, f)
+		}
+	}
+	return merged, only
+}
+
+// declKeys returns the symbols declared by top-level declarations, named as
+// in Package.OnlyOn.
+//
+// Parameters:
+//   - decls: The declarations
+//
+// Returns:
+//   - []string: The symbol names, "Recv.Method" for methods
+func declKeys(decls []ast.Decl) []string {
+	var keys []string
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			keys = append(keys, funcKey(d))
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				keys = append(keys, specKeys(spec)...)
+			}
+		}
+	}
+	return keys
+}
+
+// pruneDecls removes the functions, methods, types and value specs that
+// declare an already seen symbol.
+//
+// Parameters:
+//   - decls: The declarations of a file
+//   - seen: The symbols declared so far
+//
+// Returns:
+//   - []ast.Decl: The remaining declarations
+func pruneDecls(decls []ast.Decl, seen map[string]bool) []ast.Decl {
+	var out []ast.Decl
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if seen[funcKey(d)] {
+				continue
+			}
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				break
+			}
+			var specs []ast.Spec
+		specLoop:
+			for _, spec := range d.Specs {
+				for _, key := range specKeys(spec) {
+					if seen[key] {
+						continue specLoop
+					}
+				}
+				specs = append(specs, spec)
+			}
+			if len(specs) == 0 {
+				continue
+			}
+			d.Specs = specs
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// funcKey returns the symbol name of a function or method declaration.
+//
+// Parameters:
+//   - decl: The declaration
+//
+// Returns:
+//   - string: The name, "Recv.Method" for methods
+func funcKey(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	typ := decl.Recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+			continue
+		case *ast.IndexExpr:
+			typ = t.X
+			continue
+		case *ast.IndexListExpr:
+			typ = t.X
+			continue
+		case *ast.ParenExpr:
+			typ = t.X
+			continue
+		case *ast.Ident:
+			return t.Name + "." + decl.Name.Name
+		}
+		return decl.Name.Name
+	}
+}
+
+// specKeys returns the names a type or value spec declares.
+//
+// Parameters:
+//   - spec: The spec
+//
+// Returns:
+//   - []string: The declared names, none for import specs
+func specKeys(spec ast.Spec) []string {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return []string{s.Name.Name}
+	case *ast.ValueSpec:
+		var names []string
+		for _, n := range s.Names {
+			if n.Name != "_" {
+				names = append(names, n.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// containsInt reports whether a list contains a value.
+//
+// Parameters:
+//   - list: The list
+//   - v: The value
+//
+// Returns:
+//   - bool: True if v is in list
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// OnlyOn returns the platforms any of the given symbols is declared on when
+// some documented platform declares none of them, in the order of
+// Package.Platforms.
+//
+// Parameters:
+//   - symbols: The symbol names, "Recv.Method" for methods
+//
+// Returns:
+//   - []string: The platforms, or nil if every documented platform declares one of the symbols
+func (p *Package) OnlyOn(symbols ...string) []string {
+	if len(p.onlyOn) == 0 {
+		return nil
+	}
+	on := map[string]bool{}
+	for _, s := range symbols {
+		labels, ok := p.onlyOn[s]
+		if !ok {
+			// Declared on every platform.
+			return nil
+		}
+		for _, l := range labels {
+			on[l] = true
+		}
+	}
+	if len(on) == 0 {
+		return nil
+	}
+	var out []string
+	for _, l := range p.Platforms {
+		if on[l] {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadWithOptions_Platforms(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common.go":   "package sys\n\n// Open opens.\nfunc Open() {}\n\n// Handle is a handle.\ntype Handle struct{}\n",
+		"sys_unix.go": "//go:build unix\n\npackage sys\n\n// Fd returns the descriptor.\nfunc (h *Handle) Fd() int { return 0 }\n\n// Signal is a signal.\nconst Signal = 1\n",
+		"sys_windows.go": "package sys\n\n// Fd returns the handle.\nfunc (h *Handle) Fd() int { return 0 }\n\n" +
+			"// Registry reads the registry.\nfunc Registry() {}\n",
+		"gen.go":    "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+		"tagged.go": "//go:build integration\n\npackage sys\n\n// Fixture is for integration tests.\nfunc Fixture() {}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkg, err := LoadWithOptions(dir, BuildOptions{GOOS: "linux", GOARCH: "amd64"})
+	if err != nil {
+		t.Fatalf("LoadWithOptions: %v", err)
+	}
+	if got := funcNames(pkg); got != "Open" {
+		t.Errorf("linux funcs = %q, want Open", got)
+	}
+	if pkg.OnlyOn("Signal") != nil {
+		t.Errorf("single platform reported platform-specific symbols")
+	}
+
+	pkg, err = LoadWithOptions(dir, BuildOptions{
+		GOOS: "linux", GOARCH: "amd64", Tags: []string{"integration"},
+		Platforms: []string{"windows/amd64", "darwin", "linux/amd64"},
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOptions: %v", err)
+	}
+	if got := strings.Join(pkg.Platforms, " "); got != "linux/amd64 windows/amd64 darwin/amd64" {
+		t.Errorf("platforms = %q", got)
+	}
+	if got := funcNames(pkg); got != "Fixture Open Registry" {
+		t.Errorf("merged funcs = %q", got)
+	}
+	if len(pkg.Doc.Types) != 1 || len(pkg.Doc.Types[0].Methods) != 1 {
+		t.Fatalf("Handle.Fd should be documented once: %+v", pkg.Doc.Types)
+	}
+	if doc := pkg.Doc.Types[0].Methods[0].Doc; doc != "Fd returns the descriptor.\n" {
+		t.Errorf("Fd doc = %q, want the first platform's", doc)
+	}
+	for symbol, want := range map[string]string{
+		"Open":      "",
+		"Handle.Fd": "",
+		"Registry":  "windows/amd64",
+		"Signal":    "linux/amd64 darwin/amd64",
+	} {
+		if got := strings.Join(pkg.OnlyOn(symbol), " "); got != want {
+			t.Errorf("OnlyOn(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+
+	if _, err := LoadWithOptions(dir, BuildOptions{Platforms: []string{"linux/amd64/v3"}}); err == nil {
+		t.Error("expected an error for an invalid platform")
+	}
+}
+
+// funcNames returns the names of a package's functions, space-separated.
+func funcNames(pkg *Package) string {
+	var names []string
+	for _, f := range pkg.Doc.Funcs {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, " ")
+}
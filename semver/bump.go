@@ -0,0 +1,193 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/thinktide/godocmd/apidiff"
+)
+
+// Version bumps, from least to most significant.
+const (
+	Patch = "patch"
+	Minor = "minor"
+	Major = "major"
+)
+
+// ErrUnderversioned is returned by Recommendation.Validate when a proposed
+// version does not cover the API changes since the current version.
+var ErrUnderversioned = errors.New("version bump too small for the API changes")
+
+// Recommendation is the minimum version bump required by the API changes made
+// since the current version.
+type Recommendation struct {
+	Current    Version  // the latest released version
+	Next       Version  // the smallest version allowed for the changes
+	Bump       string   // Patch, Minor or Major
+	ModulePath string   // the module path at the new version
+	Reasons    []string // why the bump is required, one sentence each
+}
+
+// modulePathMajor matches the major version suffix of a module path, e.g. "/v2".
+var modulePathMajor = regexp.MustCompile(`/v([0-9]+)$`)
+
+// PathMajor returns the major version declared by a module path's "/vN"
+// suffix. Paths without a suffix declare v0 or v1, reported as 1.
+//
+// Parameters:
+//   - modulePath: The module path, e.g. "example.com/m/v2"
+//
+// Returns:
+//   - int: The major version declared by the path
+func PathMajor(modulePath string) int {
+	m := modulePathMajor.FindStringSubmatch(modulePath)
+	if m == nil {
+		return 1
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 2 {
+		return 1
+	}
+	return n
+}
+
+// Recommend computes the minimum version following current that the API
+// changes in report require, following the Go module rules:
+//
+//   - Breaking changes require a major bump from v1 on, and a minor bump in v0,
+//     which carries no compatibility promise.
+//   - Additions and deprecations require a minor bump.
+//   - Anything else requires a patch bump.
+//   - From v2 on, the major version must match the module path's "/vN" suffix,
+//     so a module path that already declares a higher major version requires
+//     that version.
+//
+// Parameters:
+//   - current: The latest released version
+//   - report: The API changes since current
+//   - modulePath: The module path at the new version
+//
+// Returns:
+//   - Recommendation: The required bump and its justification
+func Recommend(current Version, report apidiff.Report, modulePath string) Recommendation {
+	r := Recommendation{Current: current, Bump: Patch, ModulePath: modulePath}
+	pathMajor := PathMajor(modulePath)
+
+	var breaking, compatible []apidiff.Change
+	for _, c := range report.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		} else if c.Kind == apidiff.Added || c.Kind == apidiff.Deprecated {
+			compatible = append(compatible, c)
+		}
+	}
+
+	switch {
+	case pathMajor >= 2 && pathMajor > current.Major:
+		r.Bump = Major
+		r.Next = Version{Major: pathMajor}
+		r.Reasons = append(r.Reasons, fmt.Sprintf("The module path %s declares major version %d.", modulePath, pathMajor))
+	case len(breaking) > 0 && current.Major == 0:
+		r.Bump = Minor
+		r.Reasons = append(r.Reasons, fmt.Sprintf("%d breaking change(s); v0 makes no compatibility promise, so a minor bump suffices.", len(breaking)))
+	case len(breaking) > 0:
+		r.Bump = Major
+		r.Reasons = append(r.Reasons, fmt.Sprintf("%d breaking change(s).", len(breaking)))
+		if current.Major+1 > pathMajor {
+			r.Reasons = append(r.Reasons, fmt.Sprintf("The module path must change to %s/v%d.", modulePathMajor.ReplaceAllString(modulePath, ""), current.Major+1))
+		}
+	case len(compatible) > 0:
+		r.Bump = Minor
+		r.Reasons = append(r.Reasons, fmt.Sprintf("%d compatible addition(s) or deprecation(s).", len(compatible)))
+	default:
+		r.Reasons = append(r.Reasons, "No changes to the exported API.")
+	}
+	if current.Major >= 2 && pathMajor < current.Major {
+		r.Reasons = append(r.Reasons, fmt.Sprintf("Warning: %s does not match the module path %s, which declares major version %d.", current, modulePath, pathMajor))
+	}
+
+	if r.Next == (Version{}) {
+		r.Next = current.next(r.Bump)
+	}
+	for _, c := range breaking {
+		r.Reasons = append(r.Reasons, "Breaking: "+describe(c))
+	}
+	for _, c := range compatible {
+		r.Reasons = append(r.Reasons, "Compatible: "+describe(c))
+	}
+	return r
+}
+
+// next returns the release following v after a bump. Pre-release and build
+// metadata are dropped, so the release of a pre-release needs no bump of its own.
+//
+// Parameters:
+//   - bump: Patch, Minor or Major
+//
+// Returns:
+//   - Version: The next release
+func (v Version) next(bump string) Version {
+	switch bump {
+	case Major:
+		return Version{Major: v.Major + 1}
+	case Minor:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// describe formats a change for a justification line.
+//
+// Parameters:
+//   - c: The change
+//
+// Returns:
+//   - string: The qualified symbol and the change message
+func describe(c apidiff.Change) string {
+	name := c.Package
+	if c.Symbol != "" {
+		name += "." + c.Symbol
+	}
+	return name + ": " + c.Message
+}
+
+// Validate checks that a proposed version is at least the recommended one and,
+// from v2 on, matches the major version of the module path. A pre-release of
+// the recommended version, such as "v2.0.0-rc.1", is accepted.
+//
+// Parameters:
+//   - tag: The proposed version, e.g. "v1.3.0"
+//
+// Returns:
+//   - error: ErrUnderversioned if tag is lower than Next, or an error if it is not a
+//     valid version or does not match the module path
+func (r Recommendation) Validate(tag string) error {
+	v, ok := Parse(tag)
+	if !ok {
+		return fmt.Errorf("%q is not a semantic version", tag)
+	}
+	core := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	if Compare(core, r.Next) < 0 {
+		return fmt.Errorf("%w: %s requires at least %s (%s bump), got %s", ErrUnderversioned, r.Current, r.Next, r.Bump, tag)
+	}
+	if v.Major >= 2 && v.Major != PathMajor(r.ModulePath) {
+		return fmt.Errorf("%s does not match the module path %s; a v%d module path must end in /v%d", tag, r.ModulePath, v.Major, v.Major)
+	}
+	return nil
+}
+
+// WriteText writes a recommendation as plain text: the next version followed
+// by its justification.
+//
+// Parameters:
+//   - r: The recommendation to write
+//   - out: The writer to output the text to
+func WriteText(r Recommendation, out io.Writer) {
+	fmt.Fprintf(out, "%s (%s bump from %s)\n", r.Next, r.Bump, r.Current)
+	for _, reason := range r.Reasons {
+		fmt.Fprintf(out, "  - %s\n", reason)
+	}
+}
@@ -0,0 +1,94 @@
+package semver
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thinktide/godocmd/apidiff"
+)
+
+func TestRecommend(t *testing.T) {
+	breaking := apidiff.Report{Changes: []apidiff.Change{
+		{Package: "example.com/m/store", Symbol: "Close", Kind: apidiff.Removed, Breaking: true, Message: "func removed"},
+		{Package: "example.com/m/store", Symbol: "Dial", Kind: apidiff.Added, Message: "func added"},
+	}}
+	additive := apidiff.Report{Changes: []apidiff.Change{
+		{Package: "example.com/m/store", Symbol: "Open", Kind: apidiff.Deprecated, Message: "func deprecated"},
+	}}
+	tagOnly := apidiff.Report{Changes: []apidiff.Change{
+		{Package: "example.com/m/store", Symbol: "Item.Name", Kind: apidiff.Changed, Message: "struct tag changed"},
+	}}
+
+	tests := []struct {
+		name       string
+		current    string
+		report     apidiff.Report
+		modulePath string
+		next       string
+		bump       string
+		reason     string
+	}{
+		{"v0 breaking", "v0.4.2", breaking, "example.com/m", "v0.5.0", Minor, "v0 makes no compatibility promise"},
+		{"v1 breaking", "v1.4.2", breaking, "example.com/m", "v2.0.0", Major, "The module path must change to example.com/m/v2."},
+		{"v2 breaking", "v2.1.0", breaking, "example.com/m/v2", "v3.0.0", Major, "The module path must change to example.com/m/v3."},
+		{"additions", "v1.4.2", additive, "example.com/m", "v1.5.0", Minor, "Compatible: example.com/m/store.Open: func deprecated"},
+		{"no api change", "v1.4.2", tagOnly, "example.com/m", "v1.4.3", Patch, "No changes to the exported API."},
+		{"path declares major", "v1.4.2", additive, "example.com/m/v2", "v2.0.0", Major, "declares major version 2"},
+		{"pre-release", "v1.0.0-rc.1", apidiff.Report{}, "example.com/m", "v1.0.1", Patch, "No changes"},
+		{"mismatched path", "v2.1.0", apidiff.Report{}, "example.com/m", "v2.1.1", Patch, "Warning: v2.1.0 does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, ok := Parse(tt.current)
+			if !ok {
+				t.Fatalf("invalid version %q", tt.current)
+			}
+			r := Recommend(current, tt.report, tt.modulePath)
+			if r.Next.String() != tt.next || r.Bump != tt.bump {
+				t.Errorf("got %s (%s), want %s (%s)", r.Next, r.Bump, tt.next, tt.bump)
+			}
+			if joined := strings.Join(r.Reasons, "\n"); !strings.Contains(joined, tt.reason) {
+				t.Errorf("expected reasons to contain %q:\n%s", tt.reason, joined)
+			}
+		})
+	}
+}
+
+func TestRecommendation_Validate(t *testing.T) {
+	current, _ := Parse("v1.4.2")
+	breaking := apidiff.Report{Changes: []apidiff.Change{{Kind: apidiff.Removed, Breaking: true}}}
+
+	r := Recommend(current, breaking, "example.com/m/v2")
+	for _, tag := range []string{"v2.0.0", "v2.0.0-rc.1", "v2.1.0"} {
+		if err := r.Validate(tag); err != nil {
+			t.Errorf("Validate(%q): %v", tag, err)
+		}
+	}
+	if err := r.Validate("v1.5.0"); !errors.Is(err, ErrUnderversioned) {
+		t.Errorf("expected ErrUnderversioned, got %v", err)
+	}
+	if err := r.Validate("v3.0.0"); err == nil || !strings.Contains(err.Error(), "must end in /v3") {
+		t.Errorf("expected a module path mismatch, got %v", err)
+	}
+	if err := r.Validate("2.0.0"); err == nil {
+		t.Error("expected an invalid version error")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	current, _ := Parse("v0.2.0")
+	r := Recommend(current, apidiff.Report{Changes: []apidiff.Change{
+		{Package: "example.com/m/store", Symbol: "Close", Kind: apidiff.Added, Message: "func added"},
+	}}, "example.com/m")
+
+	var buf bytes.Buffer
+	WriteText(r, &buf)
+	want := "v0.3.0 (minor bump from v0.2.0)\n" +
+		"  - 1 compatible addition(s) or deprecation(s).\n" +
+		"  - Compatible: example.com/m/store.Close: func added\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
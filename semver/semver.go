@@ -0,0 +1,143 @@
+// Package semver parses and orders semantic version tags such as "v1.2.3" and
+// "v2.0.0-rc.1".
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string // e.g. "rc.1", without the leading "-"
+	Build               string // build metadata, without the leading "+"; ignored when ordering
+}
+
+// pattern matches a "v"-prefixed semantic version.
+var pattern = regexp.MustCompile(`^v(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// Parse parses a "v"-prefixed semantic version.
+//
+// Parameters:
+//   - s: The version, e.g. "v1.2.3" or "v1.0.0-beta.2+exp"
+//
+// Returns:
+//   - Version: The parsed version
+//   - bool: False if s is not a valid semantic version
+func Parse(s string) (Version, bool) {
+	m := pattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, false
+	}
+	var v Version
+	var err error
+	if v.Major, err = strconv.Atoi(m[1]); err != nil {
+		return Version{}, false
+	}
+	if v.Minor, err = strconv.Atoi(m[2]); err != nil {
+		return Version{}, false
+	}
+	if v.Patch, err = strconv.Atoi(m[3]); err != nil {
+		return Version{}, false
+	}
+	v.Prerelease, v.Build = m[4], m[5]
+	return v, true
+}
+
+// String formats the version with its "v" prefix.
+//
+// Returns:
+//   - string: The version, e.g. "v1.2.3-rc.1"
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare orders two versions by semantic version precedence. A pre-release
+// sorts before the release it precedes, and build metadata is ignored.
+//
+// Parameters:
+//   - a: The first version
+//   - b: The second version
+//
+// Returns:
+//   - int: -1 if a < b, 0 if a == b, or +1 if a > b
+func Compare(a, b Version) int {
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a.Prerelease, "."), strings.Split(b.Prerelease, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+// compareIdentifier orders two pre-release identifiers: numeric identifiers
+// compare numerically and sort before alphanumeric ones, which compare in ASCII
+// order.
+//
+// Parameters:
+//   - a: The first identifier
+//   - b: The second identifier
+//
+// Returns:
+//   - int: -1, 0 or +1
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInt(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareInt orders two integers.
+//
+// Parameters:
+//   - a: The first integer
+//   - b: The second integer
+//
+// Returns:
+//   - int: -1, 0 or +1
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
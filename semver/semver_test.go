@@ -0,0 +1,50 @@
+package semver
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	v, ok := Parse("v1.12.3-rc.1+build.5")
+	if !ok {
+		t.Fatal("expected a valid version")
+	}
+	if v.Major != 1 || v.Minor != 12 || v.Patch != 3 || v.Prerelease != "rc.1" || v.Build != "build.5" {
+		t.Errorf("unexpected version %+v", v)
+	}
+	if got := v.String(); got != "v1.12.3-rc.1+build.5" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, s := range []string{"1.2.3", "v1.2", "v01.2.3", "v1.2.3-", "v1.2.3-rc..1", "release-1"} {
+		if _, ok := Parse(s); ok {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tags := []string{
+		"v1.0.0", "v0.9.0", "v1.0.0-rc.10", "v1.0.0-alpha", "v1.0.0-rc.2",
+		"v1.0.0-alpha.1", "v1.0.0-beta", "v1.10.0", "v1.2.0", "v1.0.0-alpha.beta",
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, _ := Parse(tags[i])
+		b, _ := Parse(tags[j])
+		return Compare(a, b) < 0
+	})
+
+	want := "v0.9.0 v1.0.0-alpha v1.0.0-alpha.1 v1.0.0-alpha.beta v1.0.0-beta " +
+		"v1.0.0-rc.2 v1.0.0-rc.10 v1.0.0 v1.2.0 v1.10.0"
+	if got := strings.Join(tags, " "); got != want {
+		t.Errorf("unexpected order:\n%s\nwant:\n%s", got, want)
+	}
+
+	a, _ := Parse("v1.0.0+one")
+	b, _ := Parse("v1.0.0+two")
+	if Compare(a, b) != 0 {
+		t.Error("expected build metadata to be ignored")
+	}
+}
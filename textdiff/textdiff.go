@@ -0,0 +1,310 @@
+// Package textdiff computes line-based unified diffs between two texts.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// opKind identifies an edit operation on a single line.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line of an edit script.
+type op struct {
+	kind opKind
+	text string
+	a, b int // 0-based line numbers in the old and new text
+}
+
+// Unified returns a unified diff that turns oldText into newText, in the format
+// produced by `diff -u`. It returns an empty string if the texts are equal.
+//
+// Parameters:
+//   - oldName: The name of the old file shown in the "---" header
+//   - newName: The name of the new file shown in the "+++" header
+//   - oldText: The original text
+//   - newText: The updated text
+//
+// Returns:
+//   - string: The unified diff, or an empty string if the texts are equal
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&b, ops[h[0]:h[1]])
+	}
+	return b.String()
+}
+
+// splitLines splits a text into lines, keeping their line endings.
+//
+// Parameters:
+//   - text: The text to split
+//
+// Returns:
+//   - []string: The lines; the last one lacks a newline if the text does
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script between two line slices using
+// the linear space refinement of Myers' O(ND) algorithm, so that memory grows
+// with the length of the inputs rather than with the number of changes.
+//
+// Parameters:
+//   - a: The old lines
+//   - b: The new lines
+//
+// Returns:
+//   - []op: The edit script, covering every line of both inputs in order
+func diffLines(a, b []string) []op {
+	d := &differ{a: a, b: b, ops: make([]op, 0, max(len(a), len(b)))}
+	d.diff(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// differ accumulates the edit script of two line slices.
+type differ struct {
+	a, b []string
+	ops  []op
+}
+
+// diff appends the edit script turning a[aLo:aHi] into b[bLo:bHi]. Common
+// leading and trailing lines are matched directly; the rest is split at a
+// point of a shortest edit path found by middleSnake and diffed recursively.
+//
+// Parameters:
+//   - aLo, aHi: The range of old lines
+//   - bLo, bHi: The range of new lines
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{kind: opEqual, text: d.a[aLo], a: aLo, b: bLo})
+		aLo++
+		bLo++
+	}
+	aEnd, bEnd := aHi, bHi
+	for aEnd > aLo && bEnd > bLo && d.a[aEnd-1] == d.b[bEnd-1] {
+		aEnd--
+		bEnd--
+	}
+
+	switch {
+	case aLo == aEnd:
+		for y := bLo; y < bEnd; y++ {
+			d.ops = append(d.ops, op{kind: opInsert, text: d.b[y], a: aLo, b: y})
+		}
+	case bLo == bEnd:
+		for x := aLo; x < aEnd; x++ {
+			d.ops = append(d.ops, op{kind: opDelete, text: d.a[x], a: x, b: bLo})
+		}
+	default:
+		x, y := d.middleSnake(aLo, aEnd, bLo, bEnd)
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aEnd, y, bEnd)
+	}
+
+	for x, y := aEnd, bEnd; x < aHi; x, y = x+1, y+1 {
+		d.ops = append(d.ops, op{kind: opEqual, text: d.a[x], a: x, b: y})
+	}
+}
+
+// middleSnake runs the forward and backward searches of Myers' algorithm
+// simultaneously until they overlap, and returns the point where the forward
+// path reaches the overlap. Both ranges must be non-empty and differ in their
+// first and last lines, so that the point splits them into two smaller problems.
+//
+// Parameters:
+//   - aLo, aHi: The range of old lines
+//   - bLo, bHi: The range of new lines
+//
+// Returns:
+//   - int: The index in a of the split point
+//   - int: The index in b of the split point
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	// vf holds the furthest x reached on each diagonal by the forward search,
+	// vb the furthest distance from the end reached by the backward search.
+	vf, vb := make([]int, size), make([]int, size)
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	delta := n - m
+	// The forward search checks for an overlap when delta is odd, the backward
+	// search when it is even.
+	front := delta%2 != 0
+	// Diagonals that ran off the bottom or right edge are skipped.
+	kfStart, kfEnd, kbStart, kbEnd := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k := -step + kfStart; k <= step-kfEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && vf[i-1] < vf[i+1]) {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			switch {
+			case x > n:
+				kfEnd += 2
+			case y > m:
+				kfStart += 2
+			case front:
+				j := offset + delta - k
+				if j >= 0 && j < size && vb[j] != -1 && x >= n-vb[j] {
+					return aLo + x, bLo + y
+				}
+			}
+		}
+
+		for k := -step + kbStart; k <= step-kbEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && vb[i-1] < vb[i+1]) {
+				x = vb[i+1]
+			} else {
+				x = vb[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[i] = x
+			switch {
+			case x > n:
+				kbEnd += 2
+			case y > m:
+				kbStart += 2
+			case !front:
+				j := offset + delta - k
+				if j >= 0 && j < size && vf[j] != -1 {
+					fx := vf[j]
+					fy := fx - (j - offset)
+					if fx >= n-x {
+						return aLo + fx, bLo + fy
+					}
+				}
+			}
+		}
+	}
+
+	// The ranges have no line in common: delete all of a, then insert all of b.
+	return aHi, bLo
+}
+
+// hunks groups the changes of an edit script with their surrounding context.
+//
+// Parameters:
+//   - ops: The edit script
+//
+// Returns:
+//   - [][2]int: The start and end index in ops of each hunk
+func hunks(ops []op) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := i
+		// Extend the hunk while the next change is within twice the context.
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != opEqual {
+				end = j + 1
+			} else if j-end >= 2*contextLines {
+				break
+			}
+		}
+		end = min(end+contextLines, len(ops))
+
+		if len(out) > 0 && out[len(out)-1][1] >= start {
+			out[len(out)-1][1] = end
+		} else {
+			out = append(out, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return out
+}
+
+// writeHunk writes a single hunk with its "@@" header.
+//
+// Parameters:
+//   - b: The builder to write to
+//   - ops: The operations of the hunk
+func writeHunk(b *strings.Builder, ops []op) {
+	oldStart, newStart := ops[0].a, ops[0].b
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, o := range ops {
+		prefix := " "
+		switch o.kind {
+		case opDelete:
+			prefix = "-"
+		case opInsert:
+			prefix = "+"
+		}
+		b.WriteString(prefix + o.text)
+		if !strings.HasSuffix(o.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the line range of one side of a hunk header.
+//
+// Parameters:
+//   - start: The 0-based index of the first line
+//   - count: The number of lines
+//
+// Returns:
+//   - string: The range, e.g. "4,7", "4" for a single line or "3,0" when empty
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
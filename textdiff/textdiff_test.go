@@ -0,0 +1,97 @@
+package textdiff
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnified_Equal(t *testing.T) {
+	if diff := Unified("a", "b", "x\ny\n", "x\ny\n"); diff != "" {
+		t.Errorf("expected no diff, got:\n%s", diff)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	newText := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\n16\n"
+
+	want := `--- docs.md
++++ docs.md (generated)
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -11,5 +11,5 @@
+ 11
+ 12
+ 13
+-14
+ 15
++16
+`
+	if diff := Unified("docs.md", "docs.md (generated)", oldText, newText); diff != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", diff, want)
+	}
+}
+
+func TestUnified_MergesNearbyChanges(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\n"
+	newText := "A\nb\nc\nd\ne\nf\nG\n"
+
+	diff := Unified("old", "new", oldText, newText)
+	if strings.Count(diff, "@@ ") != 1 {
+		t.Errorf("expected a single hunk, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -1,7 +1,7 @@") {
+		t.Errorf("unexpected hunk header:\n%s", diff)
+	}
+}
+
+func TestUnified_EdgeCases(t *testing.T) {
+	diff := Unified("old", "new", "", "a\n")
+	if !strings.Contains(diff, "@@ -0,0 +1 @@\n+a\n") {
+		t.Errorf("unexpected diff for a new file:\n%s", diff)
+	}
+
+	diff = Unified("old", "new", "a\nb", "a\nb\n")
+	if !strings.Contains(diff, "-b\n\\ No newline at end of file\n+b\n") {
+		t.Errorf("expected a missing newline marker:\n%s", diff)
+	}
+}
+
+func TestUnified_LargeInput(t *testing.T) {
+	const n = 10000
+	var oldText, newText strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&oldText, "line %d\n", i)
+		if i%3 == 0 {
+			fmt.Fprintf(&newText, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&newText, "line %d\n", i)
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	diff := Unified("old", "new", oldText.String(), newText.String())
+	runtime.ReadMemStats(&after)
+
+	if got := strings.Count(diff, "\n+changed "); got != (n+2)/3 {
+		t.Errorf("expected %d inserted lines, got %d", (n+2)/3, got)
+	}
+	// The diff itself is a few MB; the edit search must not grow with the
+	// number of changes times the input length.
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+		t.Errorf("diffing %d lines allocated %d MB", n, alloc>>20)
+	}
+
+	if diff := Unified("old", "new", "", oldText.String()); strings.Count(diff, "\n+line ") != n {
+		t.Error("expected every line to be inserted into an empty file")
+	}
+}
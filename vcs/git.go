@@ -0,0 +1,186 @@
+// Package vcs runs the git commands godocmd needs to inspect other revisions
+// of a repository.
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thinktide/godocmd/semver"
+)
+
+// git runs a git command in dir and returns its trimmed standard output.
+//
+// Parameters:
+//   - dir: The working directory of the command
+//   - args: The git arguments
+//
+// Returns:
+//   - string: The standard output without surrounding whitespace
+//   - error: An error including git's standard error if the command fails
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Toplevel returns the root directory of the git repository containing dir.
+//
+// Parameters:
+//   - dir: A directory inside the repository
+//
+// Returns:
+//   - string: The absolute path of the repository root
+//   - error: An error if dir is not inside a git repository
+func Toplevel(dir string) (string, error) {
+	return git(dir, "rev-parse", "--show-toplevel")
+}
+
+// Worktree checks out a revision of the repository containing dir into a
+// temporary directory and returns the directory corresponding to dir within it.
+// The returned cleanup function removes the worktree.
+//
+// Parameters:
+//   - dir: A directory inside the repository
+//   - ref: The revision to check out, e.g. a tag, branch or commit
+//
+// Returns:
+//   - string: The path of dir within the checked-out revision
+//   - func(): Removes the worktree; safe to call once
+//   - error: An error if the revision cannot be checked out
+func Worktree(dir, ref string) (string, func(), error) {
+	top, err := Toplevel(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	rel, err := relToTop(top, dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "godocmd-worktree-")
+	if err != nil {
+		return "", nil, err
+	}
+	path := filepath.Join(tmp, "src")
+	if _, err := git(top, "worktree", "add", "--detach", "--quiet", path, ref); err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, err
+	}
+
+	cleanup := func() {
+		git(top, "worktree", "remove", "--force", path)
+		os.RemoveAll(tmp)
+	}
+	return filepath.Join(path, rel), cleanup, nil
+}
+
+// WorktreePath maps path, a location in the repository containing dir, onto
+// the worktree that Worktree checked out for dir. Paths outside the repository
+// are returned unchanged.
+//
+// Parameters:
+//   - dir: The directory Worktree was called with
+//   - worktreeDir: The directory Worktree returned for dir
+//   - path: The path to map
+//
+// Returns:
+//   - string: The corresponding path within the worktree
+//   - error: An error if dir is not inside a git repository
+func WorktreePath(dir, worktreeDir, path string) (string, error) {
+	top, err := Toplevel(dir)
+	if err != nil {
+		return "", err
+	}
+	relDir, err := relToTop(top, dir)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := relToTop(top, path)
+	if err != nil {
+		return "", err
+	}
+	if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return path, nil
+	}
+	// worktreeDir is the worktree root joined with relDir, so step back up.
+	up, err := filepath.Rel(relDir, ".")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(worktreeDir, up, relPath), nil
+}
+
+// relToTop returns the path of p relative to the repository root top,
+// resolving symlinks so both are expressed the way git reports them.
+//
+// Parameters:
+//   - top: The absolute repository root
+//   - p: The path to express relative to top
+//
+// Returns:
+//   - string: The relative path
+//   - error: An error if p cannot be made absolute
+func relToTop(top, p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	return filepath.Rel(top, abs)
+}
+
+// Tags returns the semantic version tags of the repository containing dir,
+// such as "v1.2.0", from oldest to newest. Other tags are ignored.
+//
+// Parameters:
+//   - dir: A directory inside the repository
+//
+// Returns:
+//   - []string: The tags in semantic version order
+//   - error: An error if dir is not inside a git repository
+func Tags(dir string) ([]string, error) {
+	out, err := git(dir, "tag", "--list", "v*")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	versions := map[string]semver.Version{}
+	for _, tag := range strings.Fields(out) {
+		if v, ok := semver.Parse(tag); ok {
+			tags = append(tags, tag)
+			versions[tag] = v
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return semver.Compare(versions[tags[i]], versions[tags[j]]) < 0
+	})
+	return tags, nil
+}
+
+// Date returns the commit date of a revision.
+//
+// Parameters:
+//   - dir: A directory inside the repository
+//   - ref: The revision, e.g. a tag
+//
+// Returns:
+//   - string: The date as YYYY-MM-DD
+//   - error: An error if the revision does not exist
+func Date(dir, ref string) (string, error) {
+	return git(dir, "log", "-1", "--format=%cd", "--date=short", ref)
+}
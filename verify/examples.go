@@ -0,0 +1,364 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/thinktide/godocmd/modules"
+)
+
+// testEvent mirrors the subset of the `go test -json` event stream used to
+// determine the outcome of each example.
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// Workspace is an isolated temporary copy of a Go module in which example tests
+// are run, so that verifying examples does not write to the module's directory.
+// The content-addressed build cache is shared with the user's builds; test
+// results are never cached.
+type Workspace struct {
+	// modDir is the directory of the original module.
+	modDir string
+
+	// tmp is the temporary directory holding the copy and its go.work file.
+	tmp string
+
+	// gowork is the value of GOWORK used when running go test.
+	gowork string
+}
+
+// NewWorkspace copies the module containing dir into a new temporary directory.
+// Relative replace directives of the copy are rewritten to the original
+// directories, and the other modules of an enclosing go.work file are used
+// in place. The workspace must be released with Close.
+//
+// Parameters:
+//   - dir: A directory inside the module to copy
+//
+// Returns:
+//   - *Workspace: The isolated workspace
+//   - error: Any error encountered locating or copying the module
+func NewWorkspace(dir string) (*Workspace, error) {
+	mod, err := modules.Find(dir)
+	if err != nil {
+		return nil, err
+	}
+	modDir, err := filepath.Abs(mod.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "godocmd-examples-*")
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{modDir: modDir, tmp: tmp, gowork: "off"}
+	if err := w.setup(); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// setup copies the module into the workspace and prepares its go.mod and go.work files.
+//
+// Returns:
+//   - error: Any error encountered copying or editing the files
+func (w *Workspace) setup() error {
+	if err := copyModule(w.modDir, w.root()); err != nil {
+		return fmt.Errorf("copying %s: %w", w.modDir, err)
+	}
+	if err := w.rewriteReplaces(); err != nil {
+		return err
+	}
+
+	ws, err := modules.FindWorkspace(w.modDir)
+	if err != nil || ws == nil {
+		return err
+	}
+	var work strings.Builder
+	if ws.GoVersion != "" {
+		fmt.Fprintf(&work, "go %s\n\n", ws.GoVersion)
+	}
+	work.WriteString("use (\n\t./module\n")
+	for _, mod := range ws.Modules {
+		modDir, err := filepath.Abs(mod.Dir)
+		if err != nil {
+			return err
+		}
+		if modDir != w.modDir {
+			fmt.Fprintf(&work, "\t%s\n", filepath.ToSlash(modDir))
+		}
+	}
+	work.WriteString(")\n")
+	w.gowork = filepath.Join(w.tmp, "go.work")
+	return os.WriteFile(w.gowork, []byte(work.String()), 0644)
+}
+
+// root returns the directory of the module copy.
+//
+// Returns:
+//   - string: The module directory inside the workspace
+func (w *Workspace) root() string {
+	return filepath.Join(w.tmp, "module")
+}
+
+// rewriteReplaces points the relative replace directives of the copied go.mod
+// file at the directories they name relative to the original module.
+//
+// Returns:
+//   - error: Any error encountered reading or editing the go.mod file
+func (w *Workspace) rewriteReplaces() error {
+	out, err := w.goCmd(w.root(), "mod", "edit", "-json").Output()
+	if err != nil {
+		return fmt.Errorf("reading go.mod: %w", err)
+	}
+	var gomod struct {
+		Replace []struct {
+			Old struct{ Path, Version string }
+			New struct{ Path, Version string }
+		}
+	}
+	if err := json.Unmarshal(out, &gomod); err != nil {
+		return fmt.Errorf("reading go.mod: %w", err)
+	}
+
+	args := []string{"mod", "edit"}
+	for _, r := range gomod.Replace {
+		if r.New.Version != "" || !(strings.HasPrefix(r.New.Path, "./") || strings.HasPrefix(r.New.Path, "../")) {
+			continue
+		}
+		old := r.Old.Path
+		if r.Old.Version != "" {
+			old += "@" + r.Old.Version
+		}
+		target := filepath.Join(w.modDir, filepath.FromSlash(r.New.Path))
+		args = append(args, "-replace", old+"="+filepath.ToSlash(target))
+	}
+	if len(args) == 2 {
+		return nil
+	}
+	if out, err := w.goCmd(w.root(), args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("rewriting go.mod replacements: %v\n%s", err, out)
+	}
+	return nil
+}
+
+// goCmd prepares a go command running in dir with the workspace's go.work
+// settings.
+//
+// Parameters:
+//   - dir: The working directory of the command
+//   - args: The arguments to the go command
+//
+// Returns:
+//   - *exec.Cmd: The prepared command
+func (w *Workspace) goCmd(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK="+w.gowork)
+	return cmd
+}
+
+// Close removes the workspace.
+//
+// Returns:
+//   - error: Any error encountered removing the temporary directory
+func (w *Workspace) Close() error {
+	return os.RemoveAll(w.tmp)
+}
+
+// Examples compiles and runs the example tests of the package in dir in a
+// temporary copy of its module, as Workspace.Examples does.
+//
+// Parameters:
+//   - dir: The package directory whose examples should be run
+//
+// Returns:
+//   - map[string]string: The go test output of each failing example, keyed by
+//     the name of the example function
+//   - error: Any error encountered copying the module or running go test
+func Examples(dir string) (map[string]string, error) {
+	w, err := NewWorkspace(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	return w.Examples(dir)
+}
+
+// Examples compiles and runs the example tests of the package in dir using
+// `go test -run ^Example` inside the workspace and reports the examples whose
+// output did not match their `// Output:` comment.
+//
+// Parameters:
+//   - dir: The package directory in the original module whose examples should be run
+//
+// Returns:
+//   - map[string]string: The go test output of each failing example, keyed by
+//     the name of the example function (e.g. "ExampleUser_Name")
+//   - error: Any error encountered running go test, including build failures
+func (w *Workspace) Examples(dir string) (map[string]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(w.modDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is outside of module %s", dir, w.modDir)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := w.goCmd(filepath.Join(w.root(), rel), "test", "-run", "^Example", "-count=1", "-json", ".")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	failures := map[string]string{}
+	output := map[string]*strings.Builder{}
+	var pkgOutput strings.Builder
+
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.Test == "" {
+			if ev.Action == "output" || ev.Action == "build-output" {
+				pkgOutput.WriteString(ev.Output)
+			}
+			continue
+		}
+		if !strings.HasPrefix(ev.Test, "Example") {
+			continue
+		}
+		switch ev.Action {
+		case "output":
+			if output[ev.Test] == nil {
+				output[ev.Test] = &strings.Builder{}
+			}
+			output[ev.Test].WriteString(ev.Output)
+		case "fail":
+			failures[ev.Test] = exampleFailure(output[ev.Test])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading go test output: %w", err)
+	}
+
+	if runErr != nil && len(failures) == 0 {
+		msg := strings.TrimSpace(stderr.String() + pkgOutput.String())
+		return nil, fmt.Errorf("go test failed: %v\n%s", runErr, msg)
+	}
+
+	return failures, nil
+}
+
+// copyModule copies the files of the module in src to dst, skipping version
+// control metadata and nested modules, which are not part of the module.
+//
+// Parameters:
+//   - src: The module directory
+//   - dst: The directory to create the copy in
+//
+// Returns:
+//   - error: Any error encountered reading or writing the files
+func copyModule(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			if rel != "." {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(target, 0755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		}
+		return nil
+	})
+}
+
+// copyFile copies a regular file, preserving its permissions.
+//
+// Parameters:
+//   - src: The file to copy
+//   - dst: The path of the copy
+//
+// Returns:
+//   - error: Any error encountered reading or writing the file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// exampleFailure strips the "=== RUN" and "--- FAIL" lines from the output of a
+// failing example, leaving the got/want report written by the testing package.
+//
+// Parameters:
+//   - out: The collected output of the example, possibly nil
+//
+// Returns:
+//   - string: The trimmed failure report
+func exampleFailure(out *strings.Builder) string {
+	if out == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- FAIL") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,131 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles is a test helper that creates files below root.
+//
+// Parameters:
+//   - t: The test to fail on errors
+//   - root: The directory to create the files in
+//   - files: The file contents keyed by slash-separated relative path
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkspace_Examples(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":   "module example.com/greet\n\ngo 1.21\n",
+		"greet.go": "package greet\n\n// Hello greets name.\nfunc Hello(name string) string { return \"Hello, \" + name }\n",
+		"example_test.go": `package greet_test
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+func ExampleHello() {
+	fmt.Println(greet.Hello("Ada"))
+	// Output: Hello, Ada
+}
+
+func ExampleHello_wrong() {
+	fmt.Println(greet.Hello("Bob"))
+	// Output: Hi, Bob
+}
+`,
+	})
+
+	w, err := NewWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	failures, err := w.Examples(root)
+	if err != nil {
+		t.Fatalf("Examples: %v", err)
+	}
+	if _, ok := failures["ExampleHello"]; ok {
+		t.Errorf("expected ExampleHello to pass, got %q", failures["ExampleHello"])
+	}
+	if got := failures["ExampleHello_wrong"]; !strings.Contains(got, "Hello, Bob") || !strings.Contains(got, "Hi, Bob") {
+		t.Errorf("expected a got/want report for ExampleHello_wrong, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "go.sum")); err == nil {
+		t.Error("expected the module directory to be left untouched")
+	}
+}
+
+func TestWorkspace_RewriteReplaces(t *testing.T) {
+	root := t.TempDir()
+	modDir := filepath.Join(root, "app")
+	writeFiles(t, root, map[string]string{
+		"app/go.mod": "module example.com/app\n\ngo 1.21\n\n" +
+			"require (\n\texample.com/lib v1.0.0\n\texample.com/remote v1.0.0\n)\n\n" +
+			"replace example.com/lib => ../lib\n\nreplace example.com/remote => example.com/fork v1.1.0\n",
+		"app/app.go": "package app\n",
+		"lib/go.mod": "module example.com/lib\n\ngo 1.21\n",
+	})
+
+	w, err := NewWorkspace(modDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	data, err := os.ReadFile(filepath.Join(w.root(), "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gomod := string(data)
+	if want := "example.com/lib => " + filepath.ToSlash(filepath.Join(root, "lib")); !strings.Contains(gomod, want) {
+		t.Errorf("expected %q in the copied go.mod, got:\n%s", want, gomod)
+	}
+	if !strings.Contains(gomod, "example.com/remote => example.com/fork v1.1.0") {
+		t.Errorf("expected the module replacement to be kept, got:\n%s", gomod)
+	}
+}
+
+func TestCopyModule(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"go.mod":            "module example.com/app\n",
+		"app.go":            "package app\n",
+		"sub/sub.go":        "package sub\n",
+		"nested/go.mod":     "module example.com/app/nested\n",
+		"nested/nested.go":  "package nested\n",
+		".git/HEAD":         "ref: refs/heads/main\n",
+		"testdata/data.txt": "data\n",
+	})
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := copyModule(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"go.mod", "app.go", "sub/sub.go", "testdata/data.txt"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("expected %s to be copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{"nested", ".git"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be skipped, got %v", name, err)
+		}
+	}
+}